@@ -56,13 +56,10 @@ func main() {
 
 	fmt.Printf("\norder: %+v\n", o)
 
-	orderID1, err := client.CancelOrderByID(o.ID, godax.QueryParams{godax.ProductIDParam: o.ProductID})
-	if err != nil {
+	if err := client.CancelOrderByID(o.ID, godax.QueryParams{godax.ProductIDParam: o.ProductID}); err != nil {
 		fmt.Println("err canceling:", err)
 	}
 
-	fmt.Printf("\norderID1: %s\n", orderID1)
-
 	// o, err = client.PlaceOrder(godax.OrderParams{
 	// 	CommonOrderParams: godax.CommonOrderParams{
 	// 		Side:      "buy",
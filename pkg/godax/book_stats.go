@@ -0,0 +1,246 @@
+package godax
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrEmptyBook is returned for book stats that need a bid and an ask when a side of the book is empty.
+var ErrEmptyBook = errors.New("the order book needs at least one bid and one ask")
+
+// The book stats below work on any OrderBook, one from GetProductOrderBook or one you keep up to date
+// yourself. They expect the bids sorted best (highest) first and the asks best (lowest) first, as
+// coinbase sends them, and skip empty levels.
+
+// Spread is the best ask minus the best bid.
+func (b OrderBook) Spread() (string, error) {
+	bid, ask, err := b.inside()
+	if err != nil {
+		return "", err
+	}
+	return formatDecimal(sub(ask.price, bid.price)), nil
+}
+
+// SpreadBps is the spread in basis points of the mid price.
+func (b OrderBook) SpreadBps() (string, error) {
+	bid, ask, err := b.inside()
+	if err != nil {
+		return "", err
+	}
+	mid := midPrice(bid, ask)
+	return formatDecimal(quo(mul(sub(ask.price, bid.price), newRat().SetInt64(10000)), mid)), nil
+}
+
+// Mid is halfway between the best bid and the best ask.
+func (b OrderBook) Mid() (string, error) {
+	bid, ask, err := b.inside()
+	if err != nil {
+		return "", err
+	}
+	return formatDecimal(midPrice(bid, ask)), nil
+}
+
+// Microprice is the mid weighted by the sizes at the top of the book,
+// (bid * askSize + ask * bidSize) / (bidSize + askSize). It leans towards the side with less size, the
+// side more likely to trade through next.
+func (b OrderBook) Microprice() (string, error) {
+	bid, ask, err := b.inside()
+	if err != nil {
+		return "", err
+	}
+	weighted := add(mul(bid.price, ask.size), mul(ask.price, bid.size))
+	return formatDecimal(quo(weighted, add(bid.size, ask.size))), nil
+}
+
+// Imbalance is (bidSize - askSize) / (bidSize + askSize) over the top n levels of each side, from -1
+// (all asks) to 1 (all bids). n of 0 or less uses every level. An empty book is balanced.
+func (b OrderBook) Imbalance(n int) (string, error) {
+	bids, err := parseLevels(b.Bids)
+	if err != nil {
+		return "", err
+	}
+	asks, err := parseLevels(b.Asks)
+	if err != nil {
+		return "", err
+	}
+	bidSize, askSize := newRat(), newRat()
+	for i, l := range bids {
+		if n > 0 && i == n {
+			break
+		}
+		bidSize = add(bidSize, l.size)
+	}
+	for i, l := range asks {
+		if n > 0 && i == n {
+			break
+		}
+		askSize = add(askSize, l.size)
+	}
+	total := add(bidSize, askSize)
+	if total.Sign() == 0 {
+		return "0", nil
+	}
+	return formatDecimal(quo(sub(bidSize, askSize), total)), nil
+}
+
+// BookDepth is how much is on each side of the book within a distance of the mid price.
+type BookDepth struct {
+	Mid string
+
+	// BidSize and AskSize are in base currency, BidNotional and AskNotional in quote currency.
+	BidSize     string
+	BidNotional string
+	BidLevels   int
+	AskSize     string
+	AskNotional string
+	AskLevels   int
+}
+
+// DepthWithin is the cumulative depth on each side of the book priced within bps basis points of the
+// mid price.
+func (b OrderBook) DepthWithin(bps string) (BookDepth, error) {
+	band, err := parseDecimal(bps)
+	if err != nil {
+		return BookDepth{}, err
+	}
+	bids, err := parseLevels(b.Bids)
+	if err != nil {
+		return BookDepth{}, err
+	}
+	asks, err := parseLevels(b.Asks)
+	if err != nil {
+		return BookDepth{}, err
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return BookDepth{}, ErrEmptyBook
+	}
+	mid := midPrice(bids[0], asks[0])
+	distance := quo(mul(mid, band), newRat().SetInt64(10000))
+	low, high := sub(mid, distance), add(mid, distance)
+
+	d := BookDepth{Mid: formatDecimal(mid)}
+	bidSize, bidNotional := newRat(), newRat()
+	for _, l := range bids {
+		if l.price.Cmp(low) < 0 {
+			break
+		}
+		bidSize = add(bidSize, l.size)
+		bidNotional = add(bidNotional, mul(l.size, l.price))
+		d.BidLevels++
+	}
+	askSize, askNotional := newRat(), newRat()
+	for _, l := range asks {
+		if l.price.Cmp(high) > 0 {
+			break
+		}
+		askSize = add(askSize, l.size)
+		askNotional = add(askNotional, mul(l.size, l.price))
+		d.AskLevels++
+	}
+	d.BidSize, d.BidNotional = formatDecimal(bidSize), formatDecimal(bidNotional)
+	d.AskSize, d.AskNotional = formatDecimal(askSize), formatDecimal(askNotional)
+	return d, nil
+}
+
+// BookBucket is one bar of a book histogram, the levels of a side priced from Low up to (but not
+// including) High.
+type BookBucket struct {
+	Low       string
+	High      string
+	Size      string
+	NumOrders int
+	Levels    int
+}
+
+// BookHistogram is the size of each side of the book in price buckets, best bucket first.
+type BookHistogram struct {
+	Bids []BookBucket
+	Asks []BookBucket
+}
+
+// Histogram groups each side of the book into buckets of width in price, with every bucket starting
+// at a multiple of width. Buckets without any size are left out. A width of 0 gives one bucket per
+// price level, with Low and High both its price.
+func (b OrderBook) Histogram(width string) (BookHistogram, error) {
+	w, err := parseDecimal(width)
+	if err != nil {
+		return BookHistogram{}, err
+	}
+	bids, err := parseLevels(b.Bids)
+	if err != nil {
+		return BookHistogram{}, err
+	}
+	asks, err := parseLevels(b.Asks)
+	if err != nil {
+		return BookHistogram{}, err
+	}
+	return BookHistogram{Bids: histogram(bids, w), Asks: histogram(asks, w)}, nil
+}
+
+func histogram(levels []bookLevel, width *big.Rat) []BookBucket {
+	var buckets []BookBucket
+	var sizes []*big.Rat
+	var low *big.Rat
+	for _, l := range levels {
+		if start := floorTo(l.price, width); low == nil || start.Cmp(low) != 0 {
+			low = start
+			buckets = append(buckets, BookBucket{Low: formatDecimal(low), High: formatDecimal(add(low, width))})
+			sizes = append(sizes, newRat())
+		}
+		i := len(buckets) - 1
+		sizes[i] = add(sizes[i], l.size)
+		buckets[i].NumOrders += l.numOrders
+		buckets[i].Levels++
+	}
+	for i := range buckets {
+		buckets[i].Size = formatDecimal(sizes[i])
+	}
+	return buckets
+}
+
+// bookLevel is a parsed OrderBookOrder.
+type bookLevel struct {
+	price     *big.Rat
+	size      *big.Rat
+	numOrders int
+}
+
+// parseLevels parses one side of a book, leaving out levels without any size.
+func parseLevels(orders []OrderBookOrder) ([]bookLevel, error) {
+	levels := make([]bookLevel, 0, len(orders))
+	for _, o := range orders {
+		price, err := parseDecimal(o.Price)
+		if err != nil {
+			return nil, err
+		}
+		size, err := parseDecimal(o.Size)
+		if err != nil {
+			return nil, err
+		}
+		if size.Sign() <= 0 {
+			continue
+		}
+		levels = append(levels, bookLevel{price: price, size: size, numOrders: o.NumOrders})
+	}
+	return levels, nil
+}
+
+// inside parses the best bid and ask.
+func (b OrderBook) inside() (bid, ask bookLevel, err error) {
+	bids, err := parseLevels(b.Bids)
+	if err != nil {
+		return bookLevel{}, bookLevel{}, err
+	}
+	asks, err := parseLevels(b.Asks)
+	if err != nil {
+		return bookLevel{}, bookLevel{}, err
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return bookLevel{}, bookLevel{}, ErrEmptyBook
+	}
+	return bids[0], asks[0], nil
+}
+
+func midPrice(bid, ask bookLevel) *big.Rat {
+	return quo(add(bid.price, ask.price), newRat().SetInt64(2))
+}
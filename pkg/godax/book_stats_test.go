@@ -0,0 +1,106 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func statsBook() OrderBook {
+	return OrderBook{
+		Bids: []OrderBookOrder{{"99", "3", 2}, {"98.5", "1", 1}, {"98", "0", 0}, {"97", "4", 3}},
+		Asks: []OrderBookOrder{{"101", "1", 1}, {"102", "2", 4}, {"110", "5", 1}},
+	}
+}
+
+func TestOrderBook_Inside(t *testing.T) {
+	b := statsBook()
+	tests := []struct {
+		name string
+		stat func() (string, error)
+		want string
+	}{
+		{"spread", b.Spread, "2"},
+		{"spread bps", b.SpreadBps, "200"},
+		{"mid", b.Mid, "100"},
+		// 99 * 1 + 101 * 3 over 4
+		{"microprice", b.Microprice, "100.5"},
+	}
+	for _, tt := range tests {
+		got, err := tt.stat()
+		if err != nil {
+			t.Fatalf("%s error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	empty := OrderBook{Bids: b.Bids, Asks: []OrderBookOrder{{"101", "0", 0}}}
+	if _, err := empty.Mid(); err != ErrEmptyBook {
+		t.Errorf("Mid() error = %v, want ErrEmptyBook", err)
+	}
+}
+
+func TestOrderBook_Imbalance(t *testing.T) {
+	b := statsBook()
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "0.5"},
+		{2, "0.1428571428571429"},
+		{0, "0"},
+	}
+	for _, tt := range tests {
+		if got, err := b.Imbalance(tt.n); err != nil || got != tt.want {
+			t.Errorf("Imbalance(%d) = %s, %v, want %s", tt.n, got, err, tt.want)
+		}
+	}
+	if got, _ := (OrderBook{}).Imbalance(5); got != "0" {
+		t.Errorf("Imbalance() of an empty book = %s, want 0", got)
+	}
+}
+
+func TestOrderBook_DepthWithin(t *testing.T) {
+	got, err := statsBook().DepthWithin("200")
+	if err != nil {
+		t.Fatalf("DepthWithin() error = %v", err)
+	}
+	want := BookDepth{
+		Mid:         "100",
+		BidSize:     "4",
+		BidNotional: "395.5",
+		BidLevels:   2,
+		AskSize:     "3",
+		AskNotional: "305",
+		AskLevels:   2,
+	}
+	if got != want {
+		t.Errorf("DepthWithin() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOrderBook_Histogram(t *testing.T) {
+	got, err := statsBook().Histogram("5")
+	if err != nil {
+		t.Fatalf("Histogram() error = %v", err)
+	}
+	want := BookHistogram{
+		Bids: []BookBucket{{Low: "95", High: "100", Size: "8", NumOrders: 6, Levels: 3}},
+		Asks: []BookBucket{
+			{Low: "100", High: "105", Size: "3", NumOrders: 5, Levels: 2},
+			{Low: "110", High: "115", Size: "5", NumOrders: 1, Levels: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Histogram() = %+v, want %+v", got, want)
+	}
+
+	got, err = statsBook().Histogram("")
+	if err != nil {
+		t.Fatalf("Histogram() error = %v", err)
+	}
+	if len(got.Bids) != 3 || got.Bids[1] != (BookBucket{Low: "98.5", High: "98.5", Size: "1", NumOrders: 1, Levels: 1}) {
+		t.Errorf("Histogram() per level bids = %+v", got.Bids)
+	}
+}
@@ -0,0 +1,291 @@
+package godax
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrInvalidInterval is returned when a CandleBuilder is given an interval that isn't positive.
+var ErrInvalidInterval = errors.New("please provide a positive candle interval")
+
+// CandleUpdate is emitted by a CandleBuilder each time a candle changes. Closed is false while
+// the candle's interval is still in progress and true once, when the interval has ended.
+type CandleUpdate struct {
+	ProductID string
+	Candle    Candle
+	Closed    bool
+}
+
+// CandleBuilder builds OHLCV candles of any interval (10s or 2h for example) from match messages
+// on the websocket feed. Every match updates the live candle of its product. A candle is closed
+// when a match for a later interval arrives or when CloseUntil passes its end, and intervals that
+// saw no trades are closed as flat Missing candles at the previous close so the series stays
+// contiguous. Seed joins the live candles onto a REST backfill used as warm-up history.
+type CandleBuilder struct {
+	mu       sync.Mutex
+	emitMu   sync.Mutex // held across emit so updates reach the handler in the order they were made
+	interval int64
+	handler  func(CandleUpdate)
+	products map[string]*candleState
+}
+
+type candleState struct {
+	current     *Candle
+	bucket      int64
+	next        int64
+	lastClose   float64
+	hasClose    bool
+	lastTradeID int
+	cutoff      int64
+}
+
+// NewCandleBuilder creates a CandleBuilder for the given interval. handler is called with every
+// live and closed candle, in order, even when HandleMatch and CloseUntil run on different
+// goroutines. It may call Current, but not HandleMatch or CloseUntil, which wait for it to return.
+func NewCandleBuilder(interval time.Duration, handler func(CandleUpdate)) (*CandleBuilder, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return &CandleBuilder{
+		interval: int64(interval),
+		handler:  handler,
+		products: make(map[string]*candleState),
+	}, nil
+}
+
+// Seed loads warm-up history for a product, typically from BackfillHistoricRates, and returns it
+// resampled to the builder's interval. The interval must be a multiple of the history's granularity.
+// If the last backfilled candle is still in progress it becomes the live candle rather than being
+// returned. Matches that fall inside the backfilled range are skipped since they are already
+// counted in the history. The smallest REST granularity is 60s, so sub-minute intervals can't be
+// seeded from candles, use SeedTrades for those.
+func (b *CandleBuilder) Seed(productID string, history CandleSeries) ([]Candle, error) {
+	g := int64(history.Granularity.Duration())
+	if g <= 0 || b.interval%g != 0 {
+		return nil, ErrInvalidResample
+	}
+	resampled, err := history.Resample(Granularity(b.interval / int64(time.Second)))
+	if err != nil {
+		return nil, err
+	}
+	if len(resampled.Candles) == 0 {
+		return nil, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := b.state(productID)
+	last := history.Candles[len(history.Candles)-1]
+	st.cutoff = secondsToNanos(last.Time) + g
+
+	closed := resampled.Candles
+	tail := closed[len(closed)-1]
+	tailBucket := secondsToNanos(tail.Time)
+	if tailBucket+b.interval > st.cutoff {
+		closed = closed[:len(closed)-1]
+		st.bucket = tailBucket
+		if !tail.Missing {
+			c := tail
+			st.current = &c
+		}
+	} else {
+		st.next = tailBucket + b.interval
+	}
+	for i := len(resampled.Candles) - 1; i >= 0; i-- {
+		if c := resampled.Candles[i]; !c.Missing {
+			st.lastClose, st.hasClose = c.Close, true
+			break
+		}
+	}
+	if st.current == nil && st.next == 0 {
+		st.next = tailBucket
+	}
+
+	out := make([]Candle, len(closed))
+	copy(out, closed)
+	return out, nil
+}
+
+// HandleMatch applies a match (or last_match) message from the feed. Other message types are
+// ignored, as are duplicate matches and matches for intervals that have already closed.
+func (b *CandleBuilder) HandleMatch(msg FeedMessage) error {
+	if msg.Type != FeedMatch && msg.Type != FeedLastMatch {
+		return nil
+	}
+	t, err := msg.ParsedTime()
+	if err != nil {
+		return err
+	}
+	price, err := strconv.ParseFloat(msg.Price, 64)
+	if err != nil {
+		return err
+	}
+	size, err := strconv.ParseFloat(msg.Size, 64)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	updates := b.applyMatch(msg.ProductID, msg.TradeID, t.UnixNano(), price, size)
+	b.unlockAndEmit(updates)
+	return nil
+}
+
+// SeedTrades loads warm-up history for a product from trades, typically from ListTradesByProduct,
+// and returns the candles they close. The last candle stays live rather than being returned. This
+// is how sub-minute builders are warmed up, as their interval is shorter than any candle coinbase
+// serves. ListTradesByProduct only returns the latest trades, so the warm-up is as long as they
+// cover. Matches from the feed join on by trade ID, the ones already seeded are skipped.
+func (b *CandleBuilder) SeedTrades(productID string, trades []Trade) ([]Candle, error) {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TradeID < sorted[j].TradeID })
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var closed []Candle
+	for _, tr := range sorted {
+		t, err := time.Parse(time.RFC3339Nano, tr.Time)
+		if err != nil {
+			return nil, err
+		}
+		price, err := strconv.ParseFloat(tr.Price, 64)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseFloat(tr.Size, 64)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range b.applyMatch(productID, tr.TradeID, t.UnixNano(), price, size) {
+			if u.Closed {
+				closed = append(closed, u.Candle)
+			}
+		}
+	}
+	return closed, nil
+}
+
+// applyMatch adds a trade to the product's candles and returns the resulting updates. The lock must
+// be held.
+func (b *CandleBuilder) applyMatch(productID string, tradeID int, ts int64, price, size float64) []CandleUpdate {
+	var updates []CandleUpdate
+	st := b.state(productID)
+	bucket := ts / b.interval * b.interval
+
+	switch {
+	case tradeID != 0 && tradeID <= st.lastTradeID:
+	case ts < st.cutoff:
+	case st.current != nil && bucket < st.bucket:
+	case st.current == nil && st.next != 0 && bucket < st.next:
+	default:
+		if tradeID != 0 {
+			st.lastTradeID = tradeID
+		}
+		if st.current != nil && bucket > st.bucket {
+			updates = append(updates, b.closeCurrent(productID, st))
+		}
+		updates = append(updates, b.closeGaps(productID, st, bucket)...)
+
+		if st.current == nil {
+			st.current = &Candle{HistoricRate: HistoricRate{
+				Time: nanosToSeconds(bucket), Open: price, High: price, Low: price, Close: price,
+			}}
+			st.bucket = bucket
+		}
+		c := st.current
+		c.High = math.Max(c.High, price)
+		c.Low = math.Min(c.Low, price)
+		c.Close = price
+		c.Volume += size
+		st.lastClose, st.hasClose = price, true
+		updates = append(updates, CandleUpdate{ProductID: productID, Candle: *c})
+	}
+	return updates
+}
+
+// CloseUntil closes every candle whose interval ended at or before now, including flat candles
+// for intervals with no trades. Call it on a timer so quiet products still produce closed candles.
+func (b *CandleBuilder) CloseUntil(now time.Time) {
+	b.mu.Lock()
+	var updates []CandleUpdate
+	upTo := now.UnixNano() / b.interval * b.interval
+	for productID, st := range b.products {
+		if st.current != nil && st.bucket+b.interval <= now.UnixNano() {
+			updates = append(updates, b.closeCurrent(productID, st))
+		}
+		if st.current == nil {
+			updates = append(updates, b.closeGaps(productID, st, upTo)...)
+		}
+	}
+	b.unlockAndEmit(updates)
+}
+
+// Current returns the live candle of a product, if it has one.
+func (b *CandleBuilder) Current(productID string) (Candle, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st, ok := b.products[productID]
+	if !ok || st.current == nil {
+		return Candle{}, false
+	}
+	return *st.current, true
+}
+
+func (b *CandleBuilder) closeCurrent(productID string, st *candleState) CandleUpdate {
+	u := CandleUpdate{ProductID: productID, Candle: *st.current, Closed: true}
+	st.next = st.bucket + b.interval
+	st.current = nil
+	return u
+}
+
+// closeGaps closes flat candles for every interval from st.next up to (but excluding) bucket.
+func (b *CandleBuilder) closeGaps(productID string, st *candleState, bucket int64) []CandleUpdate {
+	var updates []CandleUpdate
+	if !st.hasClose || st.next == 0 {
+		return updates
+	}
+	for ; st.next < bucket; st.next += b.interval {
+		p := st.lastClose
+		updates = append(updates, CandleUpdate{
+			ProductID: productID,
+			Candle:    Candle{HistoricRate: HistoricRate{Time: nanosToSeconds(st.next), Open: p, High: p, Low: p, Close: p}, Missing: true},
+			Closed:    true,
+		})
+	}
+	return updates
+}
+
+func (b *CandleBuilder) state(productID string) *candleState {
+	st, ok := b.products[productID]
+	if !ok {
+		st = &candleState{}
+		b.products[productID] = st
+	}
+	return st
+}
+
+// unlockAndEmit releases b.mu and hands updates to the handler. The emit lock is taken before b.mu
+// is released, so no later updates can be handed over before these.
+func (b *CandleBuilder) unlockAndEmit(updates []CandleUpdate) {
+	b.emitMu.Lock()
+	defer b.emitMu.Unlock()
+	b.mu.Unlock()
+
+	if b.handler == nil {
+		return
+	}
+	for _, u := range updates {
+		b.handler(u)
+	}
+}
+
+func secondsToNanos(s float64) int64  { return int64(math.Round(s * 1e9)) }
+func nanosToSeconds(ns int64) float64 { return float64(ns) / 1e9 }
@@ -0,0 +1,212 @@
+package godax
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func match(tradeID int, at time.Time, price, size string) FeedMessage {
+	return FeedMessage{
+		Type:      FeedMatch,
+		TradeID:   tradeID,
+		ProductID: "BTC-USD",
+		Time:      at.UTC().Format(time.RFC3339Nano),
+		Price:     price,
+		Size:      size,
+	}
+}
+
+func TestCandleBuilder(t *testing.T) {
+	var updates []CandleUpdate
+	b, err := NewCandleBuilder(10*time.Second, func(u CandleUpdate) { updates = append(updates, u) })
+	if err != nil {
+		t.Fatalf("NewCandleBuilder() error = %v", err)
+	}
+	base := time.Unix(1600000000, 0)
+
+	msgs := []FeedMessage{
+		match(1, base.Add(1*time.Second), "100", "1"),
+		match(2, base.Add(4*time.Second), "105", "0.5"),
+		match(2, base.Add(4*time.Second), "105", "0.5"), // duplicate
+		match(3, base.Add(9*time.Second), "98", "2"),
+		match(4, base.Add(31*time.Second), "101", "1"),
+		match(5, base.Add(5*time.Second), "1", "1"), // late, its candle already closed
+		{Type: "heartbeat"},
+	}
+	for _, m := range msgs {
+		if err := b.HandleMatch(m); err != nil {
+			t.Fatalf("HandleMatch() error = %v", err)
+		}
+	}
+
+	var closed []Candle
+	live := 0
+	for _, u := range updates {
+		if u.ProductID != "BTC-USD" {
+			t.Errorf("update product = %s, want BTC-USD", u.ProductID)
+		}
+		if u.Closed {
+			closed = append(closed, u.Candle)
+		} else {
+			live++
+		}
+	}
+	if live != 4 {
+		t.Errorf("got %d live updates, want 4", live)
+	}
+	want := []Candle{
+		{HistoricRate: HistoricRate{Time: 1600000000, Open: 100, High: 105, Low: 98, Close: 98, Volume: 3.5}},
+		{HistoricRate: HistoricRate{Time: 1600000010, Open: 98, High: 98, Low: 98, Close: 98}, Missing: true},
+		{HistoricRate: HistoricRate{Time: 1600000020, Open: 98, High: 98, Low: 98, Close: 98}, Missing: true},
+	}
+	if !reflect.DeepEqual(closed, want) {
+		t.Errorf("closed candles = %+v, want %+v", closed, want)
+	}
+
+	cur, ok := b.Current("BTC-USD")
+	if !ok || cur.Time != 1600000030 || cur.Close != 101 {
+		t.Errorf("Current() = %+v, %v, want the live 1600000030 candle", cur, ok)
+	}
+
+	updates = nil
+	b.CloseUntil(base.Add(55 * time.Second))
+	if len(updates) != 2 || !updates[0].Closed || updates[0].Candle.Close != 101 || !updates[1].Candle.Missing {
+		t.Errorf("CloseUntil() updates = %+v, want the live candle closed and one flat candle", updates)
+	}
+	if _, ok := b.Current("BTC-USD"); ok {
+		t.Error("there should be no live candle after CloseUntil")
+	}
+}
+
+func TestCandleBuilder_Seed(t *testing.T) {
+	var updates []CandleUpdate
+	b, err := NewCandleBuilder(2*time.Minute, func(u CandleUpdate) { updates = append(updates, u) })
+	if err != nil {
+		t.Fatalf("NewCandleBuilder() error = %v", err)
+	}
+
+	// one minute history from 0 through 120, so the last two minute candle (120-240) is still in progress
+	history := NewCandleSeries([]HistoricRate{
+		{Time: 0, Open: 10, High: 11, Low: 9, Close: 10, Volume: 1},
+		{Time: 60, Open: 10, High: 12, Low: 10, Close: 12, Volume: 1},
+		{Time: 120, Open: 12, High: 13, Low: 12, Close: 13, Volume: 1},
+	}, OneMinute)
+
+	warm, err := b.Seed("BTC-USD", history)
+	if err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	wantWarm := []Candle{{HistoricRate: HistoricRate{Time: 0, Open: 10, High: 12, Low: 9, Close: 12, Volume: 2}}}
+	if !reflect.DeepEqual(warm, wantWarm) {
+		t.Errorf("Seed() = %+v, want %+v", warm, wantWarm)
+	}
+	if cur, ok := b.Current("BTC-USD"); !ok || cur.Time != 120 {
+		t.Errorf("Current() = %+v, %v, want the in progress seeded candle", cur, ok)
+	}
+
+	b.HandleMatch(match(1, time.Unix(150, 0), "50", "1")) // inside the backfill, skipped
+	b.HandleMatch(match(2, time.Unix(200, 0), "11", "1")) // after the backfill, continues the seeded candle
+	b.HandleMatch(match(3, time.Unix(245, 0), "14", "1")) // closes the seeded candle
+
+	if len(updates) != 3 {
+		t.Fatalf("got %d updates, want 3: %+v", len(updates), updates)
+	}
+	wantClosed := Candle{HistoricRate: HistoricRate{Time: 120, Open: 12, High: 13, Low: 11, Close: 11, Volume: 2}}
+	if !updates[1].Closed || !reflect.DeepEqual(updates[1].Candle, wantClosed) {
+		t.Errorf("second update = %+v, want the seeded candle %+v closed", updates[1], wantClosed)
+	}
+	if cur, ok := b.Current("BTC-USD"); !ok || cur.Time != 240 || cur.Open != 14 {
+		t.Errorf("Current() = %+v, %v, want a new live candle at 240", cur, ok)
+	}
+
+	if _, err := b.Seed("ETH-USD", CandleSeries{Granularity: Granularity(90)}); err != ErrInvalidResample {
+		t.Errorf("Seed() with an incompatible granularity error = %v, want ErrInvalidResample", err)
+	}
+	if _, err := NewCandleBuilder(0, nil); err != ErrInvalidInterval {
+		t.Errorf("NewCandleBuilder(0) error = %v, want ErrInvalidInterval", err)
+	}
+}
+
+func TestCandleBuilder_SeedTrades(t *testing.T) {
+	var updates []CandleUpdate
+	b, err := NewCandleBuilder(10*time.Second, func(u CandleUpdate) { updates = append(updates, u) })
+	if err != nil {
+		t.Fatalf("NewCandleBuilder() error = %v", err)
+	}
+
+	// no REST candle is small enough for 10s bars
+	minutes := NewCandleSeries([]HistoricRate{{Time: 0, Open: 1, High: 1, Low: 1, Close: 1}}, OneMinute)
+	if _, err := b.Seed("BTC-USD", minutes); err != ErrInvalidResample {
+		t.Errorf("Seed() of 10s bars from minutes error = %v, want ErrInvalidResample", err)
+	}
+
+	// ListTradesByProduct order, newest first
+	trades := []Trade{
+		{TradeID: 4, Time: "1970-01-01T00:00:25.5Z", Price: "13", Size: "1"},
+		{TradeID: 3, Time: "1970-01-01T00:00:12Z", Price: "12", Size: "2"},
+		{TradeID: 2, Time: "1970-01-01T00:00:03.25Z", Price: "9", Size: "1"},
+		{TradeID: 1, Time: "1970-01-01T00:00:01Z", Price: "10", Size: "1"},
+	}
+	warm, err := b.SeedTrades("BTC-USD", trades)
+	if err != nil {
+		t.Fatalf("SeedTrades() error = %v", err)
+	}
+	wantWarm := []Candle{
+		{HistoricRate: HistoricRate{Time: 0, Open: 10, High: 10, Low: 9, Close: 9, Volume: 2}},
+		{HistoricRate: HistoricRate{Time: 10, Open: 12, High: 12, Low: 12, Close: 12, Volume: 2}},
+	}
+	if !reflect.DeepEqual(warm, wantWarm) {
+		t.Errorf("SeedTrades() = %+v, want %+v", warm, wantWarm)
+	}
+	if len(updates) != 0 {
+		t.Errorf("SeedTrades() emitted %+v, want the warm-up returned only", updates)
+	}
+
+	b.HandleMatch(match(4, time.Unix(25, 0), "50", "1")) // already seeded, skipped
+	b.HandleMatch(match(5, time.Unix(27, 0), "14", "1"))
+	if cur, ok := b.Current("BTC-USD"); !ok || cur.Time != 20 || cur.Open != 13 || cur.Close != 14 || cur.Volume != 2 {
+		t.Errorf("Current() = %+v, %v, want the seeded 20s candle continued", cur, ok)
+	}
+}
+
+func TestCandleBuilder_OrderedAcrossGoroutines(t *testing.T) {
+	var mu sync.Mutex
+	var updates []CandleUpdate
+	entered, release := make(chan struct{}), make(chan struct{})
+	b, _ := NewCandleBuilder(time.Second, func(u CandleUpdate) {
+		mu.Lock()
+		updates = append(updates, u)
+		first := len(updates) == 1
+		mu.Unlock()
+		if first {
+			close(entered)
+			<-release
+		}
+	})
+	base := time.Unix(1600000000, 0)
+
+	// the feed goroutine is still handing over a live candle when a timer closes it
+	go b.HandleMatch(match(1, base, "100", "1"))
+	<-entered
+	closed := make(chan struct{})
+	go func() {
+		b.CloseUntil(base.Add(2 * time.Second))
+		close(closed)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	early := len(updates)
+	mu.Unlock()
+	close(release)
+	<-closed
+
+	if early != 1 {
+		t.Errorf("the closed candle was handed over while the live one still was")
+	}
+	if len(updates) != 3 || updates[0].Closed || !updates[1].Closed {
+		t.Errorf("updates = %+v, want the live candle, then it closed, then a flat one", updates)
+	}
+}
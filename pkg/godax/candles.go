@@ -0,0 +1,247 @@
+package godax
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Granularity is the width of a candle in seconds. Coinbase pro only serves the granularities
+// declared below, but a CandleSeries can be resampled to any multiple of them (4h for example).
+type Granularity int
+
+// Granularities accepted by GetHistoricRatesForProduct
+const (
+	OneMinute      Granularity = 60
+	FiveMinutes    Granularity = 300
+	FifteenMinutes Granularity = 900
+	OneHour        Granularity = 3600
+	SixHours       Granularity = 21600
+	OneDay         Granularity = 86400
+)
+
+// Valid reports whether coinbase pro accepts g as a granularity.
+func (g Granularity) Valid() bool {
+	switch g {
+	case OneMinute, FiveMinutes, FifteenMinutes, OneHour, SixHours, OneDay:
+		return true
+	}
+	return false
+}
+
+// Duration returns g as a time.Duration.
+func (g Granularity) Duration() time.Duration {
+	return time.Duration(g) * time.Second
+}
+
+// String returns g in seconds, ready to be used as the GranularityParam.
+func (g Granularity) String() string {
+	return strconv.Itoa(int(g))
+}
+
+// maxCandlesPerRequest is the most candles coinbase pro returns from one call to GetHistoricRatesForProduct.
+const maxCandlesPerRequest = 300
+
+// publicRequestInterval keeps backfills within the public endpoint rate limit of 3 requests per second.
+const publicRequestInterval = time.Second / 3
+
+// sleep is swapped out in tests so backfills don't have to wait on the rate limit.
+var sleep = time.Sleep
+
+// Backfill errors
+var (
+	ErrInvalidGranularity = errors.New("granularity must be one of 60, 300, 900, 3600, 21600 or 86400 seconds")
+	ErrInvalidTimeRange   = errors.New("please provide a start time that is before the end time")
+	ErrInvalidResample    = errors.New("a series can only be resampled to a positive multiple of its granularity")
+)
+
+// Candle is one bucket of a contiguous candle series. Missing is true when coinbase pro
+// published no data for the bucket, in which case only Time is set.
+type Candle struct {
+	HistoricRate
+	Missing bool
+}
+
+// BackfillHistoricRates fetches every candle for a product between start (inclusive) and end
+// (exclusive). The range is split into windows of at most 300 candles, which are requested one
+// after another within the public rate limit. The results are deduped and sorted oldest first
+// into a contiguous CandleSeries with one Candle per bucket, where buckets with no data are marked
+// Missing. Start is aligned down to the granularity so buckets line up with coinbase pro's.
+func BackfillHistoricRates(md MarketDataAPI, productID string, start, end time.Time, granularity Granularity) (CandleSeries, error) {
+	if !granularity.Valid() {
+		return CandleSeries{}, ErrInvalidGranularity
+	}
+	g := int64(granularity)
+	from := start.Unix() / g * g
+	to := end.Unix()
+	if from >= to {
+		return CandleSeries{}, ErrInvalidTimeRange
+	}
+
+	byTime := make(map[int64]HistoricRate)
+	window := g * maxCandlesPerRequest
+	for ws := from; ws < to; ws += window {
+		if ws != from {
+			sleep(publicRequestInterval)
+		}
+		we := ws + window - g
+		if we >= to {
+			we = to - 1
+		}
+		rates, err := md.GetHistoricRatesForProduct(productID, QueryParams{
+			StartParam:       time.Unix(ws, 0).UTC().Format(time.RFC3339),
+			EndParam:         time.Unix(we, 0).UTC().Format(time.RFC3339),
+			GranularityParam: granularity.String(),
+		})
+		if err != nil {
+			return CandleSeries{}, err
+		}
+		for _, r := range rates {
+			t := int64(r.Time)
+			if t < from || t >= to {
+				continue
+			}
+			byTime[t] = r
+		}
+	}
+
+	return fillSeries(byTime, granularity, from, to), nil
+}
+
+// CandleSeries is a contiguous, oldest first run of candles of a single granularity.
+// Buckets with no data are present and marked Missing rather than skipped.
+type CandleSeries struct {
+	Granularity Granularity
+	Candles     []Candle
+}
+
+// NewCandleSeries builds a CandleSeries from rates as returned by GetHistoricRatesForProduct.
+// Rates are deduped and sorted, and any gaps between the first and last rate are marked Missing.
+func NewCandleSeries(rates []HistoricRate, granularity Granularity) CandleSeries {
+	if len(rates) == 0 {
+		return CandleSeries{Granularity: granularity}
+	}
+	g := int64(granularity)
+	byTime := make(map[int64]HistoricRate, len(rates))
+	from, to := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, r := range rates {
+		t := int64(r.Time) / g * g
+		r.Time = float64(t)
+		byTime[t] = r
+		if t < from {
+			from = t
+		}
+		if t > to {
+			to = t
+		}
+	}
+	return fillSeries(byTime, granularity, from, to+g)
+}
+
+// fillSeries lays out one candle per bucket from (inclusive) to (exclusive).
+func fillSeries(byTime map[int64]HistoricRate, granularity Granularity, from, to int64) CandleSeries {
+	g := int64(granularity)
+	s := CandleSeries{Granularity: granularity}
+	for t := from; t < to; t += g {
+		r, ok := byTime[t]
+		if !ok {
+			s.Candles = append(s.Candles, Candle{HistoricRate: HistoricRate{Time: float64(t)}, Missing: true})
+			continue
+		}
+		s.Candles = append(s.Candles, Candle{HistoricRate: r})
+	}
+	return s
+}
+
+// ForwardFill returns a copy of the series where every Missing candle carries the previous close as
+// its open, high, low and close with zero volume. The candles stay marked Missing. Missing candles
+// at the start of the series, before any data, are left empty.
+func (s CandleSeries) ForwardFill() CandleSeries {
+	filled := CandleSeries{Granularity: s.Granularity, Candles: make([]Candle, len(s.Candles))}
+	var last *Candle
+	for i, c := range s.Candles {
+		if c.Missing && last != nil {
+			c.Open, c.High, c.Low, c.Close, c.Volume = last.Close, last.Close, last.Close, last.Close, 0
+		}
+		filled.Candles[i] = c
+		if !c.Missing || last != nil {
+			last = &filled.Candles[i]
+		}
+	}
+	return filled
+}
+
+// Resample aggregates the series into coarser candles of granularity to, which must be a multiple of
+// the series' granularity (for example 1m into 4h). Buckets are aligned to the unix epoch, so the
+// first and last candles may be built from a partial bucket. A resampled candle is Missing only when
+// every candle that went into it was.
+func (s CandleSeries) Resample(to Granularity) (CandleSeries, error) {
+	if s.Granularity <= 0 || to <= 0 || to%s.Granularity != 0 {
+		return CandleSeries{}, ErrInvalidResample
+	}
+	out := CandleSeries{Granularity: to}
+	width := int64(to)
+	for _, c := range s.Candles {
+		bucket := float64(int64(c.Time) / width * width)
+		n := len(out.Candles)
+		if n == 0 || out.Candles[n-1].Time != bucket {
+			out.Candles = append(out.Candles, Candle{HistoricRate: HistoricRate{Time: bucket}, Missing: true})
+			n++
+		}
+		agg := &out.Candles[n-1]
+		if c.Missing {
+			continue
+		}
+		if agg.Missing {
+			agg.Open, agg.High, agg.Low = c.Open, c.High, c.Low
+			agg.Missing = false
+		}
+		agg.High = math.Max(agg.High, c.High)
+		agg.Low = math.Min(agg.Low, c.Low)
+		agg.Close = c.Close
+		agg.Volume += c.Volume
+	}
+	return out, nil
+}
+
+// OHLCV holds a candle series as parallel arrays, the shape most indicator code expects.
+type OHLCV struct {
+	Time   []float64
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+}
+
+// OHLCV converts the series into parallel arrays. Missing candles are included as they are,
+// so call ForwardFill first when downstream code can't handle empty buckets.
+func (s CandleSeries) OHLCV() OHLCV {
+	n := len(s.Candles)
+	o := OHLCV{
+		Time:   make([]float64, n),
+		Open:   make([]float64, n),
+		High:   make([]float64, n),
+		Low:    make([]float64, n),
+		Close:  make([]float64, n),
+		Volume: make([]float64, n),
+	}
+	for i, c := range s.Candles {
+		o.Time[i], o.Open[i], o.High[i], o.Low[i], o.Close[i], o.Volume[i] = c.Time, c.Open, c.High, c.Low, c.Close, c.Volume
+	}
+	return o
+}
+
+// Rates returns the series' candles that have data as plain HistoricRates, oldest first.
+func (s CandleSeries) Rates() []HistoricRate {
+	rates := make([]HistoricRate, 0, len(s.Candles))
+	for _, c := range s.Candles {
+		if !c.Missing {
+			rates = append(rates, c.HistoricRate)
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Time < rates[j].Time })
+	return rates
+}
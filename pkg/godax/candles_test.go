@@ -0,0 +1,176 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func noSleep(t *testing.T) {
+	orig := sleep
+	sleep = func(time.Duration) {}
+	t.Cleanup(func() { sleep = orig })
+}
+
+// candleServer returns a fake that answers candle requests within the requested window, newest
+// first like coinbase pro, skipping any bucket times in missing and repeating the window edges.
+func candleServer(t *testing.T, granularity int64, missing map[int64]bool, queries *[]QueryParams) *FakeMarketDataAPI {
+	return &FakeMarketDataAPI{
+		GetHistoricRatesForProductFunc: func(productID string, qp QueryParams) ([]HistoricRate, error) {
+			*queries = append(*queries, qp)
+			start, err := time.Parse(time.RFC3339, qp[StartParam])
+			if err != nil {
+				t.Fatalf("bad start param: %v", err)
+			}
+			end, err := time.Parse(time.RFC3339, qp[EndParam])
+			if err != nil {
+				t.Fatalf("bad end param: %v", err)
+			}
+			if n := (end.Unix()-start.Unix())/granularity + 1; n > maxCandlesPerRequest {
+				t.Fatalf("requested %d candles, more than the %d allowed", n, maxCandlesPerRequest)
+			}
+
+			var rates []HistoricRate
+			for ts := end.Unix() / granularity * granularity; ts >= start.Unix()-granularity; ts -= granularity {
+				if missing[ts] {
+					continue
+				}
+				rates = append(rates, HistoricRate{Time: float64(ts), Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10})
+			}
+			return rates, nil
+		},
+	}
+}
+
+func TestBackfillHistoricRates(t *testing.T) {
+	noSleep(t)
+	start := time.Unix(1599999990, 0) // aligns down to 1599999960
+	end := time.Unix(1599999960+700*60, 0)
+	missing := map[int64]bool{1599999960 + 10*60: true, 1599999960 + 450*60: true}
+
+	var queries []QueryParams
+	md := candleServer(t, 60, missing, &queries)
+
+	got, err := BackfillHistoricRates(md, "BTC-USD", start, end, 60)
+	if err != nil {
+		t.Fatalf("BackfillHistoricRates() error = %v", err)
+	}
+
+	if len(queries) != 3 {
+		t.Errorf("made %d requests, want 3", len(queries))
+	}
+	if len(got.Candles) != 700 {
+		t.Fatalf("got %d candles, want 700", len(got.Candles))
+	}
+	for i, c := range got.Candles {
+		wantTime := float64(1599999960 + int64(i)*60)
+		if c.Time != wantTime {
+			t.Fatalf("candle %d time = %v, want %v", i, c.Time, wantTime)
+		}
+		if c.Missing != missing[int64(c.Time)] {
+			t.Errorf("candle %d missing = %v, want %v", i, c.Missing, !c.Missing)
+		}
+		if !c.Missing && c.Close != 1.5 {
+			t.Errorf("candle %d close = %v, want 1.5", i, c.Close)
+		}
+	}
+}
+
+func TestBackfillHistoricRates_Errors(t *testing.T) {
+	noSleep(t)
+	now := time.Now()
+
+	if _, err := BackfillHistoricRates(&FakeMarketDataAPI{}, "BTC-USD", now.Add(-time.Hour), now, 120); err != ErrInvalidGranularity {
+		t.Errorf("invalid granularity error = %v, want ErrInvalidGranularity", err)
+	}
+	if _, err := BackfillHistoricRates(&FakeMarketDataAPI{}, "BTC-USD", now, now.Add(-time.Hour), 60); err != ErrInvalidTimeRange {
+		t.Errorf("reversed range error = %v, want ErrInvalidTimeRange", err)
+	}
+	if _, err := BackfillHistoricRates(&FakeMarketDataAPI{}, "BTC-USD", now.Add(-time.Hour), now, 60); err != ErrNotStubbed {
+		t.Errorf("request error = %v, want it passed through", err)
+	}
+}
+
+func TestGranularity(t *testing.T) {
+	if !OneHour.Valid() || Granularity(14400).Valid() {
+		t.Error("only coinbase pro's granularities should be valid")
+	}
+	if OneHour.Duration() != time.Hour {
+		t.Errorf("OneHour.Duration() = %v, want 1h", OneHour.Duration())
+	}
+	if FiveMinutes.String() != "300" {
+		t.Errorf("FiveMinutes.String() = %s, want 300", FiveMinutes.String())
+	}
+}
+
+func TestCandleSeries(t *testing.T) {
+	// coinbase pro returns candles newest first, with the odd duplicate
+	rates := []HistoricRate{
+		{Time: 300, Low: 9, High: 12, Open: 10, Close: 11, Volume: 3},
+		{Time: 120, Low: 7, High: 9, Open: 8, Close: 8.5, Volume: 2},
+		{Time: 60, Low: 5, High: 8, Open: 6, Close: 7, Volume: 1},
+		{Time: 60, Low: 5, High: 8, Open: 6, Close: 7, Volume: 1},
+	}
+
+	s := NewCandleSeries(rates, OneMinute)
+	var times []float64
+	var missing []bool
+	for _, c := range s.Candles {
+		times = append(times, c.Time)
+		missing = append(missing, c.Missing)
+	}
+	if !reflect.DeepEqual(times, []float64{60, 120, 180, 240, 300}) {
+		t.Fatalf("series times = %v, want 60 through 300", times)
+	}
+	if !reflect.DeepEqual(missing, []bool{false, false, true, true, false}) {
+		t.Errorf("series missing = %v, want 180 and 240 missing", missing)
+	}
+
+	t.Run("forward fill carries the last close into missing candles", func(t *testing.T) {
+		filled := s.ForwardFill()
+		for _, i := range []int{2, 3} {
+			c := filled.Candles[i]
+			if !c.Missing || c.Open != 8.5 || c.High != 8.5 || c.Low != 8.5 || c.Close != 8.5 || c.Volume != 0 {
+				t.Errorf("filled candle %d = %+v, want missing with every price at 8.5", i, c)
+			}
+		}
+		if s.Candles[2].Close != 0 {
+			t.Error("ForwardFill should not modify the original series")
+		}
+	})
+
+	t.Run("resample aggregates into coarser candles", func(t *testing.T) {
+		got, err := s.Resample(Granularity(180))
+		if err != nil {
+			t.Fatalf("Resample() error = %v", err)
+		}
+		want := []Candle{
+			{HistoricRate: HistoricRate{Time: 0, Low: 5, High: 9, Open: 6, Close: 8.5, Volume: 3}},
+			{HistoricRate: HistoricRate{Time: 180, Low: 9, High: 12, Open: 10, Close: 11, Volume: 3}},
+		}
+		if got.Granularity != 180 || !reflect.DeepEqual(got.Candles, want) {
+			t.Errorf("Resample() = %+v, want %+v", got, want)
+		}
+
+		if _, err := s.Resample(Granularity(90)); err != ErrInvalidResample {
+			t.Errorf("Resample() to a non multiple error = %v, want ErrInvalidResample", err)
+		}
+	})
+
+	t.Run("OHLCV converts to parallel arrays", func(t *testing.T) {
+		o := s.ForwardFill().OHLCV()
+		if !reflect.DeepEqual(o.Close, []float64{7, 8.5, 8.5, 8.5, 11}) {
+			t.Errorf("OHLCV().Close = %v", o.Close)
+		}
+		if !reflect.DeepEqual(o.Volume, []float64{1, 2, 0, 0, 3}) {
+			t.Errorf("OHLCV().Volume = %v", o.Volume)
+		}
+		if len(o.Time) != 5 || len(o.Open) != 5 || len(o.High) != 5 || len(o.Low) != 5 {
+			t.Error("every OHLCV array should have one entry per candle")
+		}
+	})
+
+	if got := s.Rates(); len(got) != 3 || got[0].Time != 60 {
+		t.Errorf("Rates() = %+v, want the 3 candles with data, oldest first", got)
+	}
+}
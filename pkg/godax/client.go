@@ -25,31 +25,71 @@ type CoinbaseErrRes struct {
 	Message string `json:"message"`
 }
 
-func newClient(sandbox bool) (*Client, error) {
+func newClient(sandbox bool, opts ...ClientOption) (*Client, error) {
 	c := &Client{
 		httpClient: retryablehttp.NewClient().StandardClient(),
 	}
 	if err := c.loadEnv(sandbox); err != nil {
 		return nil, err
 	}
+	if err := c.apply(opts); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+func (c *Client) apply(opts []ClientOption) error {
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // exec note: decodes into the value pointed at by v. In other words, `v` cannot be nil or a non-pointer.
 func (c *Client) exec(timestamp, method, path string, body []byte, qp *QueryParams, v interface{}) error {
+	_, err := c.execPage(timestamp, method, path, body, qp, v)
+	return err
+}
+
+// execPage is exec for paginated endpoints, it also returns the CB-AFTER cursor of the response.
+// The cursor is empty when there are no more pages.
+func (c *Client) execPage(timestamp, method, path string, body []byte, qp *QueryParams, v interface{}) (string, error) {
 	req, sig, err := c.createAndSignReq(timestamp, method, path, body, qp)
 	if err != nil {
-		return err
+		return "", err
 	}
 	res, err := c.do(timestamp, sig, req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer res.Body.Close()
 	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
-		return err
+		return "", err
+	}
+	return res.Header.Get("CB-AFTER"), nil
+}
+
+// paginate fetches every page of a paginated endpoint, following the CB-AFTER cursor. fetch gets
+// each page and returns how many items it held and its cursor. If qp already asks for a page with
+// BeforeParam or AfterParam only that page is fetched. qp itself is never modified.
+func paginate(qp QueryParams, fetch func(page *QueryParams) (n int, after string, err error)) error {
+	page := QueryParams{}
+	for k, v := range qp {
+		page[k] = v
+	}
+	single := page[BeforeParam] != "" || page[AfterParam] != ""
+	for {
+		n, after, err := fetch(&page)
+		if err != nil {
+			return err
+		}
+		if single || n == 0 || after == "" || after == page[AfterParam] {
+			return nil
+		}
+		page[AfterParam] = after
 	}
-	return nil
 }
 
 func (c *Client) createAndSignReq(timestamp, method, path string, body []byte, qp *QueryParams) (*http.Request, string, error) {
@@ -91,6 +131,31 @@ func (c *Client) generateSig(timestamp, method, path, body string) (string, erro
 
 func (c *Client) do(timestamp string, signature string, req *http.Request) (*http.Response, error) {
 	c.setHeaders(req, timestamp, signature)
+	if req.Method == http.MethodGet && c.dryRun != nil {
+		if res, ok := c.dryRunGet(req.URL.Path); ok {
+			return res, nil
+		}
+	}
+	if req.Method != http.MethodGet {
+		if c.dryRun != nil {
+			return c.dryRunDo(req)
+		}
+		if c.baseRestURL == liveREST {
+			release, err := c.guardLive(req)
+			if err != nil {
+				return nil, err
+			}
+			res, err := c.send(req)
+			if err != nil {
+				release()
+			}
+			return res, err
+		}
+	}
+	return c.send(req)
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
 	res, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -121,10 +186,30 @@ func (c *Client) setQueryParams(req *http.Request, qp QueryParams) {
 	req.URL.RawQuery = q.Encode()
 }
 
+// StatusError is returned when coinbase pro responds with a non-200 status code.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code: %d, message: %s", e.StatusCode, e.Message)
+}
+
 func coinbaseError(res *http.Response) error {
 	var err CoinbaseErrRes
 	if err := json.NewDecoder(res.Body).Decode(&err); err != nil {
 		return err
 	}
-	return fmt.Errorf("status code: %d, message: %s", res.StatusCode, err.Message)
+	return &StatusError{StatusCode: res.StatusCode, Message: err.Message}
+}
+
+// isNotFound reports whether err is a 404 from coinbase pro, which is what you get when looking
+// up an order that was canceled without any matches, or the paper client's equivalent.
+func isNotFound(err error) bool {
+	if err == ErrPaperOrderNotFound {
+		return true
+	}
+	se, ok := err.(*StatusError)
+	return ok && se.StatusCode == http.StatusNotFound
 }
@@ -0,0 +1,248 @@
+package godax
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reasons a DeadMansSwitch fires
+const (
+	DeadMansSwitchHeartbeat = "heartbeat"
+	DeadMansSwitchFeed      = "feed"
+	DeadMansSwitchSignal    = "signal"
+	DeadMansSwitchManual    = "manual"
+)
+
+// defaultCheckInterval is how often a running DeadMansSwitch checks its timeouts.
+const defaultCheckInterval = time.Second
+
+// DeadMansSwitchConfig configures a DeadMansSwitch. A zero timeout turns that check off.
+type DeadMansSwitchConfig struct {
+	// ProductIDs limits the cancel to these products, one CancelAllOrders call each. When empty
+	// a single CancelAllOrders call cancels open orders across every product.
+	ProductIDs []string
+
+	// HeartbeatTimeout is how long the application can go without calling Heartbeat.
+	HeartbeatTimeout time.Duration
+
+	// FeedTimeout is how long the websocket feed can go without a message passed to HandleFeedMessage.
+	FeedTimeout time.Duration
+
+	// Signals are the process signals that fire the switch while Run is running. Defaults to
+	// os.Interrupt and SIGTERM.
+	Signals []os.Signal
+
+	// CheckInterval is how often Run checks the timeouts. Defaults to 1 second.
+	CheckInterval time.Duration
+
+	// OnError is called by Run with every failed attempt to cancel orders. Run keeps the switch
+	// armed and tries again on the next check, so this is where to log or alert. Optional.
+	OnError func(error)
+}
+
+// DeadMansSwitch cancels all open orders when the application or its feed goes quiet, so a crashed
+// or hung trading bot doesn't leave stale orders resting on the book. Once armed, the application
+// calls Heartbeat regularly and hands feed messages to HandleFeedMessage. If either stops for longer
+// than its timeout, or the process gets one of the configured signals, the switch calls
+// CancelAllOrders and disarms itself.
+//
+// Run checks the timeouts and listens for signals for you, or call Check yourself. Run takes over
+// the configured signals while it runs, so a program that relied on them to exit should exit when
+// Run returns.
+type DeadMansSwitch struct {
+	mu            sync.Mutex
+	orders        OrdersAPI
+	cfg           DeadMansSwitchConfig
+	armed         bool
+	lastHeartbeat time.Time
+	lastFeed      time.Time
+	reason        string
+	canceled      []string
+	now           func() time.Time
+}
+
+// NewDeadMansSwitch creates a disarmed DeadMansSwitch that cancels orders through orders.
+func NewDeadMansSwitch(orders OrdersAPI, cfg DeadMansSwitchConfig) *DeadMansSwitch {
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+	return &DeadMansSwitch{orders: orders, cfg: cfg, now: time.Now}
+}
+
+// Arm starts the timeouts from now. Arming a switch that already fired resets it.
+func (d *DeadMansSwitch) Arm() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.armed = true
+	d.lastHeartbeat = now
+	d.lastFeed = now
+	d.reason = ""
+	d.canceled = nil
+}
+
+// Disarm stops the switch from firing, for example before a planned shutdown that keeps orders resting.
+func (d *DeadMansSwitch) Disarm() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.armed = false
+}
+
+// Armed reports whether the switch is armed.
+func (d *DeadMansSwitch) Armed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.armed
+}
+
+// Heartbeat tells the switch the application is still alive.
+func (d *DeadMansSwitch) Heartbeat() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastHeartbeat = d.now()
+}
+
+// HandleFeedMessage tells the switch the feed is still alive. Any message type counts, including
+// the feed's own heartbeats.
+func (d *DeadMansSwitch) HandleFeedMessage(msg FeedMessage) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastFeed = d.now()
+}
+
+// Check fires the switch if it is armed and a timeout has passed as of now. It returns whether the
+// switch fired. If canceling fails the switch stays armed so the next Check tries again.
+func (d *DeadMansSwitch) Check(now time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.armed {
+		return false, nil
+	}
+	if d.cfg.HeartbeatTimeout > 0 && now.Sub(d.lastHeartbeat) > d.cfg.HeartbeatTimeout {
+		return true, d.fire(DeadMansSwitchHeartbeat)
+	}
+	if d.cfg.FeedTimeout > 0 && now.Sub(d.lastFeed) > d.cfg.FeedTimeout {
+		return true, d.fire(DeadMansSwitchFeed)
+	}
+	return false, nil
+}
+
+// Trigger fires the switch right away if it is armed.
+func (d *DeadMansSwitch) Trigger() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.armed {
+		return nil
+	}
+	return d.fire(DeadMansSwitchManual)
+}
+
+// Fired returns why the switch last fired and the IDs of the orders it canceled. The reason is
+// empty if it hasn't fired since it was armed.
+func (d *DeadMansSwitch) Fired() (string, []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reason, append([]string(nil), d.canceled...)
+}
+
+// Run checks the switch every CheckInterval and fires it on any of the configured signals. It
+// returns once the switch fires, when a signal arrives after Disarm, or with ctx's error when ctx is
+// done. A cancel that fails doesn't stop Run: the error goes to OnError and the cancel is tried
+// again on the next check, including the one for a signal that was already received.
+func (d *DeadMansSwitch) Run(ctx context.Context) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, d.cfg.Signals...)
+	defer signal.Stop(sigs)
+
+	ticker := time.NewTicker(d.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	signaled := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sigs:
+			signaled = true
+			done, err := d.signal()
+			if done {
+				return nil
+			}
+			d.report(err)
+		case <-ticker.C:
+			if signaled {
+				done, err := d.signal()
+				if done {
+					return nil
+				}
+				d.report(err)
+				continue
+			}
+			fired, err := d.Check(d.now())
+			if err != nil {
+				d.report(err)
+				continue
+			}
+			if fired {
+				return nil
+			}
+		}
+	}
+}
+
+// signal fires the switch for a received signal. It returns true once Run is done with the signal,
+// either because the orders were canceled or because the switch was disarmed.
+func (d *DeadMansSwitch) signal() (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.armed {
+		return true, nil
+	}
+	if err := d.fire(DeadMansSwitchSignal); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (d *DeadMansSwitch) report(err error) {
+	if d.cfg.OnError != nil {
+		d.cfg.OnError(err)
+	}
+}
+
+// fire cancels all open orders and disarms the switch. Callers must hold d.mu.
+func (d *DeadMansSwitch) fire(reason string) error {
+	products := d.cfg.ProductIDs
+	if len(products) == 0 {
+		products = []string{""}
+	}
+
+	var canceled []string
+	for _, productID := range products {
+		qp := QueryParams{}
+		if productID != "" {
+			qp[ProductIDParam] = productID
+		}
+		ids, err := d.orders.CancelAllOrders(qp)
+		if err != nil {
+			return err
+		}
+		canceled = append(canceled, ids...)
+	}
+
+	d.armed = false
+	d.reason = reason
+	d.canceled = canceled
+	return nil
+}
@@ -0,0 +1,248 @@
+package godax
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func deadMansOrders(calls *[]QueryParams) *FakeOrdersAPI {
+	return &FakeOrdersAPI{
+		CancelAllOrdersFunc: func(qp QueryParams) ([]string, error) {
+			*calls = append(*calls, qp)
+			return []string{"order-" + qp[ProductIDParam]}, nil
+		},
+	}
+}
+
+func TestDeadMansSwitch_Heartbeat(t *testing.T) {
+	var calls []QueryParams
+	d := NewDeadMansSwitch(deadMansOrders(&calls), DeadMansSwitchConfig{
+		ProductIDs:       []string{"BTC-USD", "ETH-USD"},
+		HeartbeatTimeout: 10 * time.Second,
+	})
+	now := time.Unix(1600000000, 0)
+	d.now = func() time.Time { return now }
+
+	if fired, _ := d.Check(now.Add(time.Hour)); fired {
+		t.Fatal("Check() fired while disarmed")
+	}
+
+	d.Arm()
+	now = now.Add(8 * time.Second)
+	d.Heartbeat()
+	if fired, _ := d.Check(now.Add(10 * time.Second)); fired {
+		t.Fatal("Check() fired within the heartbeat timeout")
+	}
+
+	fired, err := d.Check(now.Add(11 * time.Second))
+	if err != nil || !fired {
+		t.Fatalf("Check() = %v, %v, want fired", fired, err)
+	}
+	want := []QueryParams{{ProductIDParam: "BTC-USD"}, {ProductIDParam: "ETH-USD"}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("CancelAllOrders calls = %v, want %v", calls, want)
+	}
+	reason, canceled := d.Fired()
+	if reason != DeadMansSwitchHeartbeat || !reflect.DeepEqual(canceled, []string{"order-BTC-USD", "order-ETH-USD"}) {
+		t.Errorf("Fired() = %s, %v", reason, canceled)
+	}
+	if d.Armed() {
+		t.Error("the switch should disarm once it fires")
+	}
+}
+
+func TestDeadMansSwitch_FeedSilence(t *testing.T) {
+	var calls []QueryParams
+	d := NewDeadMansSwitch(deadMansOrders(&calls), DeadMansSwitchConfig{FeedTimeout: 5 * time.Second})
+	now := time.Unix(1600000000, 0)
+	d.now = func() time.Time { return now }
+
+	d.Arm()
+	now = now.Add(4 * time.Second)
+	d.HandleFeedMessage(FeedMessage{Type: "heartbeat"})
+	if fired, _ := d.Check(now.Add(5 * time.Second)); fired {
+		t.Fatal("Check() fired within the feed timeout")
+	}
+	if fired, _ := d.Check(now.Add(6 * time.Second)); !fired {
+		t.Fatal("Check() didn't fire on a silent feed")
+	}
+	if !reflect.DeepEqual(calls, []QueryParams{{}}) {
+		t.Errorf("CancelAllOrders calls = %v, want one across every product", calls)
+	}
+	if reason, _ := d.Fired(); reason != DeadMansSwitchFeed {
+		t.Errorf("Fired() reason = %s, want %s", reason, DeadMansSwitchFeed)
+	}
+}
+
+func TestDeadMansSwitch_RetriesFailedCancel(t *testing.T) {
+	fail := errors.New("connection refused")
+	attempts := 0
+	orders := &FakeOrdersAPI{
+		CancelAllOrdersFunc: func(qp QueryParams) ([]string, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, fail
+			}
+			return nil, nil
+		},
+	}
+	d := NewDeadMansSwitch(orders, DeadMansSwitchConfig{})
+	d.Arm()
+
+	if err := d.Trigger(); err != fail {
+		t.Fatalf("Trigger() error = %v, want %v", err, fail)
+	}
+	if !d.Armed() {
+		t.Fatal("the switch should stay armed after a failed cancel")
+	}
+	if err := d.Trigger(); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if reason, _ := d.Fired(); reason != DeadMansSwitchManual || d.Armed() {
+		t.Errorf("Fired() reason = %s, armed = %v, want manual and disarmed", reason, d.Armed())
+	}
+}
+
+func TestDeadMansSwitch_Signal(t *testing.T) {
+	var calls []QueryParams
+	d := NewDeadMansSwitch(deadMansOrders(&calls), DeadMansSwitchConfig{
+		Signals:       []os.Signal{os.Interrupt},
+		CheckInterval: time.Hour,
+	})
+	d.Arm()
+
+	if err := runUntilSignaled(t, d); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if reason, _ := d.Fired(); reason != DeadMansSwitchSignal || len(calls) != 1 {
+		t.Errorf("Fired() reason = %s with %d cancels, want signal with 1", reason, len(calls))
+	}
+}
+
+func TestDeadMansSwitch_SignalWhileDisarmed(t *testing.T) {
+	var calls []QueryParams
+	d := NewDeadMansSwitch(deadMansOrders(&calls), DeadMansSwitchConfig{
+		Signals:       []os.Signal{os.Interrupt},
+		CheckInterval: time.Hour,
+	})
+	d.Arm()
+	d.Disarm()
+
+	if err := runUntilSignaled(t, d); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if reason, _ := d.Fired(); reason != "" || len(calls) != 0 {
+		t.Errorf("Fired() reason = %q with %d cancels, want a disarmed switch left alone", reason, len(calls))
+	}
+}
+
+func TestDeadMansSwitch_RunRetriesFailedSignalCancel(t *testing.T) {
+	fail := errors.New("connection refused")
+	var mu sync.Mutex
+	attempts := 0
+	var reported []error
+	orders := &FakeOrdersAPI{
+		CancelAllOrdersFunc: func(qp QueryParams) ([]string, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, fail
+			}
+			return nil, nil
+		},
+	}
+	d := NewDeadMansSwitch(orders, DeadMansSwitchConfig{
+		Signals:       []os.Signal{os.Interrupt},
+		CheckInterval: time.Millisecond,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	})
+	d.Arm()
+
+	if err := runUntilSignaled(t, d); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if reason, _ := d.Fired(); reason != DeadMansSwitchSignal || attempts != 2 {
+		t.Errorf("Fired() reason = %s after %d attempts, want signal after 2", reason, attempts)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || reported[0] != fail {
+		t.Errorf("OnError got %v, want [%v]", reported, fail)
+	}
+}
+
+func TestDeadMansSwitch_RunReportsFailedCheck(t *testing.T) {
+	fail := errors.New("connection refused")
+	attempts := 0
+	orders := &FakeOrdersAPI{
+		CancelAllOrdersFunc: func(qp QueryParams) ([]string, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, fail
+			}
+			return nil, nil
+		},
+	}
+	errs := make(chan error, 10)
+	d := NewDeadMansSwitch(orders, DeadMansSwitchConfig{
+		HeartbeatTimeout: time.Second,
+		CheckInterval:    time.Millisecond,
+		OnError:          func(err error) { errs <- err },
+	})
+	start := time.Unix(1600000000, 0)
+	d.now = func() time.Time { return start }
+	d.Arm()
+	d.now = func() time.Time { return start.Add(time.Minute) }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if reason, _ := d.Fired(); reason != DeadMansSwitchHeartbeat || attempts != 2 {
+		t.Errorf("Fired() reason = %s after %d attempts, want heartbeat after 2", reason, attempts)
+	}
+	if len(errs) != 1 || <-errs != fail {
+		t.Errorf("OnError should get the failed cancel once")
+	}
+}
+
+// runUntilSignaled runs d and interrupts the test process until Run returns.
+func runUntilSignaled(t *testing.T, d *DeadMansSwitch) error {
+	t.Helper()
+
+	// keep the default handler from killing the test if the signal beats Run's own Notify
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, os.Interrupt)
+	defer signal.Stop(guard)
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+
+	for {
+		if err := self.Signal(os.Interrupt); err != nil {
+			t.Skipf("can't signal the test process: %v", err)
+		}
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
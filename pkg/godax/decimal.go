@@ -0,0 +1,82 @@
+package godax
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Coinbase pro sends every price, size and amount as a string so no precision is lost on the way in.
+// The helpers below keep it that way by doing all of our own math with big.Rat rather than float64.
+
+// decimalPlaces is the number of places coinbase pro uses when it reports balances.
+const decimalPlaces = 16
+
+// parseDecimal parses a coinbase pro decimal string. An empty string is treated as zero since
+// optional amounts (size, funds, fees) are commonly left off of responses.
+func parseDecimal(s string) (*big.Rat, error) {
+	if s == "" {
+		return new(big.Rat), nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal: %q", s)
+	}
+	return r, nil
+}
+
+// formatDecimal formats r rounded to decimalPlaces with any trailing zeros trimmed.
+func formatDecimal(r *big.Rat) string {
+	s := r.FloatString(decimalPlaces)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func newRat() *big.Rat { return new(big.Rat) }
+
+func add(a, b *big.Rat) *big.Rat { return newRat().Add(a, b) }
+func sub(a, b *big.Rat) *big.Rat { return newRat().Sub(a, b) }
+func mul(a, b *big.Rat) *big.Rat { return newRat().Mul(a, b) }
+func quo(a, b *big.Rat) *big.Rat { return newRat().Quo(a, b) }
+
+// newUUID returns a random (version 4) UUID string, the same shape coinbase pro uses for IDs.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// splitProductID splits a product ID such as "BTC-USD" into its base and quote currencies.
+func splitProductID(productID string) (base, quote string, err error) {
+	parts := strings.Split(productID, "-")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid product id: %q", productID)
+	}
+	return parts[0], parts[1], nil
+}
+
+// floorTo rounds r down to a multiple of increment. A zero increment leaves r as it is.
+func floorTo(r, increment *big.Rat) *big.Rat {
+	if increment.Sign() <= 0 {
+		return newRat().Set(r)
+	}
+	steps := quo(r, increment)
+	n := newRat().SetInt(new(big.Int).Quo(steps.Num(), steps.Denom()))
+	return mul(n, increment)
+}
+
+func minRat(a, b *big.Rat) *big.Rat {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,196 @@
+package godax
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientOption configures a Client, pass them to NewClient, NewSandboxClient or NewClientWithCredentials.
+type ClientOption func(*Client) error
+
+// WithDryRun puts the client in dry-run mode. Every request is built and signed as usual, and GET
+// requests go through, but mutating requests (PlaceOrder, the Cancel methods, StableCoinConversion,
+// ProfileTransfer, CreateReport and any other POST, PUT or DELETE) are logged to logger instead of
+// being sent, and a synthetic response is returned:
+//
+//   - PlaceOrder returns a pending Order with a random ID built from the order params
+//   - CancelOrderByID and CancelOrderByClientOID look the order up and return its ID, or the lookup's error
+//   - CancelAllOrders returns the IDs of the open orders it would have canceled
+//   - StableCoinConversion and CreateReport return a Conversion or a pending ReportStatus with a random ID
+//
+// Orders from a dry-run PlaceOrder are remembered until they are canceled, so GetOrderByID,
+// GetOrderByClientOID and the Cancel methods work on them like on real orders. They aren't listed
+// by ListOrders.
+//
+// This makes it safe to run a new bot against the live API with read-only effects. A nil logger logs
+// to stderr.
+func WithDryRun(logger Logger) ClientOption {
+	return func(c *Client) error {
+		if logger == nil {
+			logger = defaultLogger()
+		}
+		c.dryRun = logger
+		c.dryRunOrders = &dryRunOrders{byID: make(map[string]Order)}
+		return nil
+	}
+}
+
+// DryRun reports whether the client is in dry-run mode.
+func (c *Client) DryRun() bool {
+	return c.dryRun != nil
+}
+
+// dryRunOrders are the synthetic orders placed by a dry-run client.
+type dryRunOrders struct {
+	mu   sync.Mutex
+	byID map[string]Order
+}
+
+func (d *dryRunOrders) put(o Order) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byID[o.ID] = o
+}
+
+// get finds a synthetic order by ID, or by client OID for IDs of the form "client:<oid>".
+func (d *dryRunOrders) get(id string) (Order, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if strings.HasPrefix(id, "client:") {
+		oid := strings.TrimPrefix(id, "client:")
+		for _, o := range d.byID {
+			if oid != "" && o.ClientOID == oid {
+				return o, true
+			}
+		}
+		return Order{}, false
+	}
+	o, ok := d.byID[id]
+	return o, ok
+}
+
+// cancel drops the synthetic orders of a product, or of every product when productID is empty,
+// and returns their IDs.
+func (d *dryRunOrders) cancel(productID string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var ids []string
+	for id, o := range d.byID {
+		if productID == "" || o.ProductID == productID {
+			ids = append(ids, id)
+			delete(d.byID, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (d *dryRunOrders) remove(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.byID, id)
+}
+
+// dryRunGet answers a GET of a synthetic order.
+func (c *Client) dryRunGet(path string) (*http.Response, bool) {
+	if !strings.HasPrefix(path, "/orders/") {
+		return nil, false
+	}
+	o, ok := c.dryRunOrders.get(strings.TrimPrefix(path, "/orders/"))
+	if !ok {
+		return nil, false
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, false
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader(b))}, true
+}
+
+// dryRunDo logs a signed mutating request and answers it with a synthetic response.
+func (c *Client) dryRunDo(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+	c.dryRun.Printf("dry run: not sending signed %s %s (timestamp %s) %s",
+		req.Method, req.URL.RequestURI(), req.Header.Get("CB-ACCESS-TIMESTAMP"), body)
+
+	res, err := c.dryRunResponse(req.Method, req.URL.Path, req.URL.Query().Get(string(ProductIDParam)), body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(res)),
+	}, nil
+}
+
+func (c *Client) dryRunResponse(method, path, productID string, body []byte) ([]byte, error) {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	switch {
+	case method == http.MethodPost && path == "/orders":
+		o := Order{ID: newUUID(), CreatedAt: now, FillFees: "0", FilledSize: "0", ExecutedValue: "0", Status: "pending"}
+		if err := json.Unmarshal(body, &o.OrderParams); err != nil {
+			return nil, err
+		}
+		c.dryRunOrders.put(o)
+		return json.Marshal(o)
+
+	case method == http.MethodDelete && path == "/orders":
+		open, err := c.ListOrders(QueryParams{ProductIDParam: productID})
+		if err != nil {
+			return nil, err
+		}
+		ids := []string{}
+		for _, o := range open {
+			ids = append(ids, o.ID)
+		}
+		ids = append(ids, c.dryRunOrders.cancel(productID)...)
+		return json.Marshal(ids)
+
+	case method == http.MethodDelete && strings.HasPrefix(path, "/orders/"):
+		id := strings.TrimPrefix(path, "/orders/")
+		var o Order
+		var err error
+		if strings.HasPrefix(id, "client:") {
+			o, err = c.GetOrderByClientOID(strings.TrimPrefix(id, "client:"))
+		} else {
+			o, err = c.GetOrderByID(id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.dryRunOrders.remove(o.ID)
+		return []byte(o.ID), nil
+
+	case method == http.MethodPost && path == "/conversions":
+		conv := Conversion{ID: newUUID()}
+		if err := json.Unmarshal(body, &conv); err != nil {
+			return nil, err
+		}
+		return json.Marshal(conv)
+
+	case method == http.MethodPost && path == "/reports":
+		r := ReportStatus{ID: newUUID(), Status: "pending", CreatedAt: now}
+		if err := json.Unmarshal(body, &r.Params); err != nil {
+			return nil, err
+		}
+		r.Type = r.Params.Type
+		return json.Marshal(r)
+	}
+	return []byte("{}"), nil
+}
@@ -0,0 +1,154 @@
+package godax
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func dryRunClient(mock *MockClient, logs *bytes.Buffer) *Client {
+	c := &Client{
+		baseRestURL: baseRestURL,
+		baseWsURL:   baseWsURL,
+		key:         key,
+		secret:      secret,
+		passphrase:  passphrase,
+		httpClient:  mock,
+	}
+	if err := WithDryRun(log.New(logs, "", 0))(c); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestClient_DryRunPlaceOrder(t *testing.T) {
+	var logs bytes.Buffer
+	mock := MockResponse(`[{"id": "a", "currency": "USD", "balance": "10"}]`)
+	c := dryRunClient(mock, &logs)
+	if !c.DryRun() {
+		t.Fatal("DryRun() = false, want true")
+	}
+
+	order := OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Price: "100", Size: "1"}}
+	o, err := c.PlaceOrder(order)
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	if o.ID == "" || o.Status != "pending" || o.FilledSize != "0" || o.ProductID != "BTC-USD" || o.Price != "100" {
+		t.Errorf("PlaceOrder() = %+v, want a synthetic pending order", o)
+	}
+	if len(mock.Requests) != 0 {
+		t.Fatalf("made %d requests, want none", len(mock.Requests))
+	}
+	if l := logs.String(); !strings.Contains(l, "POST /orders") || !strings.Contains(l, `"product_id":"BTC-USD"`) {
+		t.Errorf("log = %q, want the order logged", l)
+	}
+
+	if err := c.ProfileTransfer(TransferParams{From: "a", To: "b", Currency: "USD", Amount: "1"}); err != nil {
+		t.Fatalf("ProfileTransfer() error = %v", err)
+	}
+	conv, err := c.StableCoinConversion("USD", "USDC", "10")
+	if err != nil || conv.ID == "" || conv.From != "USD" || conv.Amount != "10" {
+		t.Errorf("StableCoinConversion() = %+v, %v, want a synthetic conversion", conv, err)
+	}
+	r, err := c.CreateReport(ReportParams{Type: "fills", ProductID: "BTC-USD"})
+	if err != nil || r.ID == "" || r.Status != "pending" || r.Type != "fills" {
+		t.Errorf("CreateReport() = %+v, %v, want a synthetic pending report", r, err)
+	}
+	if len(mock.Requests) != 0 {
+		t.Fatalf("made %d requests, want none", len(mock.Requests))
+	}
+
+	// reads still go through
+	if _, err := c.ListAccounts(); err != nil {
+		t.Fatalf("ListAccounts() error = %v", err)
+	}
+	if len(mock.Requests) != 1 || mock.Requests[0].Method != http.MethodGet {
+		t.Errorf("requests = %+v, want the GET sent", mock.Requests)
+	}
+	validateHeaders(t, c)
+}
+
+func TestClient_DryRunCancel(t *testing.T) {
+	var logs bytes.Buffer
+	mock := MockResponses(
+		`{"id": "order-1", "status": "open"}`,
+		`[{"id": "order-1"}, {"id": "order-2"}]`,
+	)
+	c := dryRunClient(mock, &logs)
+
+	if err := c.CancelOrderByID("order-1", QueryParams{}); err != nil {
+		t.Fatalf("CancelOrderByID() error = %v", err)
+	}
+	ids, err := c.CancelAllOrders(QueryParams{ProductIDParam: "BTC-USD"})
+	if err != nil {
+		t.Fatalf("CancelAllOrders() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "order-1" || ids[1] != "order-2" {
+		t.Errorf("CancelAllOrders() = %v, want the open orders", ids)
+	}
+
+	if len(mock.Requests) != 2 {
+		t.Fatalf("made %d requests, want the two lookups", len(mock.Requests))
+	}
+	for _, req := range mock.Requests {
+		if req.Method != http.MethodGet {
+			t.Errorf("sent %s %s, want only GETs", req.Method, req.URL)
+		}
+	}
+	if got := mock.Requests[1].URL.Query().Get("product_id"); got != "BTC-USD" {
+		t.Errorf("open orders looked up for %q, want BTC-USD", got)
+	}
+	if l := logs.String(); !strings.Contains(l, "DELETE /orders/order-1") || !strings.Contains(l, "DELETE /orders?product_id=BTC-USD") {
+		t.Errorf("log = %q, want both cancels logged", l)
+	}
+
+	notFound := dryRunClient(&MockClient{StatusCode: 404, Response: []byte(`{"message": "NotFound"}`)}, &logs)
+	if err := notFound.CancelOrderByID("gone", QueryParams{}); !isNotFound(err) {
+		t.Errorf("CancelOrderByID() error = %v, want NotFound", err)
+	}
+}
+
+func TestClient_DryRunPlaceThenCancel(t *testing.T) {
+	var logs bytes.Buffer
+	mock := &MockClient{StatusCode: 404, Response: []byte(`{"message": "NotFound"}`)}
+	c := dryRunClient(mock, &logs)
+
+	order := OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Price: "100", Size: "1", ClientOID: "my-oid"}}
+	placed, err := c.PlaceOrder(order)
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	got, err := c.GetOrderByID(placed.ID)
+	if err != nil || got.ID != placed.ID || got.Price != "100" {
+		t.Errorf("GetOrderByID() = %+v, %v, want the synthetic order", got, err)
+	}
+	if got, err := c.GetOrderByClientOID("my-oid"); err != nil || got.ID != placed.ID {
+		t.Errorf("GetOrderByClientOID() = %+v, %v, want the synthetic order", got, err)
+	}
+	if err := c.CancelOrderByID(placed.ID, QueryParams{}); err != nil {
+		t.Fatalf("CancelOrderByID() error = %v", err)
+	}
+	if len(mock.Requests) != 0 {
+		t.Errorf("made %d requests, want the synthetic order served locally", len(mock.Requests))
+	}
+
+	// once canceled it is gone, like a real order canceled before it filled
+	if _, err := c.GetOrderByID(placed.ID); !isNotFound(err) {
+		t.Errorf("GetOrderByID() after cancel error = %v, want NotFound", err)
+	}
+
+	// cancel all picks up the synthetic orders of the product
+	eth, _ := c.PlaceOrder(OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "ETH-USD", Price: "10", Size: "1"}})
+	btc, _ := c.PlaceOrder(order)
+	c.httpClient = MockResponse(`[]`)
+	ids, err := c.CancelAllOrders(QueryParams{ProductIDParam: "BTC-USD"})
+	if err != nil || len(ids) != 1 || ids[0] != btc.ID {
+		t.Errorf("CancelAllOrders() = %v, %v, want [%s]", ids, err, btc.ID)
+	}
+	if _, err := c.GetOrderByID(eth.ID); err != nil {
+		t.Errorf("GetOrderByID() of the ETH-USD order error = %v, want it still there", err)
+	}
+}
@@ -35,6 +35,11 @@ func (c *Client) loadEnv(sandbox bool) error {
 		return errMissingEnv(coinbaseProPassphrase)
 	}
 
+	c.setCredentials(Credentials{Key: k, Secret: s, Passphrase: p}, sandbox)
+	return nil
+}
+
+func (c *Client) setCredentials(creds Credentials, sandbox bool) {
 	if sandbox {
 		c.baseRestURL = sandboxREST
 		c.baseWsURL = sandboxWS
@@ -43,11 +48,9 @@ func (c *Client) loadEnv(sandbox bool) error {
 		c.baseWsURL = liveWS
 	}
 
-	c.key = k
-	c.secret = s
-	c.passphrase = p
-
-	return nil
+	c.key = creds.Key
+	c.secret = creds.Secret
+	c.passphrase = creds.Passphrase
 }
 
 func errMissingEnv(envVar string) error {
@@ -0,0 +1,489 @@
+package godax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ErrInvalidParentOrder is returned when a ParentOrder is missing a product, side, size, window or slices.
+var ErrInvalidParentOrder = errors.New("please provide a product, side, size, window and number of slices for the parent order")
+
+// defaultPollInterval is how often a running Execution refreshes its fills between slices.
+const defaultPollInterval = 5 * time.Second
+
+// ParentOrder describes a large order to be worked over time as a series of smaller child orders.
+type ParentOrder struct {
+	// ProductID is the product to trade, for example "BTC-USD".
+	ProductID string
+
+	// Side is either buy or sell.
+	Side string
+
+	// Size is the total amount of base currency to buy or sell.
+	Size string
+
+	// Start is when the first slice is sent. A zero Start begins at the first Step.
+	Start time.Time
+
+	// Window is how long the order is worked for. Anything unfilled at the end is canceled.
+	Window time.Duration
+
+	// Slices is the number of child orders the parent is split into.
+	Slices int
+
+	// OrderType is the type of the child orders, "limit" or "market" (default is "limit"). Limit
+	// children are priced at the touch (best bid for buys, best ask for sells) and anything left of
+	// them is canceled and rolled into the next slice.
+	OrderType string
+
+	// PollInterval is how often Run refreshes fills between slices. Defaults to 5 seconds.
+	PollInterval time.Duration
+}
+
+// ExecutionProgress is a snapshot of how an Execution is doing.
+type ExecutionProgress struct {
+	FilledSize    string
+	RemainingSize string
+	AveragePrice  string
+	ArrivalPrice  string
+	Fees          string
+
+	// SlippageBps is the average fill price versus the arrival price in basis points. Positive
+	// values are worse than arrival (paying up on a buy, selling lower on a sell).
+	SlippageBps string
+
+	ChildOrders int
+	Done        bool
+}
+
+// Execution works a ParentOrder over its window by sending child orders through PlaceOrder on
+// a schedule, following their fills through ListFills (and the feed when HandleMatch is used),
+// and canceling leftovers with CancelOrderByID. Create one with NewTWAP or NewVWAP.
+type Execution struct {
+	mu       sync.Mutex
+	orders   OrdersAPI
+	market   MarketDataAPI
+	parent   ParentOrder
+	weights  []float64
+	size     *big.Rat
+	baseInc  *big.Rat
+	quoteInc *big.Rat
+	minSize  *big.Rat
+	arrival  *big.Rat
+	sent     int
+	children []*childOrder
+	fills    map[fillKey]Fill
+	done     bool
+	now      func() time.Time
+}
+
+type childOrder struct {
+	id      string
+	resting bool
+	settled bool
+}
+
+// NewTWAP creates an Execution that splits the parent into equal slices spread evenly across its window.
+func NewTWAP(orders OrdersAPI, market MarketDataAPI, parent ParentOrder) (*Execution, error) {
+	weights := make([]float64, parent.Slices)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return newExecution(orders, market, parent, weights)
+}
+
+// NewVWAP creates an Execution whose slices are sized in proportion to weights, one weight per
+// slice. Use VolumeWeights to build the weights from the product's historic volume profile.
+func NewVWAP(orders OrdersAPI, market MarketDataAPI, parent ParentOrder, weights []float64) (*Execution, error) {
+	if len(weights) != parent.Slices {
+		return nil, ErrInvalidParentOrder
+	}
+	return newExecution(orders, market, parent, weights)
+}
+
+// VolumeWeights builds VWAP slice weights from a candle history. Each slice of the window starting
+// at start is weighted by the volume traded at the same time of day across the history, so a
+// history of the last few days gives the product's typical intraday volume profile.
+func VolumeWeights(history CandleSeries, start time.Time, window time.Duration, slices int) []float64 {
+	weights := make([]float64, slices)
+	if slices <= 0 {
+		return weights
+	}
+	const day = int64(24 * time.Hour / time.Second)
+	sliceLen := int64(window/time.Second) / int64(slices)
+	from := start.Unix() % day
+	for _, c := range history.Candles {
+		if c.Missing || sliceLen <= 0 {
+			continue
+		}
+		offset := (int64(c.Time)%day - from + day) % day
+		if i := offset / sliceLen; i < int64(slices) {
+			weights[i] += c.Volume
+		}
+	}
+	return weights
+}
+
+func newExecution(orders OrdersAPI, market MarketDataAPI, parent ParentOrder, weights []float64) (*Execution, error) {
+	if parent.ProductID == "" || (parent.Side != "buy" && parent.Side != "sell") || parent.Window <= 0 || parent.Slices <= 0 {
+		return nil, ErrInvalidParentOrder
+	}
+	size, err := parseDecimal(parent.Size)
+	if err != nil {
+		return nil, err
+	}
+	if size.Sign() <= 0 {
+		return nil, ErrInvalidParentOrder
+	}
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 {
+			return nil, ErrInvalidParentOrder
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, ErrInvalidParentOrder
+	}
+	if parent.OrderType == "" {
+		parent.OrderType = "limit"
+	}
+	if parent.PollInterval <= 0 {
+		parent.PollInterval = defaultPollInterval
+	}
+
+	product, err := market.GetProductByID(parent.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	e := &Execution{
+		orders:  orders,
+		market:  market,
+		parent:  parent,
+		weights: weights,
+		size:    size,
+		fills:   make(map[fillKey]Fill),
+		now:     time.Now,
+	}
+	if e.baseInc, err = parseDecimal(product.BaseIncrement); err != nil {
+		return nil, err
+	}
+	if e.quoteInc, err = parseDecimal(product.QuoteIncrement); err != nil {
+		return nil, err
+	}
+	if e.minSize, err = parseDecimal(product.BaseMinSize); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Run works the order until its window has ended or ctx is done, stepping at every slice and
+// every PollInterval in between. If ctx ends first or a step fails, resting child orders are canceled.
+func (e *Execution) Run(ctx context.Context) error {
+	for {
+		if err := e.Step(e.now()); err != nil {
+			if stopErr := e.Stop(); stopErr != nil {
+				return fmt.Errorf("%w (canceling resting child orders failed too: %v)", err, stopErr)
+			}
+			return err
+		}
+		if e.Done() {
+			return nil
+		}
+		wait := e.nextWake(e.now())
+		select {
+		case <-ctx.Done():
+			if err := e.Stop(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Step advances the execution to now: it cancels resting children when a new slice is due, refreshes
+// fills, and sends a child order sized to catch the filled amount up with the schedule. Once the window
+// has ended it cancels whatever is left and marks the execution done. Run calls Step for you.
+func (e *Execution) Step(now time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.done {
+		return nil
+	}
+	if e.parent.Start.IsZero() {
+		e.parent.Start = now
+	}
+	if now.Before(e.parent.Start) {
+		return nil
+	}
+	if e.arrival == nil {
+		arrival, err := e.touch(true)
+		if err != nil {
+			return err
+		}
+		e.arrival = arrival
+	}
+
+	due := e.dueSlices(now)
+	ended := !now.Before(e.parent.Start.Add(e.parent.Window))
+	if due > e.sent || ended {
+		if err := e.cancelResting(); err != nil {
+			return err
+		}
+	}
+	if err := e.refreshFills(); err != nil {
+		return err
+	}
+	if ended {
+		e.done = true
+		return nil
+	}
+	if due <= e.sent {
+		return nil
+	}
+	e.sent = due
+
+	filled, _, _ := e.totals()
+	size := floorTo(sub(e.target(due), filled), e.baseInc)
+	if size.Sign() <= 0 || size.Cmp(e.minSize) < 0 {
+		return nil
+	}
+	if err := e.placeChild(size); err != nil {
+		return err
+	}
+	return e.refreshFills()
+}
+
+// Stop cancels any resting child orders and marks the execution done.
+func (e *Execution) Stop() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.done = true
+	if err := e.cancelResting(); err != nil {
+		return err
+	}
+	return e.refreshFills()
+}
+
+// HandleMatch records a fill from a feed match message if it belongs to one of the execution's
+// child orders. Fees aren't on match messages, so they are picked up on the next ListFills refresh.
+func (e *Execution) HandleMatch(msg FeedMessage) {
+	if msg.Type != FeedMatch {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, c := range e.children {
+		if c.id != msg.MakerOrderID && c.id != msg.TakerOrderID {
+			continue
+		}
+		key := fillKey{productID: msg.ProductID, tradeID: msg.TradeID, orderID: c.id}
+		if _, ok := e.fills[key]; !ok {
+			e.fills[key] = Fill{
+				TradeID:   msg.TradeID,
+				ProductID: msg.ProductID,
+				Price:     msg.Price,
+				Size:      msg.Size,
+				OrderID:   c.id,
+				CreatedAt: msg.Time,
+				Side:      e.parent.Side,
+			}
+		}
+		return
+	}
+}
+
+// Done reports whether the execution has finished.
+func (e *Execution) Done() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.done
+}
+
+// Progress reports the filled and remaining size, the average fill price and its slippage against
+// the arrival price (the mid price when the execution started), and the fees paid so far.
+func (e *Execution) Progress() ExecutionProgress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	filled, value, fees := e.totals()
+	p := ExecutionProgress{
+		FilledSize:    formatDecimal(filled),
+		RemainingSize: formatDecimal(sub(e.size, filled)),
+		AveragePrice:  "0",
+		ArrivalPrice:  "0",
+		Fees:          formatDecimal(fees),
+		SlippageBps:   "0",
+		ChildOrders:   len(e.children),
+		Done:          e.done,
+	}
+	if e.arrival != nil {
+		p.ArrivalPrice = formatDecimal(e.arrival)
+	}
+	if filled.Sign() > 0 {
+		avg := quo(value, filled)
+		p.AveragePrice = formatDecimal(avg)
+		if e.arrival != nil && e.arrival.Sign() > 0 {
+			diff := sub(avg, e.arrival)
+			if e.parent.Side == "sell" {
+				diff.Neg(diff)
+			}
+			p.SlippageBps = formatDecimal(mul(quo(diff, e.arrival), big.NewRat(10000, 1)))
+		}
+	}
+	return p
+}
+
+// dueSlices returns how many slices should have been sent by now.
+func (e *Execution) dueSlices(now time.Time) int {
+	elapsed := now.Sub(e.parent.Start)
+	sliceLen := e.parent.Window / time.Duration(e.parent.Slices)
+	if sliceLen <= 0 {
+		return e.parent.Slices
+	}
+	due := int(elapsed/sliceLen) + 1
+	if due > e.parent.Slices {
+		due = e.parent.Slices
+	}
+	return due
+}
+
+// target returns the cumulative size the schedule calls for once n slices have been sent.
+func (e *Execution) target(n int) *big.Rat {
+	if n >= len(e.weights) {
+		return e.size
+	}
+	var cum, total float64
+	for i, w := range e.weights {
+		if i < n {
+			cum += w
+		}
+		total += w
+	}
+	frac := new(big.Rat).SetFloat64(cum / total)
+	return minRat(mul(e.size, frac), e.size)
+}
+
+func (e *Execution) nextWake(now time.Time) time.Duration {
+	wait := e.parent.PollInterval
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sliceLen := e.parent.Window / time.Duration(e.parent.Slices)
+	next := e.parent.Start.Add(time.Duration(e.sent) * sliceLen)
+	if e.sent >= e.parent.Slices {
+		next = e.parent.Start.Add(e.parent.Window)
+	}
+	if d := next.Sub(now); d > 0 && d < wait {
+		wait = d
+	}
+	return wait
+}
+
+// touch returns the mid price when mid is true, otherwise the passive price for a child order.
+func (e *Execution) touch(mid bool) (*big.Rat, error) {
+	t, err := e.market.GetProductTicker(e.parent.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := parseTicker(t)
+	if err != nil {
+		return nil, err
+	}
+	if mid {
+		return quo(add(pt.bid, pt.ask), big.NewRat(2, 1)), nil
+	}
+	if e.parent.Side == "buy" {
+		return pt.bid, nil
+	}
+	return pt.ask, nil
+}
+
+func (e *Execution) placeChild(size *big.Rat) error {
+	params := OrderParams{CommonOrderParams: CommonOrderParams{
+		Side:      e.parent.Side,
+		ProductID: e.parent.ProductID,
+		ClientOID: newUUID(),
+		Type:      e.parent.OrderType,
+		Size:      formatDecimal(size),
+	}}
+	if e.parent.OrderType == "limit" {
+		price, err := e.touch(false)
+		if err != nil {
+			return err
+		}
+		params.Price = formatDecimal(floorTo(price, e.quoteInc))
+	}
+
+	o, err := e.orders.PlaceOrder(params)
+	if err != nil {
+		return err
+	}
+	e.children = append(e.children, &childOrder{id: o.ID, resting: o.Status != orderStatusDone})
+	return nil
+}
+
+func (e *Execution) cancelResting() error {
+	for _, c := range e.children {
+		if !c.resting {
+			continue
+		}
+		if err := e.orders.CancelOrderByID(c.id, QueryParams{ProductIDParam: e.parent.ProductID}); err != nil {
+			// the order may have filled in full since we last looked, which is fine
+			if o, getErr := e.orders.GetOrderByID(c.id); getErr != nil || o.Status != orderStatusDone {
+				return err
+			}
+		}
+		c.resting = false
+	}
+	return nil
+}
+
+// refreshFills fetches the fills of every child that is resting or hasn't been looked at since it stopped resting.
+func (e *Execution) refreshFills() error {
+	for _, c := range e.children {
+		if c.settled {
+			continue
+		}
+		fills, err := e.orders.ListFills(QueryParams{OrderIDParam: c.id})
+		if err != nil {
+			return err
+		}
+		for _, f := range fills {
+			e.fills[fillKey{productID: f.ProductID, tradeID: f.TradeID, orderID: f.OrderID}] = f
+		}
+		c.settled = !c.resting
+	}
+	return nil
+}
+
+func (e *Execution) totals() (filled, value, fees *big.Rat) {
+	filled, value, fees = newRat(), newRat(), newRat()
+	for _, f := range e.fills {
+		size, err := parseDecimal(f.Size)
+		if err != nil {
+			continue
+		}
+		price, err := parseDecimal(f.Price)
+		if err != nil {
+			continue
+		}
+		fee, err := parseDecimal(f.Fee)
+		if err != nil {
+			continue
+		}
+		filled.Add(filled, size)
+		value.Add(value, mul(size, price))
+		fees.Add(fees, fee)
+	}
+	return filled, value, fees
+}
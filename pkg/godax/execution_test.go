@@ -0,0 +1,243 @@
+package godax
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func executionMarket(bid, ask *string) *FakeMarketDataAPI {
+	return &FakeMarketDataAPI{
+		GetProductByIDFunc: func(productID string) (Product, error) {
+			return Product{ID: productID, BaseIncrement: "0.001", QuoteIncrement: "0.01", BaseMinSize: "0.001"}, nil
+		},
+		GetProductTickerFunc: func(productID string) (Ticker, error) {
+			return Ticker{Price: *bid, Bid: *bid, Ask: *ask}, nil
+		},
+	}
+}
+
+func TestTWAP_MarketChildren(t *testing.T) {
+	bid, ask := "99.5", "100.5"
+	market := executionMarket(&bid, &ask)
+	paper := newTestPaperClient(t, market, map[string]string{"USD": "100000"})
+	start := time.Unix(1600000000, 0)
+
+	e, err := NewTWAP(paper, market, ParentOrder{
+		ProductID: "BTC-USD",
+		Side:      "buy",
+		Size:      "1",
+		Start:     start,
+		Window:    4 * time.Minute,
+		Slices:    3,
+		OrderType: "market",
+	})
+	if err != nil {
+		t.Fatalf("NewTWAP() error = %v", err)
+	}
+
+	steps := []time.Duration{0, 30 * time.Second, 80 * time.Second, 160 * time.Second, 4 * time.Minute}
+	for _, d := range steps {
+		if err := e.Step(start.Add(d)); err != nil {
+			t.Fatalf("Step(%v) error = %v", d, err)
+		}
+	}
+
+	fills, _ := paper.ListFills(QueryParams{ProductIDParam: "BTC-USD"})
+	var sizes []string
+	for i := len(fills) - 1; i >= 0; i-- {
+		sizes = append(sizes, fills[i].Size)
+	}
+	if !reflect.DeepEqual(sizes, []string{"0.333", "0.333", "0.334"}) {
+		t.Errorf("child fill sizes = %v, want 0.333, 0.333 and 0.334", sizes)
+	}
+
+	got := e.Progress()
+	want := ExecutionProgress{
+		FilledSize:    "1",
+		RemainingSize: "0",
+		AveragePrice:  "100.5",
+		ArrivalPrice:  "100",
+		Fees:          "0.201",
+		SlippageBps:   "50",
+		ChildOrders:   3,
+		Done:          true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Progress() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTWAP_LimitChildrenRollOver(t *testing.T) {
+	bid, ask := "99", "101"
+	market := executionMarket(&bid, &ask)
+	paper := newTestPaperClient(t, market, map[string]string{"BTC": "2"})
+	start := time.Unix(1600000000, 0)
+
+	e, err := NewTWAP(paper, market, ParentOrder{
+		ProductID: "BTC-USD",
+		Side:      "sell",
+		Size:      "2",
+		Start:     start,
+		Window:    2 * time.Minute,
+		Slices:    2,
+	})
+	if err != nil {
+		t.Fatalf("NewTWAP() error = %v", err)
+	}
+
+	if err := e.Step(start); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	open, _ := paper.ListOrders(QueryParams{})
+	if len(open) != 1 || open[0].Price != "101" || open[0].Size != "1" {
+		t.Fatalf("open orders = %+v, want one sell of 1 at the ask", open)
+	}
+
+	// nothing trades, so the next slice cancels the first child and sends everything due so far
+	if err := e.Step(start.Add(time.Minute)); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	open, _ = paper.ListOrders(QueryParams{})
+	if len(open) != 1 || open[0].Size != "2" {
+		t.Fatalf("open orders = %+v, want one sell of 2", open)
+	}
+
+	// a trade above the ask fills the resting child, the match from another order is ignored
+	paper.ApplyPrice("BTC-USD", "101.5")
+	e.HandleMatch(FeedMessage{Type: FeedMatch, TradeID: 999, MakerOrderID: "someone-else", Price: "1", Size: "1"})
+
+	if err := e.Step(start.Add(2 * time.Minute)); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	got := e.Progress()
+	if !got.Done || got.FilledSize != "2" || got.AveragePrice != "101" || got.SlippageBps != "-100" || got.ChildOrders != 2 {
+		t.Errorf("Progress() = %+v, want 2 filled at 101, 100bps better than the 100 arrival", got)
+	}
+}
+
+func TestTWAP_StopCancelsLeftovers(t *testing.T) {
+	bid, ask := "99", "101"
+	market := executionMarket(&bid, &ask)
+	paper := newTestPaperClient(t, market, map[string]string{"USD": "1000"})
+
+	e, err := NewTWAP(paper, market, ParentOrder{ProductID: "BTC-USD", Side: "buy", Size: "1", Window: time.Hour, Slices: 2})
+	if err != nil {
+		t.Fatalf("NewTWAP() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := e.Run(ctx); err != context.Canceled {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if open, _ := paper.ListOrders(QueryParams{}); len(open) != 0 {
+		t.Errorf("open orders = %+v, want the child canceled", open)
+	}
+	if !e.Done() || e.Progress().RemainingSize != "1" {
+		t.Errorf("Progress() = %+v, want done with 1 remaining", e.Progress())
+	}
+}
+
+// flakyFills fails ListFills once when armed.
+type flakyFills struct {
+	*PaperClient
+	fail bool
+}
+
+var errFlakyFills = errors.New("fills unavailable")
+
+func (f *flakyFills) ListFills(qp QueryParams) ([]Fill, error) {
+	if f.fail {
+		f.fail = false
+		return nil, errFlakyFills
+	}
+	return f.PaperClient.ListFills(qp)
+}
+
+func TestTWAP_RunErrorCancelsLeftovers(t *testing.T) {
+	bid, ask := "99", "101"
+	market := executionMarket(&bid, &ask)
+	paper := &flakyFills{PaperClient: newTestPaperClient(t, market, map[string]string{"USD": "1000"})}
+	start := time.Unix(1600000000, 0)
+
+	e, err := NewTWAP(paper, market, ParentOrder{ProductID: "BTC-USD", Side: "buy", Size: "1", Start: start, Window: time.Hour, Slices: 2})
+	if err != nil {
+		t.Fatalf("NewTWAP() error = %v", err)
+	}
+	if err := e.Step(start); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	if open, _ := paper.ListOrders(QueryParams{}); len(open) != 1 {
+		t.Fatalf("open orders = %+v, want a resting child", open)
+	}
+
+	paper.fail = true
+	e.now = func() time.Time { return start.Add(time.Minute) }
+	if err := e.Run(context.Background()); !errors.Is(err, errFlakyFills) {
+		t.Fatalf("Run() error = %v, want the fills error", err)
+	}
+	if open, _ := paper.ListOrders(QueryParams{}); len(open) != 0 {
+		t.Errorf("open orders = %+v, want the child canceled", open)
+	}
+}
+
+func TestExecution_FillsKeyedByProduct(t *testing.T) {
+	bid, ask := "99", "101"
+	market := executionMarket(&bid, &ask)
+	paper := newTestPaperClient(t, market, map[string]string{"USD": "1000"})
+	start := time.Unix(1600000000, 0)
+
+	e, err := NewTWAP(paper, market, ParentOrder{ProductID: "BTC-USD", Side: "buy", Size: "1", Start: start, Window: time.Hour, Slices: 2})
+	if err != nil {
+		t.Fatalf("NewTWAP() error = %v", err)
+	}
+	if err := e.Step(start); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	child := e.children[0].id
+
+	// the same trade ID on two products is two different trades
+	e.HandleMatch(FeedMessage{Type: FeedMatch, ProductID: "BTC-USD", TradeID: 7, MakerOrderID: child, Price: "99", Size: "0.1"})
+	e.HandleMatch(FeedMessage{Type: FeedMatch, ProductID: "BTC-EUR", TradeID: 7, MakerOrderID: child, Price: "99", Size: "0.2"})
+	if got := e.Progress().FilledSize; got != "0.3" {
+		t.Errorf("FilledSize = %s, want both fills counted", got)
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	start := time.Date(2020, 9, 14, 14, 0, 0, 0, time.UTC)
+	yesterday := start.Add(-24 * time.Hour).Unix()
+	history := NewCandleSeries([]HistoricRate{
+		{Time: float64(yesterday), Volume: 10},
+		{Time: float64(yesterday + 1800), Volume: 30},
+		{Time: float64(yesterday + 3600), Volume: 500}, // outside the window
+	}, OneMinute)
+
+	weights := VolumeWeights(history, start, time.Hour, 2)
+	if !reflect.DeepEqual(weights, []float64{10, 30}) {
+		t.Fatalf("VolumeWeights() = %v, want [10 30]", weights)
+	}
+
+	bid, ask := "100", "100"
+	market := executionMarket(&bid, &ask)
+	paper := newTestPaperClient(t, market, map[string]string{"USD": "1000"})
+	e, err := NewVWAP(paper, market, ParentOrder{
+		ProductID: "BTC-USD", Side: "buy", Size: "4", Start: start, Window: time.Hour, Slices: 2, OrderType: "market",
+	}, weights)
+	if err != nil {
+		t.Fatalf("NewVWAP() error = %v", err)
+	}
+	if err := e.Step(start); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	if got := e.Progress().FilledSize; got != "1" {
+		t.Errorf("filled after the first slice = %s, want 1", got)
+	}
+
+	if _, err := NewVWAP(paper, market, ParentOrder{ProductID: "BTC-USD", Side: "buy", Size: "1", Window: time.Hour, Slices: 2}, []float64{1}); err != ErrInvalidParentOrder {
+		t.Errorf("NewVWAP() with mismatched weights error = %v, want ErrInvalidParentOrder", err)
+	}
+}
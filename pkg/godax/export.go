@@ -0,0 +1,385 @@
+package godax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportRecord is one ledger entry or fill in the shape the exporters work with. Build them with
+// ActivityRecords and FillRecords.
+type ExportRecord struct {
+	// ID is the ledger entry ID, or product:trade ID for a fill.
+	ID   string
+	Time string
+
+	// Type is the ledger entry type ("match", "fee", "transfer", ...) or "trade" for a fill.
+	Type string
+
+	// Currency and Amount are the signed change to a balance, Balance the balance after it. For a fill
+	// they are the change in the base currency and Balance is empty.
+	Currency string
+	Amount   string
+	Balance  string
+
+	// Sent and Received split the change into what left and what arrived. A fill sends one currency and
+	// receives the other, a ledger entry only has one of them.
+	SentAmount       string
+	SentCurrency     string
+	ReceivedAmount   string
+	ReceivedCurrency string
+
+	FeeAmount   string
+	FeeCurrency string
+
+	// Side, Price and Size are set for fills.
+	Side  string
+	Price string
+	Size  string
+
+	// OrderID, TradeID and ProductID come from a ledger entry's ActivityDetail or from the fill.
+	OrderID   string
+	TradeID   string
+	ProductID string
+}
+
+// ActivityRecords turns GetAccountHistory entries of an account in currency into export records.
+func ActivityRecords(currency string, history []AccountActivity) ([]ExportRecord, error) {
+	records := make([]ExportRecord, 0, len(history))
+	for _, a := range history {
+		amount, err := parseDecimal(a.Amount)
+		if err != nil {
+			return nil, err
+		}
+		r := ExportRecord{
+			ID:        a.ID,
+			Time:      a.CreatedAt,
+			Type:      a.Type,
+			Currency:  currency,
+			Amount:    a.Amount,
+			Balance:   a.Balance,
+			OrderID:   a.Details.OrderID,
+			TradeID:   a.Details.TradeID,
+			ProductID: a.Details.ProductID,
+		}
+		switch amount.Sign() {
+		case 1:
+			r.ReceivedAmount, r.ReceivedCurrency = formatDecimal(amount), currency
+		case -1:
+			r.SentAmount, r.SentCurrency = formatDecimal(newRat().Neg(amount)), currency
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// FillRecords turns ListFills fills into export records.
+func FillRecords(fills []Fill) ([]ExportRecord, error) {
+	records := make([]ExportRecord, 0, len(fills))
+	for _, f := range fills {
+		base, quote, err := splitProductID(f.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		size, err := parseDecimal(f.Size)
+		if err != nil {
+			return nil, err
+		}
+		price, err := parseDecimal(f.Price)
+		if err != nil {
+			return nil, err
+		}
+		value := formatDecimal(mul(size, price))
+		tradeID := strconv.Itoa(f.TradeID)
+		r := ExportRecord{
+			ID:          f.ProductID + ":" + tradeID,
+			Time:        f.CreatedAt,
+			Type:        "trade",
+			Currency:    base,
+			Amount:      formatDecimal(size),
+			FeeAmount:   f.Fee,
+			FeeCurrency: quote,
+			Side:        f.Side,
+			Price:       f.Price,
+			Size:        f.Size,
+			OrderID:     f.OrderID,
+			TradeID:     tradeID,
+			ProductID:   f.ProductID,
+		}
+		if f.Side == "buy" {
+			r.SentAmount, r.SentCurrency = value, quote
+			r.ReceivedAmount, r.ReceivedCurrency = formatDecimal(size), base
+		} else {
+			r.Amount = formatDecimal(newRat().Neg(size))
+			r.SentAmount, r.SentCurrency = formatDecimal(size), base
+			r.ReceivedAmount, r.ReceivedCurrency = value, quote
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// ExportField is one column of an export, Value picks or formats it from a record.
+type ExportField struct {
+	Name  string
+	Value func(ExportRecord) string
+}
+
+// ExportMapping is the columns of a CSV or JSON Lines export, in order. Build your own, or copy one of
+// the predefined mappings and add, drop or rename fields.
+type ExportMapping []ExportField
+
+// DefaultExportMapping exports every field of a record.
+var DefaultExportMapping = ExportMapping{
+	{"id", func(r ExportRecord) string { return r.ID }},
+	{"time", func(r ExportRecord) string { return r.Time }},
+	{"type", func(r ExportRecord) string { return r.Type }},
+	{"currency", func(r ExportRecord) string { return r.Currency }},
+	{"amount", func(r ExportRecord) string { return r.Amount }},
+	{"balance", func(r ExportRecord) string { return r.Balance }},
+	{"sent_amount", func(r ExportRecord) string { return r.SentAmount }},
+	{"sent_currency", func(r ExportRecord) string { return r.SentCurrency }},
+	{"received_amount", func(r ExportRecord) string { return r.ReceivedAmount }},
+	{"received_currency", func(r ExportRecord) string { return r.ReceivedCurrency }},
+	{"fee_amount", func(r ExportRecord) string { return r.FeeAmount }},
+	{"fee_currency", func(r ExportRecord) string { return r.FeeCurrency }},
+	{"side", func(r ExportRecord) string { return r.Side }},
+	{"price", func(r ExportRecord) string { return r.Price }},
+	{"size", func(r ExportRecord) string { return r.Size }},
+	{"order_id", func(r ExportRecord) string { return r.OrderID }},
+	{"trade_id", func(r ExportRecord) string { return r.TradeID }},
+	{"product_id", func(r ExportRecord) string { return r.ProductID }},
+}
+
+// KoinlyMapping is Koinly's universal CSV import format.
+var KoinlyMapping = ExportMapping{
+	{"Date", exportTime("2006-01-02 15:04:05 UTC")},
+	{"Sent Amount", func(r ExportRecord) string { return r.SentAmount }},
+	{"Sent Currency", func(r ExportRecord) string { return r.SentCurrency }},
+	{"Received Amount", func(r ExportRecord) string { return r.ReceivedAmount }},
+	{"Received Currency", func(r ExportRecord) string { return r.ReceivedCurrency }},
+	{"Fee Amount", func(r ExportRecord) string { return r.FeeAmount }},
+	{"Fee Currency", func(r ExportRecord) string { return r.FeeCurrency }},
+	{"Net Worth Amount", func(r ExportRecord) string { return "" }},
+	{"Net Worth Currency", func(r ExportRecord) string { return "" }},
+	{"Label", func(r ExportRecord) string { return "" }},
+	{"Description", exportDescription},
+	{"TxHash", func(r ExportRecord) string { return r.ID }},
+}
+
+// CoinTrackerMapping is CoinTracker's CSV import format.
+var CoinTrackerMapping = ExportMapping{
+	{"Date", exportTime("01/02/2006 15:04:05")},
+	{"Received Quantity", func(r ExportRecord) string { return r.ReceivedAmount }},
+	{"Received Currency", func(r ExportRecord) string { return r.ReceivedCurrency }},
+	{"Sent Quantity", func(r ExportRecord) string { return r.SentAmount }},
+	{"Sent Currency", func(r ExportRecord) string { return r.SentCurrency }},
+	{"Fee Amount", func(r ExportRecord) string { return r.FeeAmount }},
+	{"Fee Currency", func(r ExportRecord) string { return r.FeeCurrency }},
+	{"Tag", func(r ExportRecord) string { return "" }},
+}
+
+// exportTime formats a record's time in UTC with layout, leaving unparseable times as they are.
+func exportTime(layout string) func(ExportRecord) string {
+	return func(r ExportRecord) string {
+		t, err := time.Parse(time.RFC3339Nano, r.Time)
+		if err != nil {
+			return r.Time
+		}
+		return t.UTC().Format(layout)
+	}
+}
+
+// exportDescription carries a record's type and IDs into a free text column.
+func exportDescription(r ExportRecord) string {
+	d := r.Type
+	if r.ProductID != "" {
+		d += " " + r.ProductID
+	}
+	if r.OrderID != "" {
+		d += " order " + r.OrderID
+	}
+	if r.TradeID != "" {
+		d += " trade " + r.TradeID
+	}
+	return d
+}
+
+// WriteCSV writes records as CSV with a header row of the mapping's names.
+func WriteCSV(w io.Writer, records []ExportRecord, mapping ExportMapping) error {
+	cw := csv.NewWriter(w)
+	row := make([]string, len(mapping))
+	for i, f := range mapping {
+		row[i] = f.Name
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+	for _, r := range records {
+		for i, f := range mapping {
+			row[i] = f.Value(r)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSONLines writes each record as a JSON object on its own line, keyed by the mapping's names in
+// the mapping's order.
+func WriteJSONLines(w io.Writer, records []ExportRecord, mapping ExportMapping) error {
+	var line bytes.Buffer
+	for _, r := range records {
+		line.Reset()
+		line.WriteByte('{')
+		for i, f := range mapping {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			name, err := json.Marshal(f.Name)
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(f.Value(r))
+			if err != nil {
+				return err
+			}
+			line.Write(name)
+			line.WriteByte(':')
+			line.Write(value)
+		}
+		line.WriteString("}\n")
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OFXAccount describes the account an OFX statement is for.
+type OFXAccount struct {
+	// AccountID is the coinbase pro account ID.
+	AccountID string
+
+	// Currency is the statement currency. Only records in it are written.
+	Currency string
+
+	// BankID defaults to "COINBASEPRO".
+	BankID string
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	FITID  string `xml:"FITID"`
+	Name   string `xml:"NAME"`
+	Memo   string `xml:"MEMO,omitempty"`
+}
+
+type ofxStatement struct {
+	XMLName  xml.Name         `xml:"OFX"`
+	TrnUID   string           `xml:"BANKMSGSRSV1>STMTTRNRS>TRNUID"`
+	Code     int              `xml:"BANKMSGSRSV1>STMTTRNRS>STATUS>CODE"`
+	Severity string           `xml:"BANKMSGSRSV1>STMTTRNRS>STATUS>SEVERITY"`
+	CurDef   string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>CURDEF"`
+	BankID   string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>BANKID"`
+	AcctID   string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTID"`
+	AcctType string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKACCTFROM>ACCTTYPE"`
+	Start    string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTSTART"`
+	End      string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTEND"`
+	Trans    []ofxTransaction `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+	Balance  string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>BALAMT,omitempty"`
+	BalDate  string           `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>DTASOF,omitempty"`
+}
+
+// WriteOFX writes the records in account.Currency as an OFX 2 bank statement that accounting software
+// can import. Each record becomes a transaction with its ID as the FITID and its type, product, order
+// and trade IDs in the memo. The ledger balance is the Balance of the latest record that has one.
+// Records at the same time, like the match and fee entries of a fill, go by ledger ID, or by which
+// comes first when the IDs aren't numbers since GetAccountHistory lists the newest first.
+func WriteOFX(w io.Writer, records []ExportRecord, account OFXAccount) error {
+	s := ofxStatement{
+		TrnUID:   "1",
+		Severity: "INFO",
+		CurDef:   account.Currency,
+		BankID:   account.BankID,
+		AcctID:   account.AccountID,
+		AcctType: "CHECKING",
+	}
+	if s.BankID == "" {
+		s.BankID = "COINBASEPRO"
+	}
+
+	var start, end, balanceAt time.Time
+	var balanceID string
+	for _, r := range records {
+		if r.Currency != account.Currency {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, r.Time)
+		if err != nil {
+			return err
+		}
+		amount, err := parseDecimal(r.Amount)
+		if err != nil {
+			return err
+		}
+		trnType := "CREDIT"
+		switch {
+		case r.Type == "fee":
+			trnType = "FEE"
+		case amount.Sign() < 0:
+			trnType = "DEBIT"
+		}
+		s.Trans = append(s.Trans, ofxTransaction{
+			Type:   trnType,
+			Posted: ofxTime(at),
+			Amount: formatDecimal(amount),
+			FITID:  r.ID,
+			Name:   r.Type,
+			Memo:   exportDescription(r),
+		})
+		if start.IsZero() || at.Before(start) {
+			start = at
+		}
+		if at.After(end) {
+			end = at
+		}
+		if r.Balance != "" && (s.Balance == "" || at.After(balanceAt) || (at.Equal(balanceAt) && laterLedgerID(r.ID, balanceID))) {
+			s.Balance, s.BalDate, balanceAt, balanceID = r.Balance, ofxTime(at), at, r.ID
+		}
+	}
+	if len(s.Trans) > 0 {
+		s.Start, s.End = ofxTime(start), ofxTime(end)
+	}
+
+	if _, err := io.WriteString(w, xml.Header+`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// laterLedgerID reports whether ledger entry id comes after other. Ledger IDs count up.
+func laterLedgerID(id, other string) bool {
+	a, errA := strconv.ParseInt(id, 10, 64)
+	b, errB := strconv.ParseInt(other, 10, 64)
+	return errA == nil && errB == nil && a > b
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
@@ -0,0 +1,138 @@
+package godax
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+var exportFills = []Fill{
+	{TradeID: 74, ProductID: "BTC-USD", OrderID: "o1", CreatedAt: "2020-09-14T14:01:00.123Z", Side: "buy", Price: "10000", Size: "0.01", Fee: "0.5"},
+	{TradeID: 75, ProductID: "BTC-USD", OrderID: "o2", CreatedAt: "2020-09-14T14:02:00Z", Side: "sell", Price: "10100", Size: "0.01", Fee: "0.5"},
+}
+
+var exportHistory = []AccountActivity{
+	{ID: "100", CreatedAt: "2020-09-14T14:00:00Z", Amount: "1000", Balance: "1000", Type: "transfer"},
+	{ID: "101", CreatedAt: "2020-09-14T14:01:00.123Z", Amount: "-100", Balance: "900", Type: "match",
+		Details: ActivityDetail{OrderID: "o1", TradeID: "74", ProductID: "BTC-USD"}},
+	{ID: "102", CreatedAt: "2020-09-14T14:01:00.123Z", Amount: "-0.5", Balance: "899.5", Type: "fee",
+		Details: ActivityDetail{OrderID: "o1", TradeID: "74", ProductID: "BTC-USD"}},
+}
+
+func TestWriteCSV(t *testing.T) {
+	records, err := FillRecords(exportFills)
+	if err != nil {
+		t.Fatalf("FillRecords() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, records, KoinlyMapping); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "Date,Sent Amount,Sent Currency,Received Amount,Received Currency,Fee Amount,Fee Currency,Net Worth Amount,Net Worth Currency,Label,Description,TxHash\n" +
+		"2020-09-14 14:01:00 UTC,100,USD,0.01,BTC,0.5,USD,,,,trade BTC-USD order o1 trade 74,BTC-USD:74\n" +
+		"2020-09-14 14:02:00 UTC,0.01,BTC,101,USD,0.5,USD,,,,trade BTC-USD order o2 trade 75,BTC-USD:75\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	mapping := ExportMapping{
+		{"When", func(r ExportRecord) string { return r.Time }},
+		{"Order", func(r ExportRecord) string { return r.OrderID }},
+	}
+	if err := WriteCSV(&buf, records[:1], append(mapping, CoinTrackerMapping[1])); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if want := "When,Order,Received Quantity\n2020-09-14T14:01:00.123Z,o1,0.01\n"; buf.String() != want {
+		t.Errorf("WriteCSV() custom mapping = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	records, err := ActivityRecords("USD", exportHistory)
+	if err != nil {
+		t.Fatalf("ActivityRecords() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteJSONLines(&buf, records, DefaultExportMapping); err != nil {
+		t.Fatalf("WriteJSONLines() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("WriteJSONLines() wrote %d lines, want 3", len(lines))
+	}
+	want := `{"id":"102","time":"2020-09-14T14:01:00.123Z","type":"fee","currency":"USD","amount":"-0.5","balance":"899.5",` +
+		`"sent_amount":"0.5","sent_currency":"USD","received_amount":"","received_currency":"","fee_amount":"","fee_currency":"",` +
+		`"side":"","price":"","size":"","order_id":"o1","trade_id":"74","product_id":"BTC-USD"}`
+	if lines[2] != want {
+		t.Errorf("fee line =\n%s\nwant\n%s", lines[2], want)
+	}
+}
+
+func TestWriteOFX(t *testing.T) {
+	activities, err := ActivityRecords("USD", exportHistory)
+	if err != nil {
+		t.Fatalf("ActivityRecords() error = %v", err)
+	}
+	fills, err := FillRecords(exportFills)
+	if err != nil {
+		t.Fatalf("FillRecords() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOFX(&buf, append(activities, fills...), OFXAccount{AccountID: "acct-1", Currency: "USD"}); err != nil {
+		t.Fatalf("WriteOFX() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `<?OFX OFXHEADER="200"`) {
+		t.Errorf("WriteOFX() = %s, want an OFX header", buf.String())
+	}
+
+	var got ofxStatement
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal OFX: %v", err)
+	}
+	if got.CurDef != "USD" || got.AcctID != "acct-1" || got.BankID != "COINBASEPRO" || got.Balance != "899.5" {
+		t.Errorf("statement = %+v", got)
+	}
+	if got.Start != "20200914140000.000[0:GMT]" || got.End != "20200914140100.123[0:GMT]" {
+		t.Errorf("statement runs %s to %s", got.Start, got.End)
+	}
+	if len(got.Trans) != 3 {
+		t.Fatalf("transactions = %+v, want the 3 USD entries", got.Trans)
+	}
+	fee := got.Trans[2]
+	if fee.Type != "FEE" || fee.Amount != "-0.5" || fee.FITID != "102" || fee.Memo != "fee BTC-USD order o1 trade 74" {
+		t.Errorf("fee transaction = %+v", fee)
+	}
+	if got.Trans[0].Type != "CREDIT" || got.Trans[1].Type != "DEBIT" {
+		t.Errorf("transactions = %+v, want a credit then a debit", got.Trans)
+	}
+}
+
+func TestWriteOFX_BalanceTiesOnTime(t *testing.T) {
+	// newest first, as GetAccountHistory returns them, with the fill's match and fee at the same time
+	newestFirst := []AccountActivity{exportHistory[2], exportHistory[1], exportHistory[0]}
+	oldestFirst := exportHistory
+	unnumbered := []AccountActivity{exportHistory[2], exportHistory[1], exportHistory[0]}
+	unnumbered[0].ID, unnumbered[1].ID, unnumbered[2].ID = "c", "b", "a"
+
+	for name, history := range map[string][]AccountActivity{"newest first": newestFirst, "oldest first": oldestFirst, "unnumbered": unnumbered} {
+		records, err := ActivityRecords("USD", history)
+		if err != nil {
+			t.Fatalf("ActivityRecords() error = %v", err)
+		}
+		var buf bytes.Buffer
+		if err := WriteOFX(&buf, records, OFXAccount{AccountID: "acct-1", Currency: "USD"}); err != nil {
+			t.Fatalf("WriteOFX() error = %v", err)
+		}
+		var got ofxStatement
+		if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal OFX: %v", err)
+		}
+		if got.Balance != "899.5" {
+			t.Errorf("%s: balance = %s, want 899.5 after the fee", name, got.Balance)
+		}
+	}
+}
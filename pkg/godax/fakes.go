@@ -0,0 +1,471 @@
+// Code generated by genfakes from interfaces.go. DO NOT EDIT.
+
+package godax
+
+import "errors"
+
+// ErrNotStubbed is returned by a fake when the method called on it has no Func set.
+var ErrNotStubbed = errors.New("this fake method has not been stubbed")
+
+// FakeMarketDataAPI is a fake MarketDataAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeMarketDataAPI struct {
+	ListProductsFunc               func() ([]Product, error)
+	GetProductByIDFunc             func(productID string) (Product, error)
+	GetProductOrderBookFunc        func(productID string, qp QueryParams) (OrderBook, error)
+	GetProductTickerFunc           func(productID string) (Ticker, error)
+	ListTradesByProductFunc        func(productID string) ([]Trade, error)
+	GetHistoricRatesForProductFunc func(productID string, qp QueryParams) ([]HistoricRate, error)
+	Get24HourStatsForProductFunc   func(productID string) (DayStat, error)
+	ListCurrenciesFunc             func() ([]Currency, error)
+	GetServerTimeFunc              func() (ServerTime, error)
+	GetOracleFunc                  func() (Oracle, error)
+}
+
+// ListProducts calls ListProductsFunc.
+func (f *FakeMarketDataAPI) ListProducts() ([]Product, error) {
+	if f.ListProductsFunc == nil {
+		var r0 []Product
+		return r0, ErrNotStubbed
+	}
+	return f.ListProductsFunc()
+}
+
+// GetProductByID calls GetProductByIDFunc.
+func (f *FakeMarketDataAPI) GetProductByID(productID string) (Product, error) {
+	if f.GetProductByIDFunc == nil {
+		var r0 Product
+		return r0, ErrNotStubbed
+	}
+	return f.GetProductByIDFunc(productID)
+}
+
+// GetProductOrderBook calls GetProductOrderBookFunc.
+func (f *FakeMarketDataAPI) GetProductOrderBook(productID string, qp QueryParams) (OrderBook, error) {
+	if f.GetProductOrderBookFunc == nil {
+		var r0 OrderBook
+		return r0, ErrNotStubbed
+	}
+	return f.GetProductOrderBookFunc(productID, qp)
+}
+
+// GetProductTicker calls GetProductTickerFunc.
+func (f *FakeMarketDataAPI) GetProductTicker(productID string) (Ticker, error) {
+	if f.GetProductTickerFunc == nil {
+		var r0 Ticker
+		return r0, ErrNotStubbed
+	}
+	return f.GetProductTickerFunc(productID)
+}
+
+// ListTradesByProduct calls ListTradesByProductFunc.
+func (f *FakeMarketDataAPI) ListTradesByProduct(productID string) ([]Trade, error) {
+	if f.ListTradesByProductFunc == nil {
+		var r0 []Trade
+		return r0, ErrNotStubbed
+	}
+	return f.ListTradesByProductFunc(productID)
+}
+
+// GetHistoricRatesForProduct calls GetHistoricRatesForProductFunc.
+func (f *FakeMarketDataAPI) GetHistoricRatesForProduct(productID string, qp QueryParams) ([]HistoricRate, error) {
+	if f.GetHistoricRatesForProductFunc == nil {
+		var r0 []HistoricRate
+		return r0, ErrNotStubbed
+	}
+	return f.GetHistoricRatesForProductFunc(productID, qp)
+}
+
+// Get24HourStatsForProduct calls Get24HourStatsForProductFunc.
+func (f *FakeMarketDataAPI) Get24HourStatsForProduct(productID string) (DayStat, error) {
+	if f.Get24HourStatsForProductFunc == nil {
+		var r0 DayStat
+		return r0, ErrNotStubbed
+	}
+	return f.Get24HourStatsForProductFunc(productID)
+}
+
+// ListCurrencies calls ListCurrenciesFunc.
+func (f *FakeMarketDataAPI) ListCurrencies() ([]Currency, error) {
+	if f.ListCurrenciesFunc == nil {
+		var r0 []Currency
+		return r0, ErrNotStubbed
+	}
+	return f.ListCurrenciesFunc()
+}
+
+// GetServerTime calls GetServerTimeFunc.
+func (f *FakeMarketDataAPI) GetServerTime() (ServerTime, error) {
+	if f.GetServerTimeFunc == nil {
+		var r0 ServerTime
+		return r0, ErrNotStubbed
+	}
+	return f.GetServerTimeFunc()
+}
+
+// GetOracle calls GetOracleFunc.
+func (f *FakeMarketDataAPI) GetOracle() (Oracle, error) {
+	if f.GetOracleFunc == nil {
+		var r0 Oracle
+		return r0, ErrNotStubbed
+	}
+	return f.GetOracleFunc()
+}
+
+var _ MarketDataAPI = (*FakeMarketDataAPI)(nil)
+
+// FakeAccountsAPI is a fake AccountsAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeAccountsAPI struct {
+	ListAccountsFunc      func() ([]ListAccount, error)
+	GetAccountFunc        func(accountID string) (Account, error)
+	GetAccountHistoryFunc func(accountID string) ([]AccountActivity, error)
+	GetAccountHoldsFunc   func(accountID string) ([]AccountHold, error)
+	GetCurrentFeesFunc    func() (Fees, error)
+	GetTrailingVolumeFunc func() ([]UserAccount, error)
+}
+
+// ListAccounts calls ListAccountsFunc.
+func (f *FakeAccountsAPI) ListAccounts() ([]ListAccount, error) {
+	if f.ListAccountsFunc == nil {
+		var r0 []ListAccount
+		return r0, ErrNotStubbed
+	}
+	return f.ListAccountsFunc()
+}
+
+// GetAccount calls GetAccountFunc.
+func (f *FakeAccountsAPI) GetAccount(accountID string) (Account, error) {
+	if f.GetAccountFunc == nil {
+		var r0 Account
+		return r0, ErrNotStubbed
+	}
+	return f.GetAccountFunc(accountID)
+}
+
+// GetAccountHistory calls GetAccountHistoryFunc.
+func (f *FakeAccountsAPI) GetAccountHistory(accountID string) ([]AccountActivity, error) {
+	if f.GetAccountHistoryFunc == nil {
+		var r0 []AccountActivity
+		return r0, ErrNotStubbed
+	}
+	return f.GetAccountHistoryFunc(accountID)
+}
+
+// GetAccountHolds calls GetAccountHoldsFunc.
+func (f *FakeAccountsAPI) GetAccountHolds(accountID string) ([]AccountHold, error) {
+	if f.GetAccountHoldsFunc == nil {
+		var r0 []AccountHold
+		return r0, ErrNotStubbed
+	}
+	return f.GetAccountHoldsFunc(accountID)
+}
+
+// GetCurrentFees calls GetCurrentFeesFunc.
+func (f *FakeAccountsAPI) GetCurrentFees() (Fees, error) {
+	if f.GetCurrentFeesFunc == nil {
+		var r0 Fees
+		return r0, ErrNotStubbed
+	}
+	return f.GetCurrentFeesFunc()
+}
+
+// GetTrailingVolume calls GetTrailingVolumeFunc.
+func (f *FakeAccountsAPI) GetTrailingVolume() ([]UserAccount, error) {
+	if f.GetTrailingVolumeFunc == nil {
+		var r0 []UserAccount
+		return r0, ErrNotStubbed
+	}
+	return f.GetTrailingVolumeFunc()
+}
+
+var _ AccountsAPI = (*FakeAccountsAPI)(nil)
+
+// FakeOrdersAPI is a fake OrdersAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeOrdersAPI struct {
+	PlaceOrderFunc             func(order OrderParams) (Order, error)
+	CancelOrderByIDFunc        func(orderID string, qp QueryParams) error
+	CancelOrderByClientOIDFunc func(clientOID string, qp QueryParams) (string, error)
+	CancelAllOrdersFunc        func(qp QueryParams) ([]string, error)
+	ListOrdersFunc             func(qp QueryParams) ([]Order, error)
+	GetOrderByIDFunc           func(orderID string) (Order, error)
+	GetOrderByClientOIDFunc    func(orderClientOID string) (Order, error)
+	ListFillsFunc              func(qp QueryParams) ([]Fill, error)
+}
+
+// PlaceOrder calls PlaceOrderFunc.
+func (f *FakeOrdersAPI) PlaceOrder(order OrderParams) (Order, error) {
+	if f.PlaceOrderFunc == nil {
+		var r0 Order
+		return r0, ErrNotStubbed
+	}
+	return f.PlaceOrderFunc(order)
+}
+
+// CancelOrderByID calls CancelOrderByIDFunc.
+func (f *FakeOrdersAPI) CancelOrderByID(orderID string, qp QueryParams) error {
+	if f.CancelOrderByIDFunc == nil {
+		return ErrNotStubbed
+	}
+	return f.CancelOrderByIDFunc(orderID, qp)
+}
+
+// CancelOrderByClientOID calls CancelOrderByClientOIDFunc.
+func (f *FakeOrdersAPI) CancelOrderByClientOID(clientOID string, qp QueryParams) (string, error) {
+	if f.CancelOrderByClientOIDFunc == nil {
+		var r0 string
+		return r0, ErrNotStubbed
+	}
+	return f.CancelOrderByClientOIDFunc(clientOID, qp)
+}
+
+// CancelAllOrders calls CancelAllOrdersFunc.
+func (f *FakeOrdersAPI) CancelAllOrders(qp QueryParams) ([]string, error) {
+	if f.CancelAllOrdersFunc == nil {
+		var r0 []string
+		return r0, ErrNotStubbed
+	}
+	return f.CancelAllOrdersFunc(qp)
+}
+
+// ListOrders calls ListOrdersFunc.
+func (f *FakeOrdersAPI) ListOrders(qp QueryParams) ([]Order, error) {
+	if f.ListOrdersFunc == nil {
+		var r0 []Order
+		return r0, ErrNotStubbed
+	}
+	return f.ListOrdersFunc(qp)
+}
+
+// GetOrderByID calls GetOrderByIDFunc.
+func (f *FakeOrdersAPI) GetOrderByID(orderID string) (Order, error) {
+	if f.GetOrderByIDFunc == nil {
+		var r0 Order
+		return r0, ErrNotStubbed
+	}
+	return f.GetOrderByIDFunc(orderID)
+}
+
+// GetOrderByClientOID calls GetOrderByClientOIDFunc.
+func (f *FakeOrdersAPI) GetOrderByClientOID(orderClientOID string) (Order, error) {
+	if f.GetOrderByClientOIDFunc == nil {
+		var r0 Order
+		return r0, ErrNotStubbed
+	}
+	return f.GetOrderByClientOIDFunc(orderClientOID)
+}
+
+// ListFills calls ListFillsFunc.
+func (f *FakeOrdersAPI) ListFills(qp QueryParams) ([]Fill, error) {
+	if f.ListFillsFunc == nil {
+		var r0 []Fill
+		return r0, ErrNotStubbed
+	}
+	return f.ListFillsFunc(qp)
+}
+
+var _ OrdersAPI = (*FakeOrdersAPI)(nil)
+
+// FakeFundingAPI is a fake FundingAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeFundingAPI struct {
+	StableCoinConversionFunc     func(from string, to string, amount string) (Conversion, error)
+	ListPaymentMethodsFunc       func() ([]PaymentMethod, error)
+	ListCoinbaseAccountsFunc     func() ([]CoinbaseAccount, error)
+	GetCurrentExchangeLimitsFunc func() (ExchangeLimit, error)
+}
+
+// StableCoinConversion calls StableCoinConversionFunc.
+func (f *FakeFundingAPI) StableCoinConversion(from string, to string, amount string) (Conversion, error) {
+	if f.StableCoinConversionFunc == nil {
+		var r0 Conversion
+		return r0, ErrNotStubbed
+	}
+	return f.StableCoinConversionFunc(from, to, amount)
+}
+
+// ListPaymentMethods calls ListPaymentMethodsFunc.
+func (f *FakeFundingAPI) ListPaymentMethods() ([]PaymentMethod, error) {
+	if f.ListPaymentMethodsFunc == nil {
+		var r0 []PaymentMethod
+		return r0, ErrNotStubbed
+	}
+	return f.ListPaymentMethodsFunc()
+}
+
+// ListCoinbaseAccounts calls ListCoinbaseAccountsFunc.
+func (f *FakeFundingAPI) ListCoinbaseAccounts() ([]CoinbaseAccount, error) {
+	if f.ListCoinbaseAccountsFunc == nil {
+		var r0 []CoinbaseAccount
+		return r0, ErrNotStubbed
+	}
+	return f.ListCoinbaseAccountsFunc()
+}
+
+// GetCurrentExchangeLimits calls GetCurrentExchangeLimitsFunc.
+func (f *FakeFundingAPI) GetCurrentExchangeLimits() (ExchangeLimit, error) {
+	if f.GetCurrentExchangeLimitsFunc == nil {
+		var r0 ExchangeLimit
+		return r0, ErrNotStubbed
+	}
+	return f.GetCurrentExchangeLimitsFunc()
+}
+
+var _ FundingAPI = (*FakeFundingAPI)(nil)
+
+// FakeProfilesAPI is a fake ProfilesAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeProfilesAPI struct {
+	ListProfilesFunc    func() ([]Profile, error)
+	GetProfileFunc      func(profileID string) (Profile, error)
+	ProfileTransferFunc func(transfer TransferParams) error
+}
+
+// ListProfiles calls ListProfilesFunc.
+func (f *FakeProfilesAPI) ListProfiles() ([]Profile, error) {
+	if f.ListProfilesFunc == nil {
+		var r0 []Profile
+		return r0, ErrNotStubbed
+	}
+	return f.ListProfilesFunc()
+}
+
+// GetProfile calls GetProfileFunc.
+func (f *FakeProfilesAPI) GetProfile(profileID string) (Profile, error) {
+	if f.GetProfileFunc == nil {
+		var r0 Profile
+		return r0, ErrNotStubbed
+	}
+	return f.GetProfileFunc(profileID)
+}
+
+// ProfileTransfer calls ProfileTransferFunc.
+func (f *FakeProfilesAPI) ProfileTransfer(transfer TransferParams) error {
+	if f.ProfileTransferFunc == nil {
+		return ErrNotStubbed
+	}
+	return f.ProfileTransferFunc(transfer)
+}
+
+var _ ProfilesAPI = (*FakeProfilesAPI)(nil)
+
+// FakeReportsAPI is a fake ReportsAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeReportsAPI struct {
+	CreateReportFunc    func(report ReportParams) (ReportStatus, error)
+	GetReportStatusFunc func(reportID string) (ReportStatus, error)
+}
+
+// CreateReport calls CreateReportFunc.
+func (f *FakeReportsAPI) CreateReport(report ReportParams) (ReportStatus, error) {
+	if f.CreateReportFunc == nil {
+		var r0 ReportStatus
+		return r0, ErrNotStubbed
+	}
+	return f.CreateReportFunc(report)
+}
+
+// GetReportStatus calls GetReportStatusFunc.
+func (f *FakeReportsAPI) GetReportStatus(reportID string) (ReportStatus, error) {
+	if f.GetReportStatusFunc == nil {
+		var r0 ReportStatus
+		return r0, ErrNotStubbed
+	}
+	return f.GetReportStatusFunc(reportID)
+}
+
+var _ ReportsAPI = (*FakeReportsAPI)(nil)
+
+// FakeMarginAPI is a fake MarginAPI. Set a method's Func field to stub it, unset methods return ErrNotStubbed.
+type FakeMarginAPI struct {
+	GetMarginProfileFunc              func(qp QueryParams) (MarginProfile, error)
+	GetBuyingPowerFunc                func(qp QueryParams) (BuyingPower, error)
+	GetWithdrawalPowerForCurrencyFunc func(qp QueryParams) ([]CurrencyWithdrawalPower, error)
+	GetAllWithdrawalPowerFunc         func() ([]AllWithdrawalPower, error)
+	GetMarginExitPlanFunc             func() (ExitPlan, error)
+	ListLiquidationHistoryFunc        func(qp QueryParams) ([]LiquidationEvent, error)
+	GetPositionRefreshAmountsFunc     func(qp QueryParams) (RefreshAmount, error)
+	GetMarginStatusFunc               func() (MarginStatus, error)
+}
+
+// GetMarginProfile calls GetMarginProfileFunc.
+func (f *FakeMarginAPI) GetMarginProfile(qp QueryParams) (MarginProfile, error) {
+	if f.GetMarginProfileFunc == nil {
+		var r0 MarginProfile
+		return r0, ErrNotStubbed
+	}
+	return f.GetMarginProfileFunc(qp)
+}
+
+// GetBuyingPower calls GetBuyingPowerFunc.
+func (f *FakeMarginAPI) GetBuyingPower(qp QueryParams) (BuyingPower, error) {
+	if f.GetBuyingPowerFunc == nil {
+		var r0 BuyingPower
+		return r0, ErrNotStubbed
+	}
+	return f.GetBuyingPowerFunc(qp)
+}
+
+// GetWithdrawalPowerForCurrency calls GetWithdrawalPowerForCurrencyFunc.
+func (f *FakeMarginAPI) GetWithdrawalPowerForCurrency(qp QueryParams) ([]CurrencyWithdrawalPower, error) {
+	if f.GetWithdrawalPowerForCurrencyFunc == nil {
+		var r0 []CurrencyWithdrawalPower
+		return r0, ErrNotStubbed
+	}
+	return f.GetWithdrawalPowerForCurrencyFunc(qp)
+}
+
+// GetAllWithdrawalPower calls GetAllWithdrawalPowerFunc.
+func (f *FakeMarginAPI) GetAllWithdrawalPower() ([]AllWithdrawalPower, error) {
+	if f.GetAllWithdrawalPowerFunc == nil {
+		var r0 []AllWithdrawalPower
+		return r0, ErrNotStubbed
+	}
+	return f.GetAllWithdrawalPowerFunc()
+}
+
+// GetMarginExitPlan calls GetMarginExitPlanFunc.
+func (f *FakeMarginAPI) GetMarginExitPlan() (ExitPlan, error) {
+	if f.GetMarginExitPlanFunc == nil {
+		var r0 ExitPlan
+		return r0, ErrNotStubbed
+	}
+	return f.GetMarginExitPlanFunc()
+}
+
+// ListLiquidationHistory calls ListLiquidationHistoryFunc.
+func (f *FakeMarginAPI) ListLiquidationHistory(qp QueryParams) ([]LiquidationEvent, error) {
+	if f.ListLiquidationHistoryFunc == nil {
+		var r0 []LiquidationEvent
+		return r0, ErrNotStubbed
+	}
+	return f.ListLiquidationHistoryFunc(qp)
+}
+
+// GetPositionRefreshAmounts calls GetPositionRefreshAmountsFunc.
+func (f *FakeMarginAPI) GetPositionRefreshAmounts(qp QueryParams) (RefreshAmount, error) {
+	if f.GetPositionRefreshAmountsFunc == nil {
+		var r0 RefreshAmount
+		return r0, ErrNotStubbed
+	}
+	return f.GetPositionRefreshAmountsFunc(qp)
+}
+
+// GetMarginStatus calls GetMarginStatusFunc.
+func (f *FakeMarginAPI) GetMarginStatus() (MarginStatus, error) {
+	if f.GetMarginStatusFunc == nil {
+		var r0 MarginStatus
+		return r0, ErrNotStubbed
+	}
+	return f.GetMarginStatusFunc()
+}
+
+var _ MarginAPI = (*FakeMarginAPI)(nil)
+
+// FakeAPI is a fake API. Set the Func fields on the embedded fakes to stub behavior.
+type FakeAPI struct {
+	FakeMarketDataAPI
+	FakeAccountsAPI
+	FakeOrdersAPI
+	FakeFundingAPI
+	FakeProfilesAPI
+	FakeReportsAPI
+	FakeMarginAPI
+}
+
+var _ API = (*FakeAPI)(nil)
@@ -0,0 +1,279 @@
+package godax
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// ErrInvalidMakerLikelihood is returned for a maker likelihood outside of 0 to 1.
+var ErrInvalidMakerLikelihood = errors.New("please provide a maker likelihood between 0 and 1")
+
+// FeeTier is one tier of a fee schedule. A profile is in the highest tier whose MinUSDVolume its 30
+// day USD volume reaches.
+type FeeTier struct {
+	MinUSDVolume string
+	MakerFeeRate string
+	TakerFeeRate string
+}
+
+// DefaultFeeSchedule is the coinbase pro fee schedule as published in August 2020. Coinbase changes it
+// from time to time, so pass your own to the tier functions when it does.
+var DefaultFeeSchedule = []FeeTier{
+	{MinUSDVolume: "0", MakerFeeRate: "0.005", TakerFeeRate: "0.005"},
+	{MinUSDVolume: "10000", MakerFeeRate: "0.0035", TakerFeeRate: "0.0035"},
+	{MinUSDVolume: "50000", MakerFeeRate: "0.0015", TakerFeeRate: "0.0025"},
+	{MinUSDVolume: "100000", MakerFeeRate: "0.001", TakerFeeRate: "0.002"},
+	{MinUSDVolume: "1000000", MakerFeeRate: "0.0008", TakerFeeRate: "0.0018"},
+	{MinUSDVolume: "10000000", MakerFeeRate: "0.0005", TakerFeeRate: "0.0015"},
+	{MinUSDVolume: "50000000", MakerFeeRate: "0", TakerFeeRate: "0.001"},
+	{MinUSDVolume: "100000000", MakerFeeRate: "0", TakerFeeRate: "0.0007"},
+	{MinUSDVolume: "300000000", MakerFeeRate: "0", TakerFeeRate: "0.0006"},
+	{MinUSDVolume: "500000000", MakerFeeRate: "0", TakerFeeRate: "0.0005"},
+	{MinUSDVolume: "1000000000", MakerFeeRate: "0", TakerFeeRate: "0.0004"},
+}
+
+// FeeEstimate is the expected fee of an order.
+type FeeEstimate struct {
+	// Notional is the order's value in quote currency, before fees.
+	Notional string
+
+	// Rate is the expected fee rate, the maker and taker rates weighted by the maker likelihood.
+	Rate string
+
+	// Fee is Notional * Rate.
+	Fee string
+
+	// Total is what a buy costs (Notional + Fee) or a sell brings in (Notional - Fee).
+	Total string
+}
+
+// FeeCalculator estimates fees and sizes orders so their fees are covered, at the rates from
+// GetCurrentFees.
+type FeeCalculator struct {
+	maker *big.Rat
+	taker *big.Rat
+}
+
+// NewFeeCalculator creates a FeeCalculator for the rates in fees.
+func NewFeeCalculator(fees Fees) (*FeeCalculator, error) {
+	maker, err := parseDecimal(fees.MakerFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	taker, err := parseDecimal(fees.TakerFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	return &FeeCalculator{maker: maker, taker: taker}, nil
+}
+
+// Estimate works out the expected fee of an order. price is used for market orders by size (the
+// ticker price, say) and ignored otherwise. makerLikelihood, from 0 to 1, is the chance a limit order
+// rests on the book and pays the maker rate. Post-only orders always pay the maker rate and market
+// orders the taker rate, whatever the likelihood. A market buy's Funds include its fee, as coinbase
+// takes the fee out of them.
+func (c *FeeCalculator) Estimate(order OrderParams, price string, makerLikelihood string) (FeeEstimate, error) {
+	rate, err := c.rate(order, makerLikelihood)
+	if err != nil {
+		return FeeEstimate{}, err
+	}
+
+	var notional *big.Rat
+	if order.Type == "market" && order.Funds != "" {
+		funds, err := parseDecimal(order.Funds)
+		if err != nil {
+			return FeeEstimate{}, err
+		}
+		if order.Side == "buy" {
+			notional = quo(funds, add(newRat().SetInt64(1), rate))
+		} else {
+			notional = funds
+		}
+	} else {
+		size, err := parseDecimal(order.Size)
+		if err != nil {
+			return FeeEstimate{}, err
+		}
+		if order.Type != "market" {
+			price = order.Price
+		}
+		p, err := parseDecimal(price)
+		if err != nil {
+			return FeeEstimate{}, err
+		}
+		notional = mul(size, p)
+	}
+
+	fee := mul(notional, rate)
+	total := add(notional, fee)
+	if order.Side == "sell" {
+		total = sub(notional, fee)
+	}
+	return FeeEstimate{
+		Notional: formatDecimal(notional),
+		Rate:     formatDecimal(rate),
+		Fee:      formatDecimal(fee),
+		Total:    formatDecimal(total),
+	}, nil
+}
+
+// MaxBuySize is the largest size a limit buy at price can be with available quote currency, rounded
+// down to baseIncrement. Coinbase holds a buy's value plus the taker fee whether or not it ends up
+// making, so the taker rate is used.
+func (c *FeeCalculator) MaxBuySize(available, price, baseIncrement string) (string, error) {
+	a, err := parseDecimal(available)
+	if err != nil {
+		return "", err
+	}
+	p, err := parseDecimal(price)
+	if err != nil {
+		return "", err
+	}
+	increment, err := parseDecimal(baseIncrement)
+	if err != nil {
+		return "", err
+	}
+	if p.Sign() <= 0 || a.Sign() <= 0 {
+		return "0", nil
+	}
+	size := quo(a, mul(p, add(newRat().SetInt64(1), c.taker)))
+	return formatDecimal(floorTo(size, increment)), nil
+}
+
+// FundsForSize is the Funds a market buy needs to get size at price once the taker fee comes out of
+// them, rounded up to quoteIncrement. Passing size * price as Funds buys less than size.
+func (c *FeeCalculator) FundsForSize(size, price, quoteIncrement string) (string, error) {
+	s, err := parseDecimal(size)
+	if err != nil {
+		return "", err
+	}
+	p, err := parseDecimal(price)
+	if err != nil {
+		return "", err
+	}
+	increment, err := parseDecimal(quoteIncrement)
+	if err != nil {
+		return "", err
+	}
+	funds := mul(mul(s, p), add(newRat().SetInt64(1), c.taker))
+	if floored := floorTo(funds, increment); floored.Cmp(funds) != 0 {
+		funds = add(floored, increment)
+	}
+	return formatDecimal(funds), nil
+}
+
+func (c *FeeCalculator) rate(order OrderParams, makerLikelihood string) (*big.Rat, error) {
+	switch {
+	case order.Type == "market":
+		return c.taker, nil
+	case order.PostOnly:
+		return c.maker, nil
+	}
+	likelihood, err := parseDecimal(makerLikelihood)
+	if err != nil {
+		return nil, err
+	}
+	if likelihood.Sign() < 0 || likelihood.Cmp(newRat().SetInt64(1)) > 0 {
+		return nil, ErrInvalidMakerLikelihood
+	}
+	return add(mul(likelihood, c.maker), mul(sub(newRat().SetInt64(1), likelihood), c.taker)), nil
+}
+
+// FeeTierProjection places a 30 day USD volume in a fee schedule.
+type FeeTierProjection struct {
+	USDVolume string
+	Current   FeeTier
+
+	// Next is the tier above Current, nil at the top of the schedule. VolumeToNext is how much more
+	// USD volume reaches it.
+	Next         *FeeTier
+	VolumeToNext string
+}
+
+// ProjectFeeTier finds the tier a 30 day USD volume (Fees.USDVolume, or TrailingUSDVolume) is in and
+// how far away the next one is. A nil schedule uses DefaultFeeSchedule.
+func ProjectFeeTier(usdVolume string, schedule []FeeTier) (FeeTierProjection, error) {
+	if schedule == nil {
+		schedule = DefaultFeeSchedule
+	}
+	volume, err := parseDecimal(usdVolume)
+	if err != nil {
+		return FeeTierProjection{}, err
+	}
+	tiers := make([]FeeTier, len(schedule))
+	mins := make([]*big.Rat, len(schedule))
+	copy(tiers, schedule)
+	for i := range tiers {
+		if mins[i], err = parseDecimal(tiers[i].MinUSDVolume); err != nil {
+			return FeeTierProjection{}, err
+		}
+	}
+	sort.Sort(feeTiers{tiers, mins})
+
+	p := FeeTierProjection{USDVolume: formatDecimal(volume)}
+	for i := range tiers {
+		if volume.Cmp(mins[i]) < 0 {
+			next := tiers[i]
+			p.Next = &next
+			p.VolumeToNext = formatDecimal(sub(mins[i], volume))
+			break
+		}
+		p.Current = tiers[i]
+	}
+	return p, nil
+}
+
+// TrailingUSDVolume values the per product 30 day volumes from GetTrailingVolume, which are in base
+// currency, in USD at the current ticker prices. Products are priced through other products when they
+// don't trade against USD, like ValuePortfolio does. Products that can't be priced are left out of the
+// volume and returned as unpriced, so an undercount doesn't go unnoticed.
+func TrailingUSDVolume(market MarketDataAPI, volumes []UserAccount) (string, []string, error) {
+	products, err := market.ListProducts()
+	if err != nil {
+		return "", nil, err
+	}
+	graph := priceGraph(products)
+	bases := make(map[string]string)
+	for _, p := range products {
+		bases[p.ID] = p.BaseCurrency
+	}
+	prices := make(map[string]*big.Rat)
+
+	var unpriced []string
+	total := newRat()
+	for _, v := range volumes {
+		size, err := parseDecimal(v.Volume)
+		if err != nil {
+			return "", nil, err
+		}
+		if size.Sign() == 0 {
+			continue
+		}
+		base, ok := bases[v.ProductID]
+		if !ok {
+			unpriced = append(unpriced, v.ProductID)
+			continue
+		}
+		price, _, err := routePrice(market, graph, prices, base, "USD")
+		if err != nil || price == nil {
+			unpriced = append(unpriced, v.ProductID)
+			continue
+		}
+		total = add(total, mul(size, price))
+	}
+	return formatDecimal(total), unpriced, nil
+}
+
+// feeTiers sorts tiers by their minimum volume.
+type feeTiers struct {
+	tiers []FeeTier
+	mins  []*big.Rat
+}
+
+func (t feeTiers) Len() int           { return len(t.tiers) }
+func (t feeTiers) Less(i, j int) bool { return t.mins[i].Cmp(t.mins[j]) < 0 }
+func (t feeTiers) Swap(i, j int) {
+	t.tiers[i], t.tiers[j] = t.tiers[j], t.tiers[i]
+	t.mins[i], t.mins[j] = t.mins[j], t.mins[i]
+}
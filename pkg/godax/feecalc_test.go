@@ -0,0 +1,129 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFeeCalculator_Estimate(t *testing.T) {
+	c, err := NewFeeCalculator(Fees{MakerFeeRate: "0.0015", TakerFeeRate: "0.0025"})
+	if err != nil {
+		t.Fatalf("NewFeeCalculator() error = %v", err)
+	}
+	limit := func(side, price, size string, postOnly bool) OrderParams {
+		return OrderParams{
+			CommonOrderParams: CommonOrderParams{Side: side, ProductID: "BTC-USD", Type: "limit", Price: price, Size: size},
+			LimitOrderParams:  LimitOrderParams{PostOnly: postOnly},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		order      OrderParams
+		price      string
+		likelihood string
+		want       FeeEstimate
+	}{
+		{"limit even odds", limit("buy", "100", "1", false), "", "0.5", FeeEstimate{"100", "0.002", "0.2", "100.2"}},
+		{"post only", limit("buy", "100", "1", true), "", "0", FeeEstimate{"100", "0.0015", "0.15", "100.15"}},
+		{"market buy funds", marketOrder("buy", "", "100.25"), "", "1", FeeEstimate{"100", "0.0025", "0.25", "100.25"}},
+		{"market sell size", marketOrder("sell", "2", ""), "50", "", FeeEstimate{"100", "0.0025", "0.25", "99.75"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Estimate(tt.order, tt.price, tt.likelihood)
+			if err != nil {
+				t.Fatalf("Estimate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Estimate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.Estimate(limit("buy", "100", "1", false), "", "1.5"); err != ErrInvalidMakerLikelihood {
+		t.Errorf("Estimate() error = %v, want ErrInvalidMakerLikelihood", err)
+	}
+}
+
+func TestFeeCalculator_Sizing(t *testing.T) {
+	c, err := NewFeeCalculator(Fees{MakerFeeRate: "0.0015", TakerFeeRate: "0.0025"})
+	if err != nil {
+		t.Fatalf("NewFeeCalculator() error = %v", err)
+	}
+	if got, _ := c.MaxBuySize("1000", "100", "0.00000001"); got != "9.97506234" {
+		t.Errorf("MaxBuySize() = %s, want 9.97506234", got)
+	}
+	if got, _ := c.MaxBuySize("0", "100", "0.00000001"); got != "0" {
+		t.Errorf("MaxBuySize() with nothing available = %s, want 0", got)
+	}
+	if got, _ := c.FundsForSize("1", "100", "0.01"); got != "100.25" {
+		t.Errorf("FundsForSize() = %s, want 100.25", got)
+	}
+	if got, _ := c.FundsForSize("0.333", "100", "0.01"); got != "33.39" {
+		t.Errorf("FundsForSize() = %s, want 33.39 rounded up", got)
+	}
+
+	// the funds buy the size back
+	funds := marketOrder("buy", "", "33.39")
+	est, err := c.Estimate(funds, "", "")
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	if n, _ := parseDecimal(est.Notional); n.Cmp(newRat().SetFrac64(333, 10)) < 0 {
+		t.Errorf("FundsForSize() notional = %s, want at least 33.3", est.Notional)
+	}
+}
+
+func marketOrder(side, size, funds string) OrderParams {
+	return OrderParams{
+		CommonOrderParams: CommonOrderParams{Side: side, ProductID: "BTC-USD", Type: "market", Size: size},
+		MarketOrderParams: MarketOrderParams{Funds: funds},
+	}
+}
+
+func TestProjectFeeTier(t *testing.T) {
+	p, err := ProjectFeeTier("25000", nil)
+	if err != nil {
+		t.Fatalf("ProjectFeeTier() error = %v", err)
+	}
+	if p.Current.MinUSDVolume != "10000" || p.Next == nil || p.Next.TakerFeeRate != "0.0025" || p.VolumeToNext != "25000" {
+		t.Errorf("ProjectFeeTier() = %+v, want 25000 short of the 50000 tier", p)
+	}
+
+	p, err = ProjectFeeTier("2000000000", []FeeTier{{"1000", "0.1", "0.2"}, {"0", "0.3", "0.4"}})
+	if err != nil {
+		t.Fatalf("ProjectFeeTier() error = %v", err)
+	}
+	if p.Current.MinUSDVolume != "1000" || p.Next != nil || p.VolumeToNext != "" {
+		t.Errorf("ProjectFeeTier() = %+v, want the top tier", p)
+	}
+}
+
+func TestTrailingUSDVolume(t *testing.T) {
+	var lookups []string
+	market := valuationMarket(&lookups)
+	prices := market.GetProductTickerFunc
+	market.GetProductTickerFunc = func(productID string) (Ticker, error) {
+		if productID == "XLM-BTC" {
+			return Ticker{Price: "0"}, nil // hasn't traded
+		}
+		return prices(productID)
+	}
+
+	got, unpriced, err := TrailingUSDVolume(market, []UserAccount{
+		{ProductID: "BTC-USD", Volume: "2"},
+		{ProductID: "ETH-BTC", Volume: "10"},
+		{ProductID: "XLM-BTC", Volume: "1000"},
+		{ProductID: "UNKNOWN-USD", Volume: "5"},
+	})
+	if err != nil {
+		t.Fatalf("TrailingUSDVolume() error = %v", err)
+	}
+	if got != "24000" {
+		t.Errorf("TrailingUSDVolume() = %s, want 20000 of BTC and 4000 of ETH", got)
+	}
+	if want := []string{"XLM-BTC", "UNKNOWN-USD"}; !reflect.DeepEqual(unpriced, want) {
+		t.Errorf("TrailingUSDVolume() unpriced = %v, want %v", unpriced, want)
+	}
+}
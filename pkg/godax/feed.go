@@ -0,0 +1,100 @@
+package godax
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FeedMessage is a message from one of the coinbase pro websocket feed channels. The feed sends
+// every message type as a flat JSON object, so FeedMessage is flat as well and only the fields
+// relevant to a message's Type are set. godax does not dial the websocket feed itself yet: read
+// messages off of your own connection with ParseFeedMessage and hand them to the components that
+// consume them.
+type FeedMessage struct {
+	// Type is the message type, for example "match", "last_match" or "ticker".
+	Type string `json:"type"`
+
+	// Sequence numbers increase by one for each message of a product and can be used to detect gaps.
+	Sequence int64 `json:"sequence"`
+
+	// ProductID - the product ID the message is for.
+	ProductID string `json:"product_id"`
+
+	// Time the message was generated, ISO 8601.
+	Time string `json:"time"`
+
+	// TradeID is set on match messages.
+	TradeID int `json:"trade_id"`
+
+	// MakerOrderID is set on match messages.
+	MakerOrderID string `json:"maker_order_id"`
+
+	// TakerOrderID is set on match messages.
+	TakerOrderID string `json:"taker_order_id"`
+
+	// Side is the maker order side on match messages, and the order side on order messages.
+	Side string `json:"side"`
+
+	// Size is the size matched on match messages, and the order size on received messages.
+	Size string `json:"size"`
+
+	// Price is the match price on match messages, the order price on order messages and the last
+	// trade price on ticker messages.
+	Price string `json:"price"`
+
+	// OrderID is set on the order messages of the full and user channels (received, open, done, change).
+	OrderID string `json:"order_id"`
+
+	// ClientOID is set on received messages for your own orders on the user channel.
+	ClientOID string `json:"client_oid"`
+
+	// OrderType is set on received messages, "limit" or "market".
+	OrderType string `json:"order_type"`
+
+	// Funds is set on received messages for market orders placed with funds.
+	Funds string `json:"funds"`
+
+	// RemainingSize is how much of the order is left unfilled, set on open and done messages.
+	RemainingSize string `json:"remaining_size"`
+
+	// Reason is set on done messages, either "filled" or "canceled".
+	Reason string `json:"reason"`
+
+	// NewSize and OldSize are set on change messages for limit orders.
+	NewSize string `json:"new_size"`
+	OldSize string `json:"old_size"`
+
+	// NewFunds and OldFunds are set on change messages for market orders.
+	NewFunds string `json:"new_funds"`
+	OldFunds string `json:"old_funds"`
+
+	// StopType and StopPrice are set on activate messages for stop orders.
+	StopType  string `json:"stop_type"`
+	StopPrice string `json:"stop_price"`
+}
+
+// Feed message types
+const (
+	FeedReceived  = "received"
+	FeedOpen      = "open"
+	FeedDone      = "done"
+	FeedMatch     = "match"
+	FeedChange    = "change"
+	FeedLastMatch = "last_match"
+	FeedTicker    = "ticker"
+	FeedActivate  = "activate"
+)
+
+// ParseFeedMessage decodes a raw message from the websocket feed.
+func ParseFeedMessage(b []byte) (FeedMessage, error) {
+	var msg FeedMessage
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return FeedMessage{}, err
+	}
+	return msg, nil
+}
+
+// ParsedTime parses the message's Time.
+func (m FeedMessage) ParsedTime() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, m.Time)
+}
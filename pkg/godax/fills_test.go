@@ -1,6 +1,7 @@
 package godax
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
 )
@@ -92,3 +93,20 @@ func TestClient_ListFills(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ListFillsPaginates(t *testing.T) {
+	mockClient := MockResponses(`[{"trade_id": 2}]`, `[{"trade_id": 1}]`)
+	mockClient.Headers = []http.Header{{"Cb-After": []string{"cursor-1"}}, {}}
+	c := &Client{baseRestURL: baseRestURL, key: key, secret: secret, passphrase: passphrase, httpClient: mockClient}
+
+	got, err := c.ListFills(QueryParams{ProductIDParam: "BTC-USD"})
+	if err != nil {
+		t.Fatalf("Client.ListFills() error = %v", err)
+	}
+	if want := []Fill{{TradeID: 2}, {TradeID: 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.ListFills() = %v, want %v", got, want)
+	}
+	if len(mockClient.Requests) != 2 || mockClient.Requests[1].URL.Query().Get("after") != "cursor-1" {
+		t.Errorf("should have fetched the second page after cursor-1, made %d requests", len(mockClient.Requests))
+	}
+}
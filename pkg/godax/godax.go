@@ -15,6 +15,8 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
 )
 
 // Client is the main export of godax. All its fields are unexported.
@@ -26,6 +28,13 @@ type Client struct {
 	secret      string
 	passphrase  string
 	httpClient  HTTPClient
+
+	// dryRun logs mutating requests instead of sending them when set, see WithDryRun
+	dryRun       Logger
+	dryRunOrders *dryRunOrders
+
+	// live guards mutating requests against the live API, see WithLiveTrading
+	live *liveGuard
 }
 
 // Param is a type alias for a string. The hope is that godax can offer all of the available
@@ -49,6 +58,11 @@ const (
 
 	// CurrencyParam is used when calling GetWithdrawalPower
 	CurrencyParam Param = "currency"
+
+	// These params are used to page through paginated endpoints such as ListOrders
+	BeforeParam Param = "before"
+	AfterParam  Param = "after"
+	LimitParam  Param = "limit"
 )
 
 var noBody = []byte{}
@@ -64,16 +78,42 @@ var (
 	ErrCoinbaseProAPIChange    = errors.New("there appears to have been a coinbase pro API change. Please open a new issue on godax, thanks")
 	ErrMarginMethodsOnHold     = errors.New("all methods interacting with margin are on hold until I can test on the sandbox")
 	ErrMissingCurrency         = errors.New("please provide the WithdrawalCurrency param")
+	ErrMissingCredentials      = errors.New("please provide a key, secret and passphrase")
 )
 
-// NewClient returns a godax Client that is hooked up to the live REST and web socket APIs.
-func NewClient() (*Client, error) {
-	return newClient(false)
+// NewClient returns a godax Client that is hooked up to the live REST and web socket APIs. It can only
+// read until it is given the WithLiveTrading option.
+func NewClient(opts ...ClientOption) (*Client, error) {
+	return newClient(false, opts...)
 }
 
 // NewSandboxClient returns a godax Client that is hooked up to the sandbox REST and web socket APIs.
-func NewSandboxClient() (*Client, error) {
-	return newClient(true)
+func NewSandboxClient(opts ...ClientOption) (*Client, error) {
+	return newClient(true, opts...)
+}
+
+// Credentials are the key, secret and passphrase of a coinbase pro API key.
+type Credentials struct {
+	Key        string
+	Secret     string
+	Passphrase string
+}
+
+// NewClientWithCredentials returns a godax Client for an API key that isn't in the environment, for
+// example when working with several profiles that each have their own key. Set sandbox to use the
+// sandbox REST and web socket APIs.
+func NewClientWithCredentials(creds Credentials, sandbox bool, opts ...ClientOption) (*Client, error) {
+	if creds.Key == "" || creds.Secret == "" || creds.Passphrase == "" {
+		return nil, ErrMissingCredentials
+	}
+	c := &Client{
+		httpClient: retryablehttp.NewClient().StandardClient(),
+	}
+	c.setCredentials(creds, sandbox)
+	if err := c.apply(opts); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 func unixTime() string {
@@ -113,14 +153,20 @@ func (c *Client) GetAccount(accountID string) (Account, error) {
 // GetAccountHistory lists account activity of the API key's profile. Account activity either increases
 // or decreases your account balance. If an entry is the result of a trade (match, fee), the details
 // field on an AccountActivity will contain additional information about the trade. Items are paginated
-// and sorted latest first. This endpoint requires either the "view" or "trade" permission.
-// TODO: paginate
+// and sorted latest first. This endpoint requires either the "view" or "trade" permission. Every page
+// is returned.
 func (c *Client) GetAccountHistory(accountID string) ([]AccountActivity, error) {
 	method := http.MethodGet
 	path := "/accounts/" + accountID + "/ledger"
 
-	var activities []AccountActivity
-	if err := c.exec(unixTime(), method, path, noBody, nil, &activities); err != nil {
+	activities := []AccountActivity{}
+	err := paginate(nil, func(page *QueryParams) (int, string, error) {
+		var a []AccountActivity
+		after, err := c.execPage(unixTime(), method, path, noBody, page, &a)
+		activities = append(activities, a...)
+		return len(a), after, err
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -248,14 +294,22 @@ func (c *Client) CancelAllOrders(qp QueryParams) (canceledOrderIDs []string, err
 // of open orders and use one of the streaming market data feeds to keep it updated. You should poll the open orders
 // endpoint once when you start trading to obtain the current state of any open orders. executed_value is the cumulative
 // match size * price and is only present for orders placed after 2016-05-20. Open orders may change state between the
-// request and the response depending on market conditions.
-// TODO: paginate
+// request and the response depending on market conditions. OrderTracker keeps such a list for you.
+//
+// ListOrders follows the pagination cursor and returns every page, unless you pass BeforeParam or AfterParam
+// yourself in which case only that one page is returned. LimitParam sets the page size (default 100, max 100).
 func (c *Client) ListOrders(qp QueryParams) ([]Order, error) {
 	method := http.MethodGet
 	path := "/orders"
 
-	var orders []Order
-	if err := c.exec(unixTime(), method, path, noBody, &qp, &orders); err != nil {
+	orders := []Order{}
+	err := paginate(qp, func(page *QueryParams) (int, string, error) {
+		var o []Order
+		after, err := c.execPage(unixTime(), method, path, noBody, page, &o)
+		orders = append(orders, o...)
+		return len(o), after, err
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -301,7 +355,8 @@ func (c *Client) GetOrderByClientOID(orderClientOID string) (Order, error) {
 // Fees are recorded in two stages. Immediately after the matching engine completes a match, the fill
 // is inserted into our datastore. Once the fill is recorded, a settlement process will settle the fill and credit
 // both trading counterparties. The fee field indicates the fees charged for this individual fill.
-// TODO: paginate
+//
+// Like ListOrders, ListFills returns every page unless you pass BeforeParam or AfterParam yourself.
 func (c *Client) ListFills(qp QueryParams) ([]Fill, error) {
 	if qp[ProductIDParam] == "" && qp[OrderIDParam] == "" {
 		return nil, ErrMissingOrderOrProductID
@@ -309,8 +364,14 @@ func (c *Client) ListFills(qp QueryParams) ([]Fill, error) {
 	method := http.MethodGet
 	path := "/fills"
 
-	var fills []Fill
-	if err := c.exec(unixTime(), method, path, noBody, &qp, &fills); err != nil {
+	fills := []Fill{}
+	err := paginate(qp, func(page *QueryParams) (int, string, error) {
+		var f []Fill
+		after, err := c.execPage(unixTime(), method, path, noBody, page, &f)
+		fills = append(fills, f...)
+		return len(f), after, err
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -567,7 +628,8 @@ func (c *Client) ListTradesByProduct(productID string) ([]Trade, error) {
 // as 300 candles and some of those candles may precede your declared start value. The maximum number of data
 // points for a single request is 300 candles. If your selection of start/end time and granularity will result
 // in more than 300 data points, your request will be rejected. If you wish to retrieve fine granularity data
-// over a larger time range, you will need to make multiple requests with new start/end ranges.
+// over a larger time range, you will need to make multiple requests with new start/end ranges, which
+// BackfillHistoricRates does for you. The Granularity constants can be used for the granularity param.
 func (c *Client) GetHistoricRatesForProduct(productID string, qp QueryParams) ([]HistoricRate, error) {
 	method := http.MethodGet
 	path := "/products/" + productID + "/candles"
@@ -0,0 +1,121 @@
+package godax
+
+import "fmt"
+
+// Hold types
+const (
+	HoldOrder    = "order"
+	HoldTransfer = "transfer"
+)
+
+// ExplainedHold is a hold along with what it is for.
+type ExplainedHold struct {
+	AccountHold
+
+	// Order is the order an order hold Refs, nil when GetOrderByID can't find it.
+	Order *Order
+
+	// TransferID is the withdrawal or transfer a transfer hold Refs.
+	TransferID string
+
+	// Stale is set for an order hold whose order isn't open anymore (done or not found). Coinbase
+	// releases such holds shortly after, so one that stays stale is worth raising.
+	Stale bool
+
+	// Reason says what the hold is for, for example "buy 0.01 BTC-USD at 10000 (open)".
+	Reason string
+}
+
+// HoldsExplanation is every hold on an account and what it is for.
+type HoldsExplanation struct {
+	AccountID string
+	Currency  string
+	Holds     []ExplainedHold
+
+	// Total is the sum of Holds, AccountHolds the Holds GetAccount reports, and Unexplained the part
+	// of AccountHolds the holds don't account for (negative when they add up to more).
+	Total        string
+	AccountHolds string
+	Unexplained  string
+}
+
+// Explained reports whether the holds add up to exactly what the account has on hold.
+func (e HoldsExplanation) Explained() bool {
+	u, err := parseDecimal(e.Unexplained)
+	return err == nil && u.Sign() == 0
+}
+
+// ExplainHolds resolves every hold on an account to what it is for. Order holds are looked up with
+// GetOrderByID, transfer holds are for the withdrawal or transfer in their Ref. The holds' total is
+// compared against the account's Holds so you can see why funds are locked.
+func ExplainHolds(accounts AccountsAPI, orders OrdersAPI, accountID string) (HoldsExplanation, error) {
+	account, err := accounts.GetAccount(accountID)
+	if err != nil {
+		return HoldsExplanation{}, err
+	}
+	return explainHolds(accounts, orders, account)
+}
+
+func explainHolds(accounts AccountsAPI, orders OrdersAPI, account Account) (HoldsExplanation, error) {
+	holds, err := accounts.GetAccountHolds(account.ID)
+	if err != nil {
+		return HoldsExplanation{}, err
+	}
+	e := HoldsExplanation{AccountID: account.ID, Currency: account.Currency, AccountHolds: account.Holds}
+	total := newRat()
+	for _, h := range holds {
+		amount, err := parseDecimal(h.Amount)
+		if err != nil {
+			return HoldsExplanation{}, err
+		}
+		total = add(total, amount)
+
+		x := ExplainedHold{AccountHold: h}
+		switch h.Type {
+		case HoldOrder:
+			o, err := orders.GetOrderByID(h.Ref)
+			if err != nil && !isNotFound(err) {
+				return HoldsExplanation{}, err
+			}
+			if err != nil {
+				x.Stale = true
+				x.Reason = "order " + h.Ref + " not found"
+				break
+			}
+			x.Order = &o
+			x.Stale = o.Status == orderStatusDone
+			x.Reason = describeOrder(o)
+		case HoldTransfer:
+			x.TransferID = h.Ref
+			x.Reason = "withdrawal or transfer " + h.Ref
+		default:
+			x.Reason = h.Type + " " + h.Ref
+		}
+		e.Holds = append(e.Holds, x)
+	}
+
+	accountHolds, err := parseDecimal(account.Holds)
+	if err != nil {
+		return HoldsExplanation{}, err
+	}
+	e.Total = formatDecimal(total)
+	e.Unexplained = formatDecimal(sub(accountHolds, total))
+	return e, nil
+}
+
+// describeOrder sums an order up in a few words.
+func describeOrder(o Order) string {
+	var d string
+	switch {
+	case o.Funds != "":
+		d = fmt.Sprintf("%s %s of %s", o.Side, o.Funds, o.ProductID)
+	case o.Price != "":
+		d = fmt.Sprintf("%s %s %s at %s", o.Side, o.Size, o.ProductID, o.Price)
+	default:
+		d = fmt.Sprintf("%s %s %s", o.Side, o.Size, o.ProductID)
+	}
+	if o.Type != "" {
+		d = o.Type + " " + d
+	}
+	return d + " (" + o.Status + ")"
+}
@@ -0,0 +1,61 @@
+package godax
+
+import "testing"
+
+func TestExplainHolds(t *testing.T) {
+	holds := []AccountHold{
+		{ID: "h1", Type: HoldOrder, Ref: "o1", Amount: "100.5"},
+		{ID: "h2", Type: HoldOrder, Ref: "o2", Amount: "20"},
+		{ID: "h3", Type: HoldTransfer, Ref: "t1", Amount: "50"},
+		{ID: "h4", Type: HoldOrder, Ref: "gone", Amount: "1"},
+	}
+	open := map[string]Order{
+		"o1": {ID: "o1", Status: "open", OrderParams: OrderParams{CommonOrderParams: CommonOrderParams{
+			Side: "buy", ProductID: "BTC-USD", Type: "limit", Price: "10050", Size: "0.01",
+		}}},
+		"o2": {ID: "o2", Status: "done", OrderParams: OrderParams{
+			CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "ETH-USD", Type: "market"},
+			MarketOrderParams: MarketOrderParams{Funds: "20"},
+		}},
+	}
+	account := Account{ID: "usd", Currency: "USD", Balance: "1000", Holds: "180"}
+	accounts, orders := ledgerFakes(account, nil, holds, nil, open)
+
+	e, err := ExplainHolds(accounts, orders, "usd")
+	if err != nil {
+		t.Fatalf("ExplainHolds() error = %v", err)
+	}
+	if e.Currency != "USD" || e.Total != "171.5" || e.AccountHolds != "180" || e.Unexplained != "8.5" || e.Explained() {
+		t.Errorf("ExplainHolds() = %+v, want 8.5 of 180 unexplained", e)
+	}
+	if len(e.Holds) != 4 {
+		t.Fatalf("Holds = %+v, want 4", e.Holds)
+	}
+
+	tests := []struct {
+		reason     string
+		orderID    string
+		transferID string
+		stale      bool
+	}{
+		{"limit buy 0.01 BTC-USD at 10050 (open)", "o1", "", false},
+		{"market buy 20 of ETH-USD (done)", "o2", "", true},
+		{"withdrawal or transfer t1", "", "t1", false},
+		{"order gone not found", "", "", true},
+	}
+	for i, tt := range tests {
+		h := e.Holds[i]
+		if h.Reason != tt.reason || h.TransferID != tt.transferID || h.Stale != tt.stale {
+			t.Errorf("hold %s = %+v, want %q", h.ID, h, tt.reason)
+		}
+		if (h.Order == nil) != (tt.orderID == "") || (h.Order != nil && h.Order.ID != tt.orderID) {
+			t.Errorf("hold %s order = %+v, want %q", h.ID, h.Order, tt.orderID)
+		}
+	}
+
+	account.Holds = "171.5"
+	accounts, orders = ledgerFakes(account, nil, holds, nil, open)
+	if e, _ := ExplainHolds(accounts, orders, "usd"); !e.Explained() {
+		t.Errorf("ExplainHolds() = %+v, want the holds to add up", e)
+	}
+}
@@ -0,0 +1,488 @@
+package godax
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrInvalidIndicator is returned when an indicator is given a period (or Bollinger band width) that
+// isn't positive, or MACD periods where the fast one isn't shorter than the slow one.
+var ErrInvalidIndicator = errors.New("please provide positive indicator periods, with the MACD fast period shorter than the slow one")
+
+// The indicators below compute on HistoricRates directly. Update one with each candle as it comes in,
+// or run a history (from GetHistoricRatesForProduct, a CandleSeries' Rates, ...) through Apply first
+// to warm it up. Updates are expected oldest first. A candle with the same Time as the last one
+// replaces it, so the in progress candles a CandleBuilder emits can be fed in as they change, and a
+// candle older than the last one is ignored.
+//
+// Until an indicator has seen enough candles its Update reports false, and Apply returns NaN.
+
+// SMA is the simple moving average of the closes.
+type SMA struct {
+	clock     candleClock
+	cur, prev window
+}
+
+// NewSMA creates an SMA over period candles.
+func NewSMA(period int) (*SMA, error) {
+	if period <= 0 {
+		return nil, ErrInvalidIndicator
+	}
+	return &SMA{cur: window{size: period}}, nil
+}
+
+// Update adds a candle and returns the average.
+func (s *SMA) Update(r HistoricRate) (float64, bool) {
+	step := s.clock.step(r.Time)
+	switch step {
+	case candleNew:
+		s.prev = s.cur
+	case candleSame:
+		s.cur = s.prev
+	}
+	if step != candleOld {
+		s.cur = s.cur.push(r.Close)
+	}
+	return s.cur.mean(), s.cur.full()
+}
+
+// Apply updates the SMA with every rate and returns the average after each of them, oldest first.
+func (s *SMA) Apply(rates []HistoricRate) []float64 {
+	return applyIndicator(rates, s.Update)
+}
+
+// EMA is the exponential moving average of the closes, seeded with the SMA of the first period closes.
+type EMA struct {
+	period    int
+	alpha     float64
+	clock     candleClock
+	cur, prev emaState
+}
+
+type emaState struct {
+	count int
+	value float64
+}
+
+// NewEMA creates an EMA over period candles, weighting each new close by 2 / (period + 1).
+func NewEMA(period int) (*EMA, error) {
+	if period <= 0 {
+		return nil, ErrInvalidIndicator
+	}
+	return &EMA{period: period, alpha: 2 / float64(period+1)}, nil
+}
+
+// Update adds a candle and returns the average.
+func (e *EMA) Update(r HistoricRate) (float64, bool) {
+	step := e.clock.step(r.Time)
+	switch step {
+	case candleNew:
+		e.prev = e.cur
+	case candleSame:
+		e.cur = e.prev
+	}
+	if step != candleOld {
+		e.cur.count++
+		switch {
+		case e.cur.count < e.period:
+			e.cur.value += r.Close
+		case e.cur.count == e.period:
+			e.cur.value = (e.cur.value + r.Close) / float64(e.period)
+		default:
+			e.cur.value += e.alpha * (r.Close - e.cur.value)
+		}
+	}
+	return e.cur.value, e.cur.count >= e.period
+}
+
+// Apply updates the EMA with every rate and returns the average after each of them, oldest first.
+func (e *EMA) Apply(rates []HistoricRate) []float64 {
+	return applyIndicator(rates, e.Update)
+}
+
+// RSI is Wilder's relative strength index of the closes, from 0 to 100.
+type RSI struct {
+	period    int
+	clock     candleClock
+	cur, prev rsiState
+}
+
+type rsiState struct {
+	closes    int
+	prevClose float64
+	gain      float64
+	loss      float64
+}
+
+// NewRSI creates an RSI over period changes in close, so it is ready after period + 1 candles.
+func NewRSI(period int) (*RSI, error) {
+	if period <= 0 {
+		return nil, ErrInvalidIndicator
+	}
+	return &RSI{period: period}, nil
+}
+
+// Update adds a candle and returns the RSI.
+func (r *RSI) Update(rate HistoricRate) (float64, bool) {
+	step := r.clock.step(rate.Time)
+	switch step {
+	case candleNew:
+		r.prev = r.cur
+	case candleSame:
+		r.cur = r.prev
+	}
+	if step != candleOld {
+		r.update(rate.Close)
+	}
+	return r.value()
+}
+
+func (r *RSI) update(close float64) {
+	st := &r.cur
+	st.closes++
+	change := close - st.prevClose
+	st.prevClose = close
+	if st.closes == 1 {
+		return
+	}
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+	n := float64(r.period)
+	if st.closes <= r.period+1 {
+		// the first averages are simple ones
+		st.gain += gain / n
+		st.loss += loss / n
+		return
+	}
+	st.gain = (st.gain*(n-1) + gain) / n
+	st.loss = (st.loss*(n-1) + loss) / n
+}
+
+func (r *RSI) value() (float64, bool) {
+	st := r.cur
+	if st.closes <= r.period {
+		return 0, false
+	}
+	switch {
+	case st.loss == 0 && st.gain == 0:
+		return 50, true
+	case st.loss == 0:
+		return 100, true
+	}
+	return 100 - 100/(1+st.gain/st.loss), true
+}
+
+// Apply updates the RSI with every rate and returns the RSI after each of them, oldest first.
+func (r *RSI) Apply(rates []HistoricRate) []float64 {
+	return applyIndicator(rates, r.Update)
+}
+
+// MACDValue is one reading of a MACD.
+type MACDValue struct {
+	// MACD is the fast EMA minus the slow EMA, Signal an EMA of MACD and Histogram MACD - Signal.
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACD is the moving average convergence divergence of the closes.
+type MACD struct {
+	fast, slow, signal *EMA
+}
+
+// NewMACD creates a MACD from fast and slow period EMAs of the closes and a signal period EMA of the
+// MACD line, 12, 26 and 9 being the usual ones.
+func NewMACD(fast, slow, signal int) (*MACD, error) {
+	if fast <= 0 || slow <= fast || signal <= 0 {
+		return nil, ErrInvalidIndicator
+	}
+	m := &MACD{}
+	m.fast, _ = NewEMA(fast)
+	m.slow, _ = NewEMA(slow)
+	m.signal, _ = NewEMA(signal)
+	return m, nil
+}
+
+// Update adds a candle and returns the MACD. It is ready once the signal line is, after
+// slow + signal - 1 candles.
+func (m *MACD) Update(r HistoricRate) (MACDValue, bool) {
+	fast, _ := m.fast.Update(r)
+	slow, ok := m.slow.Update(r)
+	if !ok {
+		return MACDValue{}, false
+	}
+	macd := fast - slow
+	signal, ok := m.signal.Update(HistoricRate{Time: r.Time, Close: macd})
+	return MACDValue{MACD: macd, Signal: signal, Histogram: macd - signal}, ok
+}
+
+// Apply updates the MACD with every rate and returns the MACD after each of them, oldest first. Not
+// ready readings are NaN.
+func (m *MACD) Apply(rates []HistoricRate) []MACDValue {
+	nan := math.NaN()
+	sorted := sortedRates(rates)
+	out := make([]MACDValue, len(sorted))
+	for i, r := range sorted {
+		v, ok := m.Update(r)
+		if !ok {
+			v = MACDValue{MACD: nan, Signal: nan, Histogram: nan}
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// BollingerValue is one reading of Bollinger Bands.
+type BollingerValue struct {
+	Middle float64
+	Upper  float64
+	Lower  float64
+}
+
+// BollingerBands are the SMA of the closes with bands a number of standard deviations above and below.
+type BollingerBands struct {
+	width     float64
+	clock     candleClock
+	cur, prev window
+}
+
+// NewBollingerBands creates Bollinger Bands over period candles, width (population) standard
+// deviations from the middle, 20 and 2 being the usual ones.
+func NewBollingerBands(period int, width float64) (*BollingerBands, error) {
+	if period <= 0 || width <= 0 {
+		return nil, ErrInvalidIndicator
+	}
+	return &BollingerBands{width: width, cur: window{size: period}}, nil
+}
+
+// Update adds a candle and returns the bands.
+func (b *BollingerBands) Update(r HistoricRate) (BollingerValue, bool) {
+	step := b.clock.step(r.Time)
+	switch step {
+	case candleNew:
+		b.prev = b.cur
+	case candleSame:
+		b.cur = b.prev
+	}
+	if step != candleOld {
+		b.cur = b.cur.push(r.Close)
+	}
+	mean := b.cur.mean()
+	band := b.width * b.cur.stddev(mean)
+	return BollingerValue{Middle: mean, Upper: mean + band, Lower: mean - band}, b.cur.full()
+}
+
+// Apply updates the bands with every rate and returns the bands after each of them, oldest first.
+// Not ready readings are NaN.
+func (b *BollingerBands) Apply(rates []HistoricRate) []BollingerValue {
+	nan := math.NaN()
+	sorted := sortedRates(rates)
+	out := make([]BollingerValue, len(sorted))
+	for i, r := range sorted {
+		v, ok := b.Update(r)
+		if !ok {
+			v = BollingerValue{Middle: nan, Upper: nan, Lower: nan}
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// ATR is Wilder's average true range.
+type ATR struct {
+	period    int
+	clock     candleClock
+	cur, prev atrState
+}
+
+type atrState struct {
+	count     int
+	prevClose float64
+	value     float64
+}
+
+// NewATR creates an ATR over period candles.
+func NewATR(period int) (*ATR, error) {
+	if period <= 0 {
+		return nil, ErrInvalidIndicator
+	}
+	return &ATR{period: period}, nil
+}
+
+// Update adds a candle and returns the ATR.
+func (a *ATR) Update(r HistoricRate) (float64, bool) {
+	step := a.clock.step(r.Time)
+	switch step {
+	case candleNew:
+		a.prev = a.cur
+	case candleSame:
+		a.cur = a.prev
+	}
+	if step != candleOld {
+		st := &a.cur
+		tr := r.High - r.Low
+		if st.count > 0 {
+			tr = math.Max(tr, math.Max(math.Abs(r.High-st.prevClose), math.Abs(r.Low-st.prevClose)))
+		}
+		st.count++
+		st.prevClose = r.Close
+		n := float64(a.period)
+		if st.count <= a.period {
+			st.value += tr / n
+		} else {
+			st.value = (st.value*(n-1) + tr) / n
+		}
+	}
+	return a.cur.value, a.cur.count >= a.period
+}
+
+// Apply updates the ATR with every rate and returns the ATR after each of them, oldest first.
+func (a *ATR) Apply(rates []HistoricRate) []float64 {
+	return applyIndicator(rates, a.Update)
+}
+
+// SessionVWAP is the volume weighted average of each candle's typical price, (high + low + close) / 3,
+// since it was created or last Reset. For the execution algorithm see NewVWAP.
+type SessionVWAP struct {
+	clock     candleClock
+	cur, prev vwapState
+}
+
+type vwapState struct {
+	priceVolume float64
+	volume      float64
+}
+
+// NewSessionVWAP creates a SessionVWAP.
+func NewSessionVWAP() *SessionVWAP {
+	return &SessionVWAP{}
+}
+
+// Update adds a candle and returns the VWAP. It is ready once there has been some volume.
+func (v *SessionVWAP) Update(r HistoricRate) (float64, bool) {
+	step := v.clock.step(r.Time)
+	switch step {
+	case candleNew:
+		v.prev = v.cur
+	case candleSame:
+		v.cur = v.prev
+	}
+	if step != candleOld {
+		v.cur.priceVolume += (r.High + r.Low + r.Close) / 3 * r.Volume
+		v.cur.volume += r.Volume
+	}
+	if v.cur.volume == 0 {
+		return 0, false
+	}
+	return v.cur.priceVolume / v.cur.volume, true
+}
+
+// Reset starts the VWAP over, at the start of a new session say.
+func (v *SessionVWAP) Reset() {
+	*v = SessionVWAP{}
+}
+
+// Apply updates the VWAP with every rate and returns the VWAP after each of them, oldest first.
+func (v *SessionVWAP) Apply(rates []HistoricRate) []float64 {
+	return applyIndicator(rates, v.Update)
+}
+
+// candleClock tracks the time of the last candle an indicator saw.
+type candleClock struct {
+	time    float64
+	started bool
+}
+
+type candleStep int
+
+const (
+	// candleNew is a candle after the last one. Indicators save their state before adding it.
+	candleNew candleStep = iota
+
+	// candleSame is the last candle again. Indicators roll back to the state saved before it and add
+	// it again.
+	candleSame
+
+	// candleOld is a candle before the last one, which indicators ignore.
+	candleOld
+)
+
+func (c *candleClock) step(t float64) candleStep {
+	switch {
+	case !c.started || t > c.time:
+		c.started, c.time = true, t
+		return candleNew
+	case t == c.time:
+		return candleSame
+	}
+	return candleOld
+}
+
+// window is the last size values. push copies rather than appending in place, so a window saved
+// before a candle isn't changed by adding it.
+type window struct {
+	size   int
+	values []float64
+}
+
+func (w window) push(x float64) window {
+	start := 0
+	if len(w.values) == w.size {
+		start = 1
+	}
+	values := make([]float64, 0, w.size)
+	w.values = append(append(values, w.values[start:]...), x)
+	return w
+}
+
+func (w window) full() bool {
+	return len(w.values) == w.size
+}
+
+func (w window) mean() float64 {
+	if len(w.values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range w.values {
+		sum += x
+	}
+	return sum / float64(len(w.values))
+}
+
+func (w window) stddev(mean float64) float64 {
+	if len(w.values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range w.values {
+		sum += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sum / float64(len(w.values)))
+}
+
+// applyIndicator runs rates through update oldest first, NaN standing in for the values that
+// aren't ready.
+func applyIndicator(rates []HistoricRate, update func(HistoricRate) (float64, bool)) []float64 {
+	sorted := sortedRates(rates)
+	out := make([]float64, len(sorted))
+	for i, r := range sorted {
+		v, ok := update(r)
+		if !ok {
+			v = math.NaN()
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// sortedRates returns rates oldest first. GetHistoricRatesForProduct returns them newest first.
+func sortedRates(rates []HistoricRate) []HistoricRate {
+	if sort.SliceIsSorted(rates, func(i, j int) bool { return rates[i].Time < rates[j].Time }) {
+		return rates
+	}
+	sorted := make([]HistoricRate, len(rates))
+	copy(sorted, rates)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	return sorted
+}
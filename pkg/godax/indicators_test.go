@@ -0,0 +1,156 @@
+package godax
+
+import (
+	"math"
+	"testing"
+)
+
+func closeRates(cs ...float64) []HistoricRate {
+	rates := make([]HistoricRate, len(cs))
+	for i, c := range cs {
+		rates[i] = HistoricRate{Time: float64(60 * (i + 1)), Open: c, High: c, Low: c, Close: c, Volume: 1}
+	}
+	return rates
+}
+
+func sameFloats(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || (!math.IsNaN(want[i]) && math.Abs(got[i]-want[i]) > 1e-9) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIndicators_Apply(t *testing.T) {
+	nan := math.NaN()
+	sma, _ := NewSMA(3)
+	ema, _ := NewEMA(3)
+	rsi, _ := NewRSI(2)
+
+	tests := []struct {
+		name  string
+		apply func([]HistoricRate) []float64
+		rates []HistoricRate
+		want  []float64
+	}{
+		{"sma", sma.Apply, closeRates(1, 2, 3, 4, 5), []float64{nan, nan, 2, 3, 4}},
+		{"ema", ema.Apply, closeRates(1, 2, 3, 4, 5), []float64{nan, nan, 2, 3, 4}},
+		{"rsi", rsi.Apply, closeRates(1, 2, 3, 2), []float64{nan, nan, 100, 50}},
+	}
+	for _, tt := range tests {
+		if got := tt.apply(tt.rates); !sameFloats(got, tt.want) {
+			t.Errorf("%s Apply() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestATR(t *testing.T) {
+	atr, err := NewATR(2)
+	if err != nil {
+		t.Fatalf("NewATR() error = %v", err)
+	}
+	rates := []HistoricRate{
+		{Time: 60, High: 10, Low: 8, Close: 9},
+		{Time: 120, High: 11, Low: 9, Close: 10},
+		{Time: 180, High: 12, Low: 9, Close: 11},
+		{Time: 240, High: 10, Low: 9, Close: 9},
+	}
+	// true ranges of 2, 2, 3 and 2 (the gap down from 11)
+	if got, want := atr.Apply(rates), []float64{math.NaN(), 2, 2.5, 2.25}; !sameFloats(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	m, err := NewMACD(2, 3, 2)
+	if err != nil {
+		t.Fatalf("NewMACD() error = %v", err)
+	}
+	got := m.Apply(closeRates(1, 2, 3, 4, 5, 6))
+	for i, v := range got {
+		if ready := i >= 3; ready == math.IsNaN(v.Signal) {
+			t.Errorf("reading %d = %+v, want ready %v", i, v, ready)
+		}
+	}
+	// on a straight line the fast EMA lags by 0.5 and the slow one by 1
+	if last := got[5]; last.MACD != 0.5 || last.Signal != 0.5 || last.Histogram != 0 {
+		t.Errorf("last reading = %+v, want a flat 0.5", last)
+	}
+
+	if _, err := NewMACD(26, 12, 9); err != ErrInvalidIndicator {
+		t.Errorf("NewMACD() with fast over slow error = %v, want ErrInvalidIndicator", err)
+	}
+}
+
+func TestBollingerBands(t *testing.T) {
+	b, err := NewBollingerBands(3, 2)
+	if err != nil {
+		t.Fatalf("NewBollingerBands() error = %v", err)
+	}
+	got := b.Apply(closeRates(5, 1, 2, 3))
+	if !math.IsNaN(got[1].Middle) {
+		t.Errorf("reading 1 = %+v, want NaN", got[1])
+	}
+	band := 2 * math.Sqrt(2.0/3)
+	if last := got[3]; last.Middle != 2 || math.Abs(last.Upper-(2+band)) > 1e-9 || math.Abs(last.Lower-(2-band)) > 1e-9 {
+		t.Errorf("last reading = %+v, want 2 +/- %v", last, band)
+	}
+
+	if _, err := NewBollingerBands(20, 0); err != ErrInvalidIndicator {
+		t.Errorf("NewBollingerBands() error = %v, want ErrInvalidIndicator", err)
+	}
+}
+
+func TestSessionVWAP(t *testing.T) {
+	v := NewSessionVWAP()
+	got := v.Apply([]HistoricRate{
+		{Time: 60, High: 12, Low: 6, Close: 9, Volume: 1},
+		{Time: 120, High: 15, Low: 9, Close: 12, Volume: 2},
+	})
+	if want := []float64{9, 11}; !sameFloats(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+	v.Reset()
+	if _, ok := v.Update(HistoricRate{Time: 180, Close: 10}); ok {
+		t.Errorf("Update() after Reset without volume is ready")
+	}
+}
+
+func TestIndicators_LiveCandles(t *testing.T) {
+	sma, _ := NewSMA(2)
+	rsi, _ := NewRSI(1)
+	updates := []struct {
+		time, close float64
+		sma         float64
+	}{
+		{60, 1, 1},
+		{120, 3, 2},
+		// the live candle moves, replacing the last close
+		{120, 5, 3},
+		{180, 7, 6},
+		// a late candle is ignored
+		{120, 100, 6},
+	}
+	for _, u := range updates {
+		r := HistoricRate{Time: u.time, Close: u.close}
+		if got, _ := sma.Update(r); got != u.sma {
+			t.Errorf("SMA Update(%v, %v) = %v, want %v", u.time, u.close, got, u.sma)
+		}
+		rsi.Update(r)
+	}
+	if got, ok := rsi.Update(HistoricRate{Time: 180, Close: 4}); !ok || got != 0 {
+		t.Errorf("RSI after replacing a rise with a drop = %v, want 0", got)
+	}
+
+	// newest first, as GetHistoricRatesForProduct returns them
+	rates := closeRates(1, 2, 3, 4)
+	reversed := []HistoricRate{rates[3], rates[2], rates[1], rates[0]}
+	ema, _ := NewEMA(2)
+	if got, want := ema.Apply(reversed), []float64{math.NaN(), 1.5, 2.5, 3.5}; !sameFloats(got, want) {
+		t.Errorf("EMA Apply() newest first = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package godax
+
+//go:generate go run ./internal/genfakes -in interfaces.go -out fakes.go
+
+// The interfaces below group the Client's exported methods by domain. Depend on the narrowest
+// one you need so a fake (see fakes.go) or another implementation can be swapped in for *Client.
+
+// MarketDataAPI describes the public market data endpoints.
+type MarketDataAPI interface {
+	ListProducts() ([]Product, error)
+	GetProductByID(productID string) (Product, error)
+	GetProductOrderBook(productID string, qp QueryParams) (OrderBook, error)
+	GetProductTicker(productID string) (Ticker, error)
+	ListTradesByProduct(productID string) ([]Trade, error)
+	GetHistoricRatesForProduct(productID string, qp QueryParams) ([]HistoricRate, error)
+	Get24HourStatsForProduct(productID string) (DayStat, error)
+	ListCurrencies() ([]Currency, error)
+	GetServerTime() (ServerTime, error)
+	GetOracle() (Oracle, error)
+}
+
+// AccountsAPI describes the trading account endpoints, along with the user level fee and volume lookups.
+type AccountsAPI interface {
+	ListAccounts() ([]ListAccount, error)
+	GetAccount(accountID string) (Account, error)
+	GetAccountHistory(accountID string) ([]AccountActivity, error)
+	GetAccountHolds(accountID string) ([]AccountHold, error)
+	GetCurrentFees() (Fees, error)
+	GetTrailingVolume() ([]UserAccount, error)
+}
+
+// OrdersAPI describes placing, canceling and looking up orders and their fills.
+type OrdersAPI interface {
+	PlaceOrder(order OrderParams) (Order, error)
+	CancelOrderByID(orderID string, qp QueryParams) error
+	CancelOrderByClientOID(clientOID string, qp QueryParams) (string, error)
+	CancelAllOrders(qp QueryParams) ([]string, error)
+	ListOrders(qp QueryParams) ([]Order, error)
+	GetOrderByID(orderID string) (Order, error)
+	GetOrderByClientOID(orderClientOID string) (Order, error)
+	ListFills(qp QueryParams) ([]Fill, error)
+}
+
+// FundingAPI describes moving funds in and out of coinbase pro and the limits around doing so.
+type FundingAPI interface {
+	StableCoinConversion(from string, to string, amount string) (Conversion, error)
+	ListPaymentMethods() ([]PaymentMethod, error)
+	ListCoinbaseAccounts() ([]CoinbaseAccount, error)
+	GetCurrentExchangeLimits() (ExchangeLimit, error)
+}
+
+// ProfilesAPI describes the profile (portfolio) endpoints.
+type ProfilesAPI interface {
+	ListProfiles() ([]Profile, error)
+	GetProfile(profileID string) (Profile, error)
+	ProfileTransfer(transfer TransferParams) error
+}
+
+// ReportsAPI describes creating reports and checking on their status.
+type ReportsAPI interface {
+	CreateReport(report ReportParams) (ReportStatus, error)
+	GetReportStatus(reportID string) (ReportStatus, error)
+}
+
+// MarginAPI describes the margin endpoints.
+type MarginAPI interface {
+	GetMarginProfile(qp QueryParams) (MarginProfile, error)
+	GetBuyingPower(qp QueryParams) (BuyingPower, error)
+	GetWithdrawalPowerForCurrency(qp QueryParams) ([]CurrencyWithdrawalPower, error)
+	GetAllWithdrawalPower() ([]AllWithdrawalPower, error)
+	GetMarginExitPlan() (ExitPlan, error)
+	ListLiquidationHistory(qp QueryParams) ([]LiquidationEvent, error)
+	GetPositionRefreshAmounts(qp QueryParams) (RefreshAmount, error)
+	GetMarginStatus() (MarginStatus, error)
+}
+
+// API is the full surface of a godax Client.
+type API interface {
+	MarketDataAPI
+	AccountsAPI
+	OrdersAPI
+	FundingAPI
+	ProfilesAPI
+	ReportsAPI
+	MarginAPI
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,43 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFakeAPI(t *testing.T) {
+	t.Run("stubbed methods return what their Func returns", func(t *testing.T) {
+		var gotProductID string
+		fake := &FakeAPI{}
+		fake.GetProductTickerFunc = func(productID string) (Ticker, error) {
+			gotProductID = productID
+			return Ticker{Price: "9000.00"}, nil
+		}
+
+		var md MarketDataAPI = fake
+		got, err := md.GetProductTicker("BTC-USD")
+		if err != nil {
+			t.Fatalf("GetProductTicker() error = %v", err)
+		}
+		if gotProductID != "BTC-USD" {
+			t.Errorf("GetProductTickerFunc received productID %s, want BTC-USD", gotProductID)
+		}
+		if !reflect.DeepEqual(got, Ticker{Price: "9000.00"}) {
+			t.Errorf("GetProductTicker() = %v, want price 9000.00", got)
+		}
+	})
+
+	t.Run("unstubbed methods return zero values and ErrNotStubbed", func(t *testing.T) {
+		var orders OrdersAPI = &FakeAPI{}
+		got, err := orders.ListOrders(QueryParams{})
+		if err != ErrNotStubbed {
+			t.Errorf("ListOrders() error = %v, want ErrNotStubbed", err)
+		}
+		if got != nil {
+			t.Errorf("ListOrders() = %v, want nil", got)
+		}
+		if err := (&FakeProfilesAPI{}).ProfileTransfer(TransferParams{}); err != ErrNotStubbed {
+			t.Errorf("ProfileTransfer() error = %v, want ErrNotStubbed", err)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+// Command genfakes generates function backed fakes for every interface declared in a file.
+// An interface made up of methods gets a Fake<Name> struct with a <Method>Func field per
+// method. An interface made up only of embedded interfaces gets a Fake<Name> struct that
+// embeds the fakes of its parts. Run it through `go generate` from pkg/godax.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "the go file declaring the interfaces")
+	out := flag.String("out", "", "the go file to write the fakes to")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		log.Fatal("please provide both -in and -out")
+	}
+
+	src, err := generate(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(path string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by genfakes from %s. DO NOT EDIT.\n\n", filepath.Base(path))
+	fmt.Fprintf(&buf, "package %s\n\n", file.Name.Name)
+	buf.WriteString("import \"errors\"\n\n")
+	buf.WriteString("// ErrNotStubbed is returned by a fake when the method called on it has no Func set.\n")
+	buf.WriteString("var ErrNotStubbed = errors.New(\"this fake method has not been stubbed\")\n")
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			iface, ok := ts.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			if err := writeFake(&buf, fset, ts.Name.Name, iface); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func writeFake(buf *bytes.Buffer, fset *token.FileSet, name string, iface *ast.InterfaceType) error {
+	fake := "Fake" + name
+
+	var embeds []string
+	var methods []*ast.Field
+	for _, f := range iface.Methods.List {
+		if len(f.Names) == 0 {
+			embeds = append(embeds, "Fake"+expr(fset, f.Type))
+			continue
+		}
+		methods = append(methods, f)
+	}
+
+	fmt.Fprintf(buf, "\n// %s is a fake %s. ", fake, name)
+	if len(embeds) > 0 {
+		buf.WriteString("Set the Func fields on the embedded fakes to stub behavior.\n")
+	} else {
+		buf.WriteString("Set a method's Func field to stub it, unset methods return ErrNotStubbed.\n")
+	}
+	fmt.Fprintf(buf, "type %s struct {\n", fake)
+	for _, e := range embeds {
+		fmt.Fprintf(buf, "%s\n", e)
+	}
+	for _, m := range methods {
+		fmt.Fprintf(buf, "%sFunc %s\n", m.Names[0].Name, signature(fset, m.Type.(*ast.FuncType), true))
+	}
+	buf.WriteString("}\n")
+
+	for _, m := range methods {
+		if err := writeMethod(buf, fset, fake, m.Names[0].Name, m.Type.(*ast.FuncType)); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(buf, "\nvar _ %s = (*%s)(nil)\n", name, fake)
+
+	return nil
+}
+
+func writeMethod(buf *bytes.Buffer, fset *token.FileSet, fake, method string, fn *ast.FuncType) error {
+	results := fieldTypes(fset, fn.Results)
+	if len(results) == 0 || results[len(results)-1] != "error" {
+		return fmt.Errorf("%s: every faked method must return an error last", method)
+	}
+
+	args := fieldNames(fn.Params)
+
+	fmt.Fprintf(buf, "\n// %s calls %sFunc.\n", method, method)
+	fmt.Fprintf(buf, "func (f *%s) %s%s {\n", fake, method, signature(fset, fn, false))
+	fmt.Fprintf(buf, "if f.%sFunc == nil {\n", method)
+	var zeros []string
+	for i, r := range results[:len(results)-1] {
+		z := fmt.Sprintf("r%d", i)
+		fmt.Fprintf(buf, "var %s %s\n", z, r)
+		zeros = append(zeros, z)
+	}
+	fmt.Fprintf(buf, "return %s\n", strings.Join(append(zeros, "ErrNotStubbed"), ", "))
+	buf.WriteString("}\n")
+	fmt.Fprintf(buf, "return f.%sFunc(%s)\n", method, strings.Join(args, ", "))
+	buf.WriteString("}\n")
+
+	return nil
+}
+
+// signature renders a method's params and results. When asFunc is true the
+// result is a func type suitable for a struct field.
+func signature(fset *token.FileSet, fn *ast.FuncType, asFunc bool) string {
+	var params []string
+	names := fieldNames(fn.Params)
+	for i, t := range fieldTypes(fset, fn.Params) {
+		params = append(params, names[i]+" "+t)
+	}
+
+	sig := "(" + strings.Join(params, ", ") + ")"
+	if asFunc {
+		sig = "func" + sig
+	}
+
+	results := fieldTypes(fset, fn.Results)
+	switch len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+
+	return sig
+}
+
+func fieldNames(fl *ast.FieldList) []string {
+	var names []string
+	if fl == nil {
+		return names
+	}
+	for i, f := range fl.List {
+		if len(f.Names) == 0 {
+			names = append(names, fmt.Sprintf("p%d", i))
+			continue
+		}
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+func fieldTypes(fset *token.FileSet, fl *ast.FieldList) []string {
+	var types []string
+	if fl == nil {
+		return types
+	}
+	for _, f := range fl.List {
+		t := expr(fset, f.Type)
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func expr(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, e)
+	return buf.String()
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestGenerate_FakesAreUpToDate(t *testing.T) {
+	got, err := generate("../../interfaces.go")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	want, err := ioutil.ReadFile("../../fakes.go")
+	if err != nil {
+		t.Fatalf("reading fakes.go: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("fakes.go is out of date with interfaces.go, please run go generate ./...")
+	}
+}
@@ -0,0 +1,328 @@
+package godax
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Ledger discrepancy kinds
+const (
+	// DiscrepancyBalance is an entry whose Balance isn't the previous balance plus its Amount.
+	DiscrepancyBalance = "balance"
+
+	// DiscrepancyAccountBalance is an account whose balance isn't the balance of its latest entry.
+	DiscrepancyAccountBalance = "account_balance"
+
+	// DiscrepancyEntryType is an entry of a type the reconciler doesn't know.
+	DiscrepancyEntryType = "entry_type"
+
+	// DiscrepancyMissingFill is a match or fee entry with no fill for its trade and order.
+	DiscrepancyMissingFill = "missing_fill"
+
+	// DiscrepancyMissingEntry is a fill with no match or fee entry for it in the account.
+	DiscrepancyMissingEntry = "missing_entry"
+
+	// DiscrepancyFillAmount is a match or fee entry whose amount doesn't agree with its fill.
+	DiscrepancyFillAmount = "fill_amount"
+
+	// DiscrepancyHold is a hold whose Ref doesn't point at an open order.
+	DiscrepancyHold = "hold"
+
+	// DiscrepancyHoldTotal is an account whose holds don't add up to its Holds.
+	DiscrepancyHoldTotal = "hold_total"
+)
+
+// ledgerEntryTypes are the entry types a ledger replay knows about.
+var ledgerEntryTypes = map[string]bool{
+	"transfer":   true,
+	"match":      true,
+	"fee":        true,
+	"rebate":     true,
+	"conversion": true,
+}
+
+// LedgerDiscrepancy is one problem found by ReconcileLedger. Which IDs are set depends on Kind.
+type LedgerDiscrepancy struct {
+	Kind string
+
+	// EntryID is the ledger entry, HoldID the hold the discrepancy is about.
+	EntryID string
+	HoldID  string
+
+	TradeID   string
+	OrderID   string
+	ProductID string
+
+	// Expected is what the reconciler worked out, Actual what the API reported.
+	Expected string
+	Actual   string
+
+	Detail string
+}
+
+// LedgerReport is the result of reconciling an account's ledger.
+type LedgerReport struct {
+	AccountID string
+	Currency  string
+
+	// Entries is how many ledger entries were replayed.
+	Entries int
+
+	// OpeningBalance is the balance before the oldest entry, ClosingBalance the balance after the
+	// latest one, and AccountBalance the balance GetAccount reports.
+	OpeningBalance string
+	ClosingBalance string
+	AccountBalance string
+
+	// Holds is the sum of the account's holds, AccountHolds what GetAccount reports.
+	Holds        string
+	AccountHolds string
+
+	Discrepancies []LedgerDiscrepancy
+}
+
+// OK reports whether the account reconciled without any discrepancies.
+func (r LedgerReport) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// ReconcileLedger audits an account. It replays the account's GetAccountHistory entries oldest first
+// and checks each running balance against the entry's Balance, and the final one against the
+// account's balance. Match and fee entries are cross-checked against ListFills of their products by
+// trade and order ID, in both directions, and their amounts against the fill's size, price and fee.
+// Finally every order hold from GetAccountHolds must Ref an open order, and the holds must add up to
+// the account's Holds.
+func ReconcileLedger(accounts AccountsAPI, orders OrdersAPI, accountID string) (LedgerReport, error) {
+	account, err := accounts.GetAccount(accountID)
+	if err != nil {
+		return LedgerReport{}, err
+	}
+	history, err := accounts.GetAccountHistory(accountID)
+	if err != nil {
+		return LedgerReport{}, err
+	}
+	r := &LedgerReport{
+		AccountID:      accountID,
+		Currency:       account.Currency,
+		Entries:        len(history),
+		AccountBalance: account.Balance,
+		AccountHolds:   account.Holds,
+	}
+
+	// the ledger comes newest first
+	replay := make([]AccountActivity, len(history))
+	for i, a := range history {
+		replay[len(history)-1-i] = a
+	}
+	if err := r.replay(replay, account); err != nil {
+		return LedgerReport{}, err
+	}
+	if err := r.crossCheckFills(orders, replay); err != nil {
+		return LedgerReport{}, err
+	}
+	if err := r.checkHolds(accounts, orders, account); err != nil {
+		return LedgerReport{}, err
+	}
+	return *r, nil
+}
+
+func (r *LedgerReport) add(d LedgerDiscrepancy) {
+	r.Discrepancies = append(r.Discrepancies, d)
+}
+
+func (r *LedgerReport) replay(entries []AccountActivity, account Account) error {
+	running := newRat()
+	for i, a := range entries {
+		amount, err := parseDecimal(a.Amount)
+		if err != nil {
+			return err
+		}
+		balance, err := parseDecimal(a.Balance)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			running = sub(balance, amount)
+			r.OpeningBalance = formatDecimal(running)
+		}
+		if !ledgerEntryTypes[a.Type] {
+			r.add(LedgerDiscrepancy{Kind: DiscrepancyEntryType, EntryID: a.ID, Actual: a.Type})
+		}
+		running = add(running, amount)
+		if running.Cmp(balance) != 0 {
+			r.add(LedgerDiscrepancy{
+				Kind:     DiscrepancyBalance,
+				EntryID:  a.ID,
+				Expected: formatDecimal(running),
+				Actual:   a.Balance,
+				Detail:   fmt.Sprintf("%s of %s", a.Type, a.Amount),
+			})
+			// carry on from the reported balance so one bad entry is reported once
+			running = balance
+		}
+	}
+	r.ClosingBalance = formatDecimal(running)
+
+	accountBalance, err := parseDecimal(account.Balance)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && running.Cmp(accountBalance) != 0 {
+		r.add(LedgerDiscrepancy{Kind: DiscrepancyAccountBalance, Expected: r.ClosingBalance, Actual: account.Balance})
+	}
+	return nil
+}
+
+// crossCheckFills matches match and fee entries to the fills of their products.
+func (r *LedgerReport) crossCheckFills(orders OrdersAPI, entries []AccountActivity) error {
+	type entryKey struct {
+		fillKey
+		fee bool
+	}
+	seen := make(map[entryKey]bool)
+	var products []string
+	byProduct := make(map[string][]Fill)
+	fills := make(map[fillKey]Fill)
+
+	for _, a := range entries {
+		if a.Type != "match" && a.Type != "fee" {
+			continue
+		}
+		productID := a.Details.ProductID
+		if _, ok := byProduct[productID]; !ok {
+			list, err := orders.ListFills(QueryParams{ProductIDParam: productID})
+			if err != nil {
+				return err
+			}
+			for _, f := range list {
+				fills[fillKey{productID, f.TradeID, f.OrderID}] = f
+			}
+			byProduct[productID] = list
+			products = append(products, productID)
+		}
+
+		tradeID, _ := strconv.Atoi(a.Details.TradeID)
+		key := fillKey{productID, tradeID, a.Details.OrderID}
+		seen[entryKey{key, a.Type == "fee"}] = true
+		d := LedgerDiscrepancy{EntryID: a.ID, TradeID: a.Details.TradeID, OrderID: a.Details.OrderID, ProductID: productID}
+		f, ok := fills[key]
+		if !ok || a.Details.TradeID == "" {
+			d.Kind = DiscrepancyMissingFill
+			d.Detail = a.Type
+			r.add(d)
+			continue
+		}
+		want, err := ledgerAmount(f, r.Currency, a.Type == "fee")
+		if err != nil {
+			return err
+		}
+		got, err := parseDecimal(a.Amount)
+		if err != nil {
+			return err
+		}
+		if want == nil || want.Cmp(got) != 0 {
+			d.Kind = DiscrepancyFillAmount
+			d.Actual = a.Amount
+			if want != nil {
+				d.Expected = formatDecimal(want)
+			}
+			d.Detail = a.Type
+			r.add(d)
+		}
+	}
+
+	// every fill of those products that moves this currency needs its entries
+	for _, productID := range products {
+		for _, f := range byProduct[productID] {
+			key := fillKey{productID, f.TradeID, f.OrderID}
+			for _, fee := range []bool{false, true} {
+				want, err := ledgerAmount(f, r.Currency, fee)
+				if err != nil {
+					return err
+				}
+				if want == nil || want.Sign() == 0 || seen[entryKey{key, fee}] {
+					continue
+				}
+				detail := "match"
+				if fee {
+					detail = "fee"
+				}
+				r.add(LedgerDiscrepancy{
+					Kind:      DiscrepancyMissingEntry,
+					TradeID:   strconv.Itoa(f.TradeID),
+					OrderID:   f.OrderID,
+					ProductID: productID,
+					Expected:  formatDecimal(want),
+					Detail:    detail,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// ledgerAmount is the amount a fill's match (or fee) entry should have in an account of currency, or
+// nil when the fill doesn't move currency.
+func ledgerAmount(f Fill, currency string, fee bool) (*big.Rat, error) {
+	base, quote, err := splitProductID(f.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	size, err := parseDecimal(f.Size)
+	if err != nil {
+		return nil, err
+	}
+	price, err := parseDecimal(f.Price)
+	if err != nil {
+		return nil, err
+	}
+	paid, err := parseDecimal(f.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	if fee {
+		if currency != quote {
+			return nil, nil
+		}
+		return newRat().Neg(paid), nil
+	}
+	var amount *big.Rat
+	switch currency {
+	case base:
+		amount = size
+	case quote:
+		amount = mul(price, size)
+	default:
+		return nil, nil
+	}
+	// a buy adds base and takes quote, a sell the other way around
+	if (currency == base) != (f.Side == "buy") {
+		amount = newRat().Neg(amount)
+	}
+	return amount, nil
+}
+
+// checkHolds checks each order hold is for an open order, and that the holds add up.
+func (r *LedgerReport) checkHolds(accounts AccountsAPI, orders OrdersAPI, account Account) error {
+	e, err := explainHolds(accounts, orders, account)
+	if err != nil {
+		return err
+	}
+	for _, h := range e.Holds {
+		if !h.Stale {
+			continue
+		}
+		d := LedgerDiscrepancy{Kind: DiscrepancyHold, HoldID: h.ID, OrderID: h.Ref, Actual: h.Amount, Detail: "order not found"}
+		if h.Order != nil {
+			d.ProductID, d.Detail = h.Order.ProductID, "order "+h.Order.Status
+		}
+		r.add(d)
+	}
+	r.Holds = e.Total
+	if !e.Explained() {
+		r.add(LedgerDiscrepancy{Kind: DiscrepancyHoldTotal, Expected: r.Holds, Actual: account.Holds})
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func ledgerFakes(account Account, history []AccountActivity, holds []AccountHold, fills []Fill, open map[string]Order) (*FakeAccountsAPI, *FakeOrdersAPI) {
+	accounts := &FakeAccountsAPI{
+		GetAccountFunc:        func(id string) (Account, error) { return account, nil },
+		GetAccountHistoryFunc: func(id string) ([]AccountActivity, error) { return history, nil },
+		GetAccountHoldsFunc:   func(id string) ([]AccountHold, error) { return holds, nil },
+	}
+	orders := &FakeOrdersAPI{
+		ListFillsFunc: func(qp QueryParams) ([]Fill, error) {
+			var list []Fill
+			for _, f := range fills {
+				if f.ProductID == qp[ProductIDParam] {
+					list = append(list, f)
+				}
+			}
+			return list, nil
+		},
+		GetOrderByIDFunc: func(id string) (Order, error) {
+			o, ok := open[id]
+			if !ok {
+				return Order{}, &StatusError{StatusCode: 404, Message: "NotFound"}
+			}
+			return o, nil
+		},
+	}
+	return accounts, orders
+}
+
+func TestReconcileLedger(t *testing.T) {
+	detail := ActivityDetail{OrderID: "o1", TradeID: "74", ProductID: "BTC-USD"}
+	history := []AccountActivity{
+		{ID: "104", Type: "fee", Amount: "-0.5", Balance: "899.5", Details: detail},
+		{ID: "103", Type: "match", Amount: "-100", Balance: "900", Details: detail},
+		{ID: "102", Type: "transfer", Amount: "1000", Balance: "1000"},
+	}
+	fills := []Fill{{TradeID: 74, ProductID: "BTC-USD", OrderID: "o1", Side: "buy", Price: "10000", Size: "0.01", Fee: "0.5"}}
+	holds := []AccountHold{{ID: "h1", Type: "order", Ref: "o2", Amount: "50"}}
+	open := map[string]Order{"o2": {ID: "o2", Status: "open"}}
+	account := Account{ID: "usd", Currency: "USD", Balance: "899.5", Holds: "50"}
+
+	accounts, orders := ledgerFakes(account, history, holds, fills, open)
+	r, err := ReconcileLedger(accounts, orders, "usd")
+	if err != nil {
+		t.Fatalf("ReconcileLedger() error = %v", err)
+	}
+	want := LedgerReport{
+		AccountID:      "usd",
+		Currency:       "USD",
+		Entries:        3,
+		OpeningBalance: "0",
+		ClosingBalance: "899.5",
+		AccountBalance: "899.5",
+		Holds:          "50",
+		AccountHolds:   "50",
+	}
+	if !r.OK() || !reflect.DeepEqual(r, want) {
+		t.Errorf("ReconcileLedger() = %+v, want %+v", r, want)
+	}
+
+	// the BTC side of the same trade
+	btcHistory := []AccountActivity{{ID: "105", Type: "match", Amount: "0.01", Balance: "0.01", Details: detail}}
+	btc := Account{ID: "btc", Currency: "BTC", Balance: "0.01", Holds: "0"}
+	accounts, orders = ledgerFakes(btc, btcHistory, nil, fills, nil)
+	if r, err := ReconcileLedger(accounts, orders, "btc"); err != nil || !r.OK() {
+		t.Errorf("ReconcileLedger() BTC = %+v, %v, want no discrepancies", r, err)
+	}
+}
+
+func TestReconcileLedger_Discrepancies(t *testing.T) {
+	history := []AccountActivity{
+		{ID: "106", Type: "rebalance", Amount: "1", Balance: "941"},
+		{ID: "105", Type: "match", Amount: "-60", Balance: "940", Details: ActivityDetail{OrderID: "o1", TradeID: "74", ProductID: "BTC-USD"}},
+		{ID: "104", Type: "match", Amount: "-40", Balance: "1000", Details: ActivityDetail{OrderID: "o9", TradeID: "99", ProductID: "BTC-USD"}},
+		{ID: "102", Type: "transfer", Amount: "1000", Balance: "1000"},
+	}
+	fills := []Fill{
+		{TradeID: 74, ProductID: "BTC-USD", OrderID: "o1", Side: "buy", Price: "10000", Size: "0.01", Fee: "0.5"},
+		{TradeID: 75, ProductID: "BTC-USD", OrderID: "o1", Side: "buy", Price: "10000", Size: "0.001", Fee: "0"},
+	}
+	holds := []AccountHold{
+		{ID: "h1", Type: "order", Ref: "gone", Amount: "10"},
+		{ID: "h2", Type: "order", Ref: "o3", Amount: "5"},
+		{ID: "h3", Type: "transfer", Ref: "t1", Amount: "1"},
+	}
+	open := map[string]Order{"o3": {ID: "o3", Status: "done", OrderParams: OrderParams{CommonOrderParams: CommonOrderParams{ProductID: "BTC-USD"}}}}
+	account := Account{ID: "usd", Currency: "USD", Balance: "900", Holds: "15"}
+
+	accounts, orders := ledgerFakes(account, history, holds, fills, open)
+	r, err := ReconcileLedger(accounts, orders, "usd")
+	if err != nil {
+		t.Fatalf("ReconcileLedger() error = %v", err)
+	}
+	want := []LedgerDiscrepancy{
+		{Kind: DiscrepancyBalance, EntryID: "104", Expected: "960", Actual: "1000", Detail: "match of -40"},
+		{Kind: DiscrepancyEntryType, EntryID: "106", Actual: "rebalance"},
+		{Kind: DiscrepancyAccountBalance, Expected: "941", Actual: "900"},
+		{Kind: DiscrepancyMissingFill, EntryID: "104", TradeID: "99", OrderID: "o9", ProductID: "BTC-USD", Detail: "match"},
+		{Kind: DiscrepancyFillAmount, EntryID: "105", TradeID: "74", OrderID: "o1", ProductID: "BTC-USD", Expected: "-100", Actual: "-60", Detail: "match"},
+		{Kind: DiscrepancyMissingEntry, TradeID: "74", OrderID: "o1", ProductID: "BTC-USD", Expected: "-0.5", Detail: "fee"},
+		{Kind: DiscrepancyMissingEntry, TradeID: "75", OrderID: "o1", ProductID: "BTC-USD", Expected: "-10", Detail: "match"},
+		{Kind: DiscrepancyHold, HoldID: "h1", OrderID: "gone", Actual: "10", Detail: "order not found"},
+		{Kind: DiscrepancyHold, HoldID: "h2", OrderID: "o3", ProductID: "BTC-USD", Actual: "5", Detail: "order done"},
+		{Kind: DiscrepancyHoldTotal, Expected: "16", Actual: "15"},
+	}
+	if !reflect.DeepEqual(r.Discrepancies, want) {
+		t.Errorf("Discrepancies =\n%+v\nwant\n%+v", r.Discrepancies, want)
+	}
+}
@@ -0,0 +1,167 @@
+package godax
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Live trading guard errors
+var (
+	ErrLiveTradingNotEnabled  = errors.New("mutating requests against the live API need the WithLiveTrading option")
+	ErrLiveProductNotAllowed  = errors.New("product is not in the WithLiveTrading allowlist")
+	ErrSessionNotionalCap     = errors.New("order would take the session over its notional cap")
+	ErrInvalidSessionNotional = errors.New("please provide a positive decimal session notional cap")
+)
+
+// LiveTrading confirms a client may send mutating requests to the live API, see WithLiveTrading.
+type LiveTrading struct {
+	// Products are the only products orders can be placed for.
+	Products []string
+
+	// SessionNotionalCap optionally caps the total value, in quote currency, of every order placed by
+	// the client. Limit orders count at their price, market orders at their funds or at the ticker.
+	SessionNotionalCap string
+}
+
+// liveGuard holds the state of a client's WithLiveTrading option.
+type liveGuard struct {
+	products map[string]bool
+	cap      *big.Rat
+
+	mu    sync.Mutex
+	spent *big.Rat
+}
+
+// WithLiveTrading allows a client on the live API to send mutating requests. Without it, every POST,
+// PUT and DELETE a live client makes fails with ErrLiveTradingNotEnabled before it is sent, so a
+// script meant for the sandbox can't trade for real just because live keys are in the environment.
+// Orders are further limited to live.Products and live.SessionNotionalCap. Canceling orders only
+// takes risk off, so CancelOrderByID, CancelOrderByClientOID and CancelAllOrders are never guarded.
+// Sandbox and dry-run clients are not guarded.
+func WithLiveTrading(live LiveTrading) ClientOption {
+	return func(c *Client) error {
+		g := &liveGuard{products: make(map[string]bool), spent: newRat()}
+		for _, p := range live.Products {
+			g.products[p] = true
+		}
+		if live.SessionNotionalCap != "" {
+			max, err := parseDecimal(live.SessionNotionalCap)
+			if err != nil || max.Sign() <= 0 {
+				return ErrInvalidSessionNotional
+			}
+			g.cap = max
+		}
+		c.live = g
+		return nil
+	}
+}
+
+// SessionNotional returns the value of the orders placed so far under WithLiveTrading's notional cap.
+func (c *Client) SessionNotional() string {
+	if c.live == nil {
+		return "0"
+	}
+	c.live.mu.Lock()
+	defer c.live.mu.Unlock()
+	return formatDecimal(c.live.spent)
+}
+
+// guardLive checks a mutating request against the live trading guard. The returned release undoes
+// the order's notional reservation and must be called if the request doesn't go through.
+func (c *Client) guardLive(req *http.Request) (release func(), err error) {
+	release = func() {}
+	path := req.URL.Path
+	if req.Method == http.MethodDelete && (path == "/orders" || strings.HasPrefix(path, "/orders/")) {
+		return release, nil
+	}
+	if c.live == nil {
+		return release, ErrLiveTradingNotEnabled
+	}
+	if req.Method == http.MethodPost && path == "/orders" {
+		var order OrderParams
+		if err := readBody(req, &order); err != nil {
+			return release, err
+		}
+		if !c.live.products[order.ProductID] {
+			return release, liveProductErr(order.ProductID)
+		}
+		if c.live.cap == nil {
+			return release, nil
+		}
+		notional, err := c.orderNotional(order)
+		if err != nil {
+			return release, err
+		}
+		return c.live.reserve(notional)
+	}
+	return release, nil
+}
+
+// orderNotional values an order in its quote currency, market orders by size at the ticker price.
+func (c *Client) orderNotional(order OrderParams) (*big.Rat, error) {
+	if order.Type == "market" && order.Funds != "" {
+		return parseDecimal(order.Funds)
+	}
+	size, err := parseDecimal(order.Size)
+	if err != nil {
+		return nil, err
+	}
+	price := order.Price
+	if order.Type == "market" {
+		t, err := c.GetProductTicker(order.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		price = t.Price
+	}
+	p, err := parseDecimal(price)
+	if err != nil {
+		return nil, err
+	}
+	return mul(p, size), nil
+}
+
+func (g *liveGuard) reserve(notional *big.Rat) (func(), error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	total := add(g.spent, notional)
+	if total.Cmp(g.cap) > 0 {
+		return func() {}, fmt.Errorf("%w: %s placed, %s more would pass %s", ErrSessionNotionalCap, formatDecimal(g.spent), formatDecimal(notional), formatDecimal(g.cap))
+	}
+	g.spent = total
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.spent = sub(g.spent, notional)
+	}, nil
+}
+
+// readBody decodes a request's body without consuming it.
+func readBody(req *http.Request, v interface{}) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func liveProductErr(productID string) error {
+	if productID == "" {
+		return fmt.Errorf("%w: no product_id given", ErrLiveProductNotAllowed)
+	}
+	return fmt.Errorf("%w: %s", ErrLiveProductNotAllowed, productID)
+}
@@ -0,0 +1,128 @@
+package godax
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func liveClient(t *testing.T, mock *MockClient, opts ...ClientOption) *Client {
+	t.Helper()
+	c := &Client{
+		baseRestURL: liveREST,
+		baseWsURL:   liveWS,
+		key:         key,
+		secret:      secret,
+		passphrase:  passphrase,
+		httpClient:  mock,
+	}
+	if err := c.apply(opts); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	return c
+}
+
+func limitOrder(productID, price, size string) OrderParams {
+	return OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: productID, Type: "limit", Price: price, Size: size}}
+}
+
+func TestClient_LiveNeedsOptIn(t *testing.T) {
+	mock := MockResponse(`[]`)
+	c := liveClient(t, mock)
+
+	if _, err := c.PlaceOrder(limitOrder("BTC-USD", "100", "1")); err != ErrLiveTradingNotEnabled {
+		t.Errorf("PlaceOrder() error = %v, want ErrLiveTradingNotEnabled", err)
+	}
+	if err := c.ProfileTransfer(TransferParams{From: "a", To: "b", Currency: "USD", Amount: "1"}); err != ErrLiveTradingNotEnabled {
+		t.Errorf("ProfileTransfer() error = %v, want ErrLiveTradingNotEnabled", err)
+	}
+	if len(mock.Requests) != 0 {
+		t.Fatalf("made %d requests, want none", len(mock.Requests))
+	}
+
+	if _, err := c.ListAccounts(); err != nil {
+		t.Fatalf("ListAccounts() error = %v", err)
+	}
+	// canceling only takes risk off, a dead man's switch has to be able to
+	if _, err := c.CancelAllOrders(QueryParams{}); err != nil {
+		t.Errorf("CancelAllOrders() error = %v", err)
+	}
+	if len(mock.Requests) != 2 {
+		t.Errorf("made %d requests, want the GET and the cancel sent", len(mock.Requests))
+	}
+
+	// the sandbox isn't guarded
+	sandbox := liveClient(t, MockResponse(`{"id": "order-1"}`))
+	sandbox.baseRestURL = sandboxREST
+	if _, err := sandbox.PlaceOrder(limitOrder("ETH-USD", "100", "1")); err != nil {
+		t.Errorf("sandbox PlaceOrder() error = %v", err)
+	}
+}
+
+func TestClient_LiveProductsAndNotionalCap(t *testing.T) {
+	mock := MockResponses(
+		`{"id": "order-1"}`,
+		`{"price": "200"}`,
+		`{"id": "order-2"}`,
+		`["order-1", "order-2", "order-3"]`,
+		`"order-3"`,
+	)
+	c := liveClient(t, mock, WithLiveTrading(LiveTrading{Products: []string{"BTC-USD"}, SessionNotionalCap: "500"}))
+
+	if _, err := c.PlaceOrder(limitOrder("ETH-USD", "10", "1")); !errors.Is(err, ErrLiveProductNotAllowed) {
+		t.Errorf("PlaceOrder() error = %v, want ErrLiveProductNotAllowed", err)
+	}
+	if _, err := c.PlaceOrder(limitOrder("BTC-USD", "100", "2")); err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	market := OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Type: "market", Size: "1.5"}}
+	if _, err := c.PlaceOrder(market); err != nil {
+		t.Fatalf("PlaceOrder() market error = %v", err)
+	}
+	if got := c.SessionNotional(); got != "500" {
+		t.Errorf("SessionNotional() = %s, want 500", got)
+	}
+	if _, err := c.PlaceOrder(limitOrder("BTC-USD", "0.01", "1")); !errors.Is(err, ErrSessionNotionalCap) {
+		t.Errorf("PlaceOrder() error = %v, want ErrSessionNotionalCap", err)
+	}
+
+	// cancels aren't limited to the allowlist
+	if _, err := c.CancelAllOrders(QueryParams{}); err != nil {
+		t.Errorf("CancelAllOrders() without a product error = %v", err)
+	}
+	if err := c.CancelOrderByID("order-3", QueryParams{ProductIDParam: "ETH-USD"}); err != nil {
+		t.Errorf("CancelOrderByID() for an ETH-USD order error = %v", err)
+	}
+
+	var methods []string
+	for _, r := range mock.Requests {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+	}
+	want := []string{"POST /orders", "GET /products/BTC-USD/ticker", "POST /orders", "DELETE /orders", "DELETE /orders/order-3"}
+	if len(methods) != len(want) {
+		t.Fatalf("requests = %v, want %v", methods, want)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Errorf("requests = %v, want %v", methods, want)
+			break
+		}
+	}
+}
+
+func TestClient_LiveNotionalReleasedOnFailure(t *testing.T) {
+	mock := &MockClient{StatusCode: http.StatusBadRequest, Response: []byte(`{"message": "Insufficient funds"}`)}
+	c := liveClient(t, mock, WithLiveTrading(LiveTrading{Products: []string{"BTC-USD"}, SessionNotionalCap: "100"}))
+
+	if _, err := c.PlaceOrder(limitOrder("BTC-USD", "100", "1")); err == nil {
+		t.Fatal("PlaceOrder() error = nil, want the exchange's error")
+	}
+	if got := c.SessionNotional(); got != "0" {
+		t.Errorf("SessionNotional() = %s, want the failed order released", got)
+	}
+
+	if _, err := NewClientWithCredentials(Credentials{Key: key, Secret: secret, Passphrase: passphrase}, false,
+		WithLiveTrading(LiveTrading{SessionNotionalCap: "-1"})); err != ErrInvalidSessionNotional {
+		t.Errorf("NewClientWithCredentials() error = %v, want ErrInvalidSessionNotional", err)
+	}
+}
@@ -0,0 +1,16 @@
+package godax
+
+import (
+	"log"
+	"os"
+)
+
+// Logger is what godax components log to. A *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// defaultLogger logs to stderr, used when a component isn't given a Logger.
+func defaultLogger() Logger {
+	return log.New(os.Stderr, "godax: ", log.LstdFlags)
+}
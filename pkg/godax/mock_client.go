@@ -14,6 +14,9 @@ type MockClient struct {
 	Responses  [][]byte
 	Requests   []*http.Request
 	StatusCode int
+
+	// Headers are response headers, matched to requests the same way as Responses
+	Headers []http.Header
 }
 
 // Do saves the HTTP Request, returning 200 and no error
@@ -33,8 +36,13 @@ func (mock *MockClient) Do(req *http.Request) (*http.Response, error) {
 	}
 
 	if len(mock.Responses) > 0 {
+		header := http.Header{}
+		if len(mock.Headers) >= len(mock.Requests) {
+			header = mock.Headers[len(mock.Requests)-1]
+		}
 		return &http.Response{
 			StatusCode: statusCode,
+			Header:     header,
 			Body:       MockBody(mock.Responses[len(mock.Requests)-1]),
 		}, nil
 	}
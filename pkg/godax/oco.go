@@ -0,0 +1,472 @@
+package godax
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// OrderManager errors
+var (
+	ErrInvalidOCO  = errors.New("please provide a product, side, size, take profit price and stop price")
+	ErrOCONotFound = errors.New("no OCO or bracket found with that ID")
+)
+
+// OCO (one-cancels-other) group statuses
+const (
+	OCOStatusPending  = "pending"
+	OCOStatusActive   = "active"
+	OCOStatusFilled   = "filled"
+	OCOStatusCanceled = "canceled"
+)
+
+// OCOParams describe a take profit limit order and a protective stop order on the same position.
+// Side is the side of the exit orders: sell to close a long position, buy to close a short one.
+type OCOParams struct {
+	ProductID string
+	Side      string
+	Size      string
+
+	// TakeProfitPrice is the price of the take profit limit order.
+	TakeProfitPrice string
+
+	// StopPrice triggers the stop order, StopLimitPrice is the limit it is placed at once triggered
+	// and defaults to StopPrice.
+	StopPrice      string
+	StopLimitPrice string
+}
+
+// BracketParams describe an entry order that is protected by an OCO once it starts to fill. The
+// exits are on the opposite side of the entry and are sized to whatever the entry has filled.
+type BracketParams struct {
+	Entry           OrderParams
+	TakeProfitPrice string
+	StopPrice       string
+	StopLimitPrice  string
+}
+
+// OCO is a snapshot of an OCO or bracket group managed by an OrderManager. Order IDs change when
+// a leg is resized since coinbase pro orders can't be amended, they are canceled and replaced.
+type OCO struct {
+	ID                string
+	ProductID         string
+	Status            string
+	EntryOrderID      string
+	EntryFilledSize   string
+	TakeProfitOrderID string
+	StopOrderID       string
+	ExitFilledSize    string
+}
+
+// OrderManager emulates one-cancels-other and bracket orders on the client, since coinbase pro only
+// offers plain limit, market and stop orders. It places a take profit limit and a protective stop
+// together and follows their fills through user channel messages (HandleFeedMessage) or by polling
+// (Poll). When one side fills the other is canceled with CancelOrderByID, and when one side only
+// partially fills the other is canceled and replaced with the size that is left.
+//
+// NOTE: coinbase pro puts a hold on the funds of both legs, so the full size has to be available
+// twice over while an OCO is active.
+type OrderManager struct {
+	mu      sync.Mutex
+	orders  OrdersAPI
+	groups  map[string]*ocoGroup
+	byOrder map[string]*ocoLeg
+	trades  map[fillKey]bool
+}
+
+type ocoGroup struct {
+	id        string
+	productID string
+	side      string
+	size      *big.Rat // the size to exit, nil for a bracket whose exit size follows the entry
+	status    string
+	entry     *ocoLeg
+	tp        *ocoLeg
+	stop      *ocoLeg
+}
+
+type ocoLeg struct {
+	group      *ocoGroup
+	params     OrderParams
+	orderID    string
+	size       *big.Rat
+	filled     *big.Rat // filled on the current order
+	prevFilled *big.Rat // filled on orders this leg replaced
+	done       bool
+	canceled   bool
+}
+
+// NewOrderManager creates an OrderManager that places and cancels orders through orders.
+func NewOrderManager(orders OrdersAPI) *OrderManager {
+	return &OrderManager{
+		orders:  orders,
+		groups:  make(map[string]*ocoGroup),
+		byOrder: make(map[string]*ocoLeg),
+		trades:  make(map[fillKey]bool),
+	}
+}
+
+// PlaceOCO places the take profit and stop orders of an OCO.
+func (m *OrderManager) PlaceOCO(p OCOParams) (OCO, error) {
+	size, err := parseDecimal(p.Size)
+	if err != nil {
+		return OCO{}, err
+	}
+	if size.Sign() <= 0 {
+		return OCO{}, ErrInvalidOCO
+	}
+	g, err := newOCOGroup(p.ProductID, p.Side, p.TakeProfitPrice, p.StopPrice, p.StopLimitPrice)
+	if err != nil {
+		return OCO{}, err
+	}
+	g.size = size
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.groups[g.id] = g
+	if err := m.reconcile(g); err != nil {
+		// the caller never gets the group's ID, so don't keep it around
+		delete(m.groups, g.id)
+		for _, leg := range g.legs() {
+			delete(m.byOrder, leg.orderID)
+		}
+		return OCO{}, err
+	}
+	return g.snapshot(), nil
+}
+
+// PlaceBracket places the entry order of a bracket. Its take profit and stop are placed as the
+// entry fills. If the entry is placed but its exits can't be, the bracket is returned along with the
+// error and the exits are tried again on the next Poll or fill.
+func (m *OrderManager) PlaceBracket(p BracketParams) (OCO, error) {
+	exitSide := "sell"
+	if p.Entry.Side == "sell" {
+		exitSide = "buy"
+	} else if p.Entry.Side != "buy" {
+		return OCO{}, ErrInvalidOCO
+	}
+	g, err := newOCOGroup(p.Entry.ProductID, exitSide, p.TakeProfitPrice, p.StopPrice, p.StopLimitPrice)
+	if err != nil {
+		return OCO{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, err := m.orders.PlaceOrder(p.Entry)
+	if err != nil {
+		return OCO{}, err
+	}
+	size, err := parseDecimal(o.Size)
+	if err != nil {
+		return OCO{}, err
+	}
+	g.entry = &ocoLeg{group: g, params: p.Entry, size: size, filled: newRat(), prevFilled: newRat()}
+	m.track(g.entry, o)
+	m.groups[g.id] = g
+	if err := m.reconcile(g); err != nil {
+		return g.snapshot(), err
+	}
+	return g.snapshot(), nil
+}
+
+// HandleFeedMessage applies a user channel message. Match messages add to a leg's filled size
+// and done messages mark a leg as finished. Messages for other orders, and done messages for legs
+// the manager already finished, are ignored.
+func (m *OrderManager) HandleFeedMessage(msg FeedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch msg.Type {
+	case FeedMatch:
+		leg := m.byOrder[msg.MakerOrderID]
+		if leg == nil {
+			leg = m.byOrder[msg.TakerOrderID]
+		}
+		if leg == nil {
+			return nil
+		}
+		key := fillKey{productID: msg.ProductID, tradeID: msg.TradeID, orderID: leg.orderID}
+		if m.trades[key] {
+			return nil
+		}
+		size, err := parseDecimal(msg.Size)
+		if err != nil {
+			return err
+		}
+		m.trades[key] = true
+		leg.filled = add(leg.filled, size)
+		return m.reconcile(leg.group)
+
+	case FeedDone:
+		// a leg that is already done was finished by us (or seen finishing by Poll), its done
+		// message would read our own cancel as someone else's
+		leg := m.byOrder[msg.OrderID]
+		if leg == nil || leg.done {
+			return nil
+		}
+		remaining, err := parseDecimal(msg.RemainingSize)
+		if err != nil {
+			return err
+		}
+		if filled := sub(leg.size, remaining); filled.Cmp(leg.filled) > 0 {
+			leg.filled = filled
+		}
+		leg.done = true
+		leg.canceled = msg.Reason == "canceled"
+		return m.reconcile(leg.group)
+	}
+	return nil
+}
+
+// Poll looks up every live leg with GetOrderByID and reconciles the groups with what it finds.
+// Use it instead of, or as a backstop for, HandleFeedMessage.
+func (m *OrderManager) Poll() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, g := range m.groups {
+		if g.finished() {
+			continue
+		}
+		for _, leg := range g.legs() {
+			if leg.orderID == "" || leg.done {
+				continue
+			}
+			o, err := m.orders.GetOrderByID(leg.orderID)
+			if isNotFound(err) {
+				leg.done, leg.canceled = true, true
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			m.apply(leg, o)
+		}
+		if err := m.reconcile(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cancel cancels every live order of a group.
+func (m *OrderManager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.groups[id]
+	if !ok {
+		return ErrOCONotFound
+	}
+	for _, leg := range g.legs() {
+		if err := m.cancelLeg(leg); err != nil {
+			return err
+		}
+	}
+	g.status = OCOStatusCanceled
+	return nil
+}
+
+// Get returns a snapshot of a group.
+func (m *OrderManager) Get(id string) (OCO, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.groups[id]
+	if !ok {
+		return OCO{}, false
+	}
+	return g.snapshot(), true
+}
+
+func newOCOGroup(productID, side, tp, stop, stopLimit string) (*ocoGroup, error) {
+	if productID == "" || (side != "buy" && side != "sell") || tp == "" || stop == "" {
+		return nil, ErrInvalidOCO
+	}
+	if stopLimit == "" {
+		stopLimit = stop
+	}
+	stopType := "loss"
+	if side == "buy" {
+		stopType = "entry"
+	}
+	g := &ocoGroup{id: newUUID(), productID: productID, side: side, status: OCOStatusPending}
+	g.tp = &ocoLeg{group: g, filled: newRat(), prevFilled: newRat(), params: OrderParams{
+		CommonOrderParams: CommonOrderParams{Side: side, ProductID: productID, Type: "limit", Price: tp},
+	}}
+	g.stop = &ocoLeg{group: g, filled: newRat(), prevFilled: newRat(), params: OrderParams{
+		CommonOrderParams: CommonOrderParams{Side: side, ProductID: productID, Type: "limit", Price: stopLimit, Stop: stopType, StopPrice: stop},
+	}}
+	return g, nil
+}
+
+// reconcile works out how much is left to exit and makes the exit legs match it, placing,
+// resizing or canceling them as needed. Callers must hold m.mu.
+func (m *OrderManager) reconcile(g *ocoGroup) error {
+	if g.finished() {
+		return nil
+	}
+
+	target := g.size
+	if g.entry != nil {
+		target = g.entry.totalFilled()
+	}
+	exited := add(g.tp.totalFilled(), g.stop.totalFilled())
+	remaining := sub(target, exited)
+
+	// an exit canceled outside of the manager cancels the whole group, one cancels the other
+	if g.tp.canceled || g.stop.canceled || (g.entry != nil && g.entry.canceled && target.Sign() == 0) {
+		for _, leg := range g.legs() {
+			if err := m.cancelLeg(leg); err != nil {
+				return err
+			}
+		}
+		g.status = OCOStatusCanceled
+		return nil
+	}
+
+	entryDone := g.entry == nil || g.entry.done
+	if remaining.Sign() <= 0 && target.Sign() > 0 {
+		for _, leg := range []*ocoLeg{g.tp, g.stop} {
+			if err := m.cancelLeg(leg); err != nil {
+				return err
+			}
+		}
+		if entryDone {
+			g.status = OCOStatusFilled
+		}
+		return nil
+	}
+	if remaining.Sign() <= 0 {
+		return nil
+	}
+
+	// both exits go out or neither does, a take profit without its stop (or the other way around)
+	// isn't an OCO
+	var placed []*ocoLeg
+	for _, leg := range []*ocoLeg{g.tp, g.stop} {
+		if leg.orderID != "" && !leg.done && sub(leg.size, leg.filled).Cmp(remaining) == 0 {
+			continue
+		}
+		if err := m.cancelLeg(leg); err != nil {
+			return m.unwind(placed, err)
+		}
+		if err := m.placeLeg(leg, remaining); err != nil {
+			return m.unwind(placed, err)
+		}
+		placed = append(placed, leg)
+	}
+	g.status = OCOStatusActive
+	return nil
+}
+
+// unwind cancels the legs placed by a reconcile that then failed with err.
+func (m *OrderManager) unwind(placed []*ocoLeg, err error) error {
+	for _, leg := range placed {
+		if cancelErr := m.cancelLeg(leg); cancelErr != nil {
+			return fmt.Errorf("%w (canceling order %s placed along with it failed too: %v)", err, leg.orderID, cancelErr)
+		}
+	}
+	return err
+}
+
+// placeLeg places a (new) order for a leg, rolling the fills of its previous order into prevFilled.
+func (m *OrderManager) placeLeg(leg *ocoLeg, size *big.Rat) error {
+	params := leg.params
+	params.Size = formatDecimal(size)
+	params.ClientOID = newUUID()
+
+	o, err := m.orders.PlaceOrder(params)
+	if err != nil {
+		return err
+	}
+	leg.prevFilled = leg.totalFilled()
+	leg.filled = newRat()
+	leg.size = size
+	leg.done, leg.canceled = false, false
+	m.track(leg, o)
+	return nil
+}
+
+// cancelLeg cancels a leg's live order, picking up any fills it had before it went away.
+func (m *OrderManager) cancelLeg(leg *ocoLeg) error {
+	if leg.orderID == "" || leg.done {
+		return nil
+	}
+	qp := QueryParams{ProductIDParam: leg.params.ProductID}
+	if err := m.orders.CancelOrderByID(leg.orderID, qp); err != nil && !isNotFound(err) {
+		// the order may have finished since we last heard about it
+		o, getErr := m.orders.GetOrderByID(leg.orderID)
+		if getErr != nil || o.Status != orderStatusDone {
+			return err
+		}
+	}
+	o, err := m.orders.GetOrderByID(leg.orderID)
+	if err == nil {
+		m.apply(leg, o)
+	} else if !isNotFound(err) {
+		return err
+	}
+	leg.done = true
+	leg.canceled = false // canceled by us, not by someone else
+	return nil
+}
+
+// track points feed lookups at a leg's current order.
+func (m *OrderManager) track(leg *ocoLeg, o Order) {
+	if leg.orderID != "" {
+		delete(m.byOrder, leg.orderID)
+	}
+	leg.orderID = o.ID
+	m.byOrder[o.ID] = leg
+	m.apply(leg, o)
+}
+
+// apply updates a leg from an order looked up from (or returned by) the API.
+func (m *OrderManager) apply(leg *ocoLeg, o Order) {
+	if filled, err := parseDecimal(o.FilledSize); err == nil && filled.Cmp(leg.filled) > 0 {
+		leg.filled = filled
+	}
+	if o.Status == orderStatusDone {
+		leg.done = true
+		leg.canceled = leg.filled.Cmp(leg.size) < 0
+	}
+}
+
+func (l *ocoLeg) totalFilled() *big.Rat {
+	if l == nil {
+		return newRat()
+	}
+	return add(l.prevFilled, l.filled)
+}
+
+func (g *ocoGroup) legs() []*ocoLeg {
+	legs := []*ocoLeg{g.tp, g.stop}
+	if g.entry != nil {
+		legs = append([]*ocoLeg{g.entry}, legs...)
+	}
+	return legs
+}
+
+func (g *ocoGroup) finished() bool {
+	return g.status == OCOStatusFilled || g.status == OCOStatusCanceled
+}
+
+func (g *ocoGroup) snapshot() OCO {
+	o := OCO{
+		ID:                g.id,
+		ProductID:         g.productID,
+		Status:            g.status,
+		TakeProfitOrderID: g.tp.orderID,
+		StopOrderID:       g.stop.orderID,
+		ExitFilledSize:    formatDecimal(add(g.tp.totalFilled(), g.stop.totalFilled())),
+	}
+	if g.entry != nil {
+		o.EntryOrderID = g.entry.orderID
+		o.EntryFilledSize = formatDecimal(g.entry.totalFilled())
+	}
+	return o
+}
@@ -0,0 +1,332 @@
+package godax
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// fakeExchange is a minimal OrdersAPI whose orders only fill when the test says so.
+type fakeExchange struct {
+	FakeOrdersAPI
+	orders   map[string]*Order
+	placed   []OrderParams
+	canceled []string
+}
+
+func newFakeExchange() *fakeExchange {
+	x := &fakeExchange{orders: make(map[string]*Order)}
+	x.PlaceOrderFunc = func(p OrderParams) (Order, error) {
+		x.placed = append(x.placed, p)
+		o := &Order{ID: "order-" + strconv.Itoa(len(x.placed)), Status: "open", FilledSize: "0", OrderParams: p}
+		x.orders[o.ID] = o
+		return *o, nil
+	}
+	x.CancelOrderByIDFunc = func(id string, qp QueryParams) error {
+		o, ok := x.orders[id]
+		if !ok || o.Status == "done" {
+			return &StatusError{StatusCode: 404, Message: "NotFound"}
+		}
+		o.Status = "done"
+		x.canceled = append(x.canceled, id)
+		return nil
+	}
+	x.GetOrderByIDFunc = func(id string) (Order, error) {
+		o, ok := x.orders[id]
+		if !ok {
+			return Order{}, &StatusError{StatusCode: 404, Message: "NotFound"}
+		}
+		return *o, nil
+	}
+	x.GetOrderByClientOIDFunc = func(clientOID string) (Order, error) {
+		for _, o := range x.orders {
+			if clientOID != "" && o.ClientOID == clientOID {
+				return *o, nil
+			}
+		}
+		return Order{}, &StatusError{StatusCode: 404, Message: "NotFound"}
+	}
+	return x
+}
+
+func TestOrderManager_OCOFilledOnOneSide(t *testing.T) {
+	paper := newTestPaperClient(t, nil, map[string]string{"BTC": "2"})
+	paper.ApplyPrice("BTC-USD", "100")
+	m := NewOrderManager(paper)
+
+	oco, err := m.PlaceOCO(OCOParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TakeProfitPrice: "110", StopPrice: "90"})
+	if err != nil {
+		t.Fatalf("PlaceOCO() error = %v", err)
+	}
+	if oco.Status != OCOStatusActive || oco.TakeProfitOrderID == "" || oco.StopOrderID == "" {
+		t.Fatalf("PlaceOCO() = %+v, want an active OCO with both legs placed", oco)
+	}
+	stop, _ := paper.GetOrderByID(oco.StopOrderID)
+	if stop.Stop != "loss" || stop.StopPrice != "90" || stop.Price != "90" {
+		t.Errorf("stop leg = %+v, want a loss stop at 90", stop.OrderParams)
+	}
+
+	paper.ApplyPrice("BTC-USD", "111")
+	if err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	got, _ := m.Get(oco.ID)
+	if got.Status != OCOStatusFilled || got.ExitFilledSize != "1" {
+		t.Errorf("Get() = %+v, want filled with 1 exited", got)
+	}
+	if open, _ := paper.ListOrders(QueryParams{}); len(open) != 0 {
+		t.Errorf("open orders = %+v, want the stop canceled", open)
+	}
+	if btc := paperBalances(t, paper)["BTC"]; btc.Balance != "1" || btc.Hold != "0" {
+		t.Errorf("BTC = %+v, want 1 left with no hold", btc)
+	}
+}
+
+func TestOrderManager_PartialFillResizesSibling(t *testing.T) {
+	x := newFakeExchange()
+	m := NewOrderManager(x)
+
+	oco, err := m.PlaceOCO(OCOParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TakeProfitPrice: "110", StopPrice: "90", StopLimitPrice: "89"})
+	if err != nil {
+		t.Fatalf("PlaceOCO() error = %v", err)
+	}
+
+	// the take profit fills 0.4, the stop is replaced with the 0.6 left
+	x.orders[oco.TakeProfitOrderID].FilledSize = "0.4"
+	msg := FeedMessage{Type: FeedMatch, TradeID: 1, MakerOrderID: oco.TakeProfitOrderID, Size: "0.4", Price: "110"}
+	if err := m.HandleFeedMessage(msg); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	if err := m.HandleFeedMessage(msg); err != nil {
+		t.Fatalf("HandleFeedMessage() with a duplicate error = %v", err)
+	}
+
+	got, _ := m.Get(oco.ID)
+	if got.StopOrderID == oco.StopOrderID || got.TakeProfitOrderID != oco.TakeProfitOrderID {
+		t.Fatalf("Get() = %+v, want only the stop replaced", got)
+	}
+	if len(x.canceled) != 1 || x.canceled[0] != oco.StopOrderID {
+		t.Errorf("canceled = %v, want the original stop", x.canceled)
+	}
+	newStop := x.orders[got.StopOrderID]
+	if newStop.Size != "0.6" || newStop.Price != "89" || newStop.StopPrice != "90" {
+		t.Errorf("new stop = %+v, want 0.6 at 89 stopping at 90", newStop.OrderParams)
+	}
+
+	// the replacement stop triggers and fills 0.1, the take profit is resized to 0.5
+	x.orders[got.StopOrderID].FilledSize = "0.1"
+	if err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	got, _ = m.Get(oco.ID)
+	if tp := x.orders[got.TakeProfitOrderID]; tp.Size != "0.5" || got.ExitFilledSize != "0.5" {
+		t.Errorf("take profit = %+v, exit filled = %s, want a 0.5 take profit with 0.5 exited", tp.OrderParams, got.ExitFilledSize)
+	}
+
+	// the stop finishes, which cancels the take profit
+	err = m.HandleFeedMessage(FeedMessage{Type: FeedDone, OrderID: got.StopOrderID, RemainingSize: "0", Reason: "filled"})
+	if err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	got, _ = m.Get(oco.ID)
+	if got.Status != OCOStatusFilled || got.ExitFilledSize != "1" {
+		t.Errorf("Get() = %+v, want filled", got)
+	}
+	if x.orders[got.TakeProfitOrderID].Status != "done" {
+		t.Error("the take profit should have been canceled")
+	}
+}
+
+func TestOrderManager_OneCancelsOther(t *testing.T) {
+	x := newFakeExchange()
+	m := NewOrderManager(x)
+	oco, err := m.PlaceOCO(OCOParams{ProductID: "BTC-USD", Side: "buy", Size: "1", TakeProfitPrice: "90", StopPrice: "110"})
+	if err != nil {
+		t.Fatalf("PlaceOCO() error = %v", err)
+	}
+	if stop := x.orders[oco.StopOrderID]; stop.Stop != "entry" {
+		t.Errorf("stop type for a buy exit = %s, want entry", stop.Stop)
+	}
+
+	// someone cancels the take profit by hand
+	x.orders[oco.TakeProfitOrderID].Status = "done"
+	if err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	got, _ := m.Get(oco.ID)
+	if got.Status != OCOStatusCanceled || x.orders[oco.StopOrderID].Status != "done" {
+		t.Errorf("Get() = %+v, want canceled with the stop canceled too", got)
+	}
+}
+
+func TestOrderManager_Bracket(t *testing.T) {
+	// both exits hold the bought size, so one more BTC has to be on hand
+	paper := newTestPaperClient(t, nil, map[string]string{"USD": "1000", "BTC": "1"})
+	paper.ApplyPrice("BTC-USD", "105")
+	m := NewOrderManager(paper)
+
+	b, err := m.PlaceBracket(BracketParams{
+		Entry:           OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Price: "100", Size: "1"}},
+		TakeProfitPrice: "120",
+		StopPrice:       "90",
+		StopLimitPrice:  "88",
+	})
+	if err != nil {
+		t.Fatalf("PlaceBracket() error = %v", err)
+	}
+	if b.Status != OCOStatusPending || b.TakeProfitOrderID != "" {
+		t.Fatalf("PlaceBracket() = %+v, want pending with no exits yet", b)
+	}
+
+	paper.ApplyPrice("BTC-USD", "100")
+	if err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	b, _ = m.Get(b.ID)
+	if b.Status != OCOStatusActive || b.EntryFilledSize != "1" {
+		t.Fatalf("Get() = %+v, want active once the entry filled", b)
+	}
+	if tp, _ := paper.GetOrderByID(b.TakeProfitOrderID); tp.Side != "sell" || tp.Size != "1" {
+		t.Errorf("take profit = %+v, want a sell of 1", tp.OrderParams)
+	}
+
+	paper.ApplyPrice("BTC-USD", "89")
+	if err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	b, _ = m.Get(b.ID)
+	if b.Status != OCOStatusFilled {
+		t.Errorf("Get() = %+v, want filled once the stop fills", b)
+	}
+	if open, _ := paper.ListOrders(QueryParams{}); len(open) != 0 {
+		t.Errorf("open orders = %+v, want none", open)
+	}
+
+	if err := m.Cancel("nope"); err != ErrOCONotFound {
+		t.Errorf("Cancel() error = %v, want ErrOCONotFound", err)
+	}
+}
+
+func TestOrderManager_OCOLegFailureUnwinds(t *testing.T) {
+	x := newFakeExchange()
+	errRejected := errors.New("rejected")
+	place := x.PlaceOrderFunc
+	x.PlaceOrderFunc = func(p OrderParams) (Order, error) {
+		if len(x.placed) == 1 {
+			return Order{}, errRejected
+		}
+		return place(p)
+	}
+	m := NewOrderManager(x)
+
+	_, err := m.PlaceOCO(OCOParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TakeProfitPrice: "110", StopPrice: "90"})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("PlaceOCO() error = %v, want the stop's rejection", err)
+	}
+	if len(x.placed) != 1 || len(x.canceled) != 1 || x.canceled[0] != "order-1" {
+		t.Errorf("placed %d, canceled %v, want the take profit canceled", len(x.placed), x.canceled)
+	}
+	if len(m.groups) != 0 || len(m.byOrder) != 0 {
+		t.Errorf("manager kept %d groups and %d orders, want none", len(m.groups), len(m.byOrder))
+	}
+}
+
+func TestOrderManager_BracketExitFailureRetries(t *testing.T) {
+	x := newFakeExchange()
+	errRejected := errors.New("rejected")
+	fail := true
+	place := x.PlaceOrderFunc
+	x.PlaceOrderFunc = func(p OrderParams) (Order, error) {
+		if fail && p.Stop != "" {
+			return Order{}, errRejected
+		}
+		o, err := place(p)
+		if p.Side == "buy" {
+			// the entry fills straight away
+			x.orders[o.ID].Status, x.orders[o.ID].FilledSize = "done", p.Size
+			o = *x.orders[o.ID]
+		}
+		return o, err
+	}
+	x.ListOrdersFunc = func(qp QueryParams) ([]Order, error) { return nil, nil }
+	m := NewOrderManager(x)
+
+	b, err := m.PlaceBracket(BracketParams{
+		Entry:           OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Price: "100", Size: "1"}},
+		TakeProfitPrice: "120",
+		StopPrice:       "90",
+	})
+	if !errors.Is(err, errRejected) || b.ID == "" {
+		t.Fatalf("PlaceBracket() = %+v, %v, want the bracket back with the stop's rejection", b, err)
+	}
+	if len(x.canceled) != 1 || x.canceled[0] != "order-2" {
+		t.Errorf("canceled %v, want the take profit canceled", x.canceled)
+	}
+
+	fail = false
+	if err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if b, _ = m.Get(b.ID); b.Status != OCOStatusActive || b.TakeProfitOrderID != "order-3" || b.StopOrderID != "order-4" {
+		t.Errorf("Get() = %+v, want both exits placed on the retry", b)
+	}
+}
+
+func TestOrderManager_OwnCancelEchoIgnored(t *testing.T) {
+	x := newFakeExchange()
+	m := NewOrderManager(x)
+	b, err := m.PlaceBracket(BracketParams{
+		Entry:           OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Type: "limit", Price: "100", Size: "2"}},
+		TakeProfitPrice: "120",
+		StopPrice:       "90",
+	})
+	if err != nil {
+		t.Fatalf("PlaceBracket() error = %v", err)
+	}
+
+	// the entry fills 1 of 2, exits go out for 1
+	x.orders[b.EntryOrderID].FilledSize = "1"
+	if err := m.HandleFeedMessage(FeedMessage{Type: FeedMatch, ProductID: "BTC-USD", TradeID: 1, MakerOrderID: b.EntryOrderID, Size: "1"}); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	b, _ = m.Get(b.ID)
+
+	// the take profit fills, the manager cancels the stop and the feed echoes that cancel
+	x.orders[b.TakeProfitOrderID].FilledSize = "1"
+	x.orders[b.TakeProfitOrderID].Status = "done"
+	if err := m.HandleFeedMessage(FeedMessage{Type: FeedMatch, ProductID: "BTC-USD", TradeID: 2, MakerOrderID: b.TakeProfitOrderID, Size: "1"}); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	if x.orders[b.StopOrderID].Status != "done" {
+		t.Fatal("the stop should have been canceled")
+	}
+	if err := m.HandleFeedMessage(FeedMessage{Type: FeedDone, OrderID: b.StopOrderID, RemainingSize: "1", Reason: "canceled"}); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+
+	got, _ := m.Get(b.ID)
+	if got.Status == OCOStatusCanceled || x.orders[b.EntryOrderID].Status == "done" {
+		t.Errorf("Get() = %+v, want the entry still working after the manager's own cancel", got)
+	}
+}
+
+func TestOrderManager_TradeIDsPerProduct(t *testing.T) {
+	x := newFakeExchange()
+	m := NewOrderManager(x)
+	btc, err := m.PlaceOCO(OCOParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TakeProfitPrice: "110", StopPrice: "90"})
+	if err != nil {
+		t.Fatalf("PlaceOCO() error = %v", err)
+	}
+	eth, err := m.PlaceOCO(OCOParams{ProductID: "ETH-USD", Side: "sell", Size: "1", TakeProfitPrice: "11", StopPrice: "9"})
+	if err != nil {
+		t.Fatalf("PlaceOCO() error = %v", err)
+	}
+
+	// both products have a trade 1
+	m.HandleFeedMessage(FeedMessage{Type: FeedMatch, ProductID: "BTC-USD", TradeID: 1, MakerOrderID: btc.TakeProfitOrderID, Size: "0.5"})
+	m.HandleFeedMessage(FeedMessage{Type: FeedMatch, ProductID: "ETH-USD", TradeID: 1, MakerOrderID: eth.TakeProfitOrderID, Size: "0.5"})
+	if got, _ := m.Get(eth.ID); got.ExitFilledSize != "0.5" {
+		t.Errorf("ETH-USD exit filled = %s, want 0.5", got.ExitFilledSize)
+	}
+}
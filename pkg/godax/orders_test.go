@@ -1,6 +1,7 @@
 package godax
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
 )
@@ -612,6 +613,48 @@ func TestClient_ListOrders(t *testing.T) {
 	}
 }
 
+func TestClient_ListOrdersPaginates(t *testing.T) {
+	mockClient := MockResponses(`[{"id": "a"}, {"id": "b"}]`, `[{"id": "c"}]`, `[]`)
+	mockClient.Headers = []http.Header{
+		{"Cb-After": []string{"cursor-1"}},
+		{"Cb-After": []string{"cursor-2"}},
+		{},
+	}
+	c := &Client{baseRestURL: baseRestURL, key: key, secret: secret, passphrase: passphrase, httpClient: mockClient}
+
+	qp := QueryParams{ProductIDParam: "BTC-USD"}
+	got, err := c.ListOrders(qp)
+	if err != nil {
+		t.Fatalf("Client.ListOrders() error = %v", err)
+	}
+	if want := []Order{{ID: "a"}, {ID: "b"}, {ID: "c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.ListOrders() = %v, want %v", got, want)
+	}
+	if len(mockClient.Requests) != 3 {
+		t.Fatalf("should have made three requests, but made: %d", len(mockClient.Requests))
+	}
+	for i, wantAfter := range []string{"", "cursor-1", "cursor-2"} {
+		q := mockClient.Requests[i].URL.Query()
+		if q.Get("after") != wantAfter || q.Get("product_id") != "BTC-USD" {
+			t.Errorf("request %d query = %v, want after=%q", i, q, wantAfter)
+		}
+	}
+	if _, ok := qp[AfterParam]; ok {
+		t.Error("ListOrders should not modify the caller's query params")
+	}
+
+	// asking for a page yourself only fetches that page
+	mockClient = MockResponses(`[{"id": "c"}]`)
+	mockClient.Headers = []http.Header{{"Cb-After": []string{"cursor-2"}}}
+	c.httpClient = mockClient
+	if _, err := c.ListOrders(QueryParams{AfterParam: "cursor-1"}); err != nil {
+		t.Fatalf("Client.ListOrders() error = %v", err)
+	}
+	if len(mockClient.Requests) != 1 {
+		t.Errorf("should have made one request, but made: %d", len(mockClient.Requests))
+	}
+}
+
 func TestClient_GetOrderByID(t *testing.T) {
 	type args struct{ orderID string }
 
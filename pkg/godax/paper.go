@@ -0,0 +1,805 @@
+package godax
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Paper trading errors
+var (
+	ErrPaperInsufficientFunds = errors.New("insufficient funds")
+	ErrPaperOrderNotFound     = errors.New("order not found")
+	ErrPaperAccountNotFound   = errors.New("account not found")
+	ErrPaperNoPrice           = errors.New("no market price available for product")
+	ErrPaperPostOnlyCross     = errors.New("post only order would cross the book")
+	ErrPaperInvalidOrder      = errors.New("please provide a valid side, type and size, funds or price for the order")
+)
+
+// Order statuses used by coinbase pro and the paper client.
+const (
+	orderStatusPending = "pending"
+	orderStatusOpen    = "open"
+	orderStatusActive  = "active"
+	orderStatusDone    = "done"
+)
+
+// PaperClient is a simulated trading account implementing OrdersAPI and AccountsAPI. Orders never
+// leave the process: market orders fill immediately at the current ticker, limit orders rest until
+// a price passed to ApplyPrice (or fetched by PollTickers) trades through them, and stop orders
+// trigger the same way. Balances, holds, fills and the ledger are all kept in memory, and every
+// fill is charged the maker or taker rate it was created with. Orders always fill in full.
+type PaperClient struct {
+	mu       sync.Mutex
+	market   MarketDataAPI
+	fees     Fees
+	maker    *big.Rat
+	taker    *big.Rat
+	accounts map[string]*paperAccount
+	orders   map[string]*paperOrder
+	fills    []Fill
+	prices   map[string]*big.Rat
+	volume   map[string]*big.Rat // base currency traded per product
+	notional map[string]*big.Rat // quote currency traded per product
+	orderSeq int
+	tradeID  int
+	ledgerID int
+	now      func() time.Time
+}
+
+type paperAccount struct {
+	id       string
+	currency string
+	balance  *big.Rat
+	hold     *big.Rat
+	ledger   []AccountActivity
+}
+
+type paperOrder struct {
+	seq       int
+	order     Order
+	size      *big.Rat
+	price     *big.Rat
+	stopPrice *big.Rat
+	hold      *big.Rat
+	holdCcy   string
+	triggered bool
+}
+
+// NewPaperClient creates a PaperClient funded with the given balances (currency -> amount). Market
+// data is used to price market orders and to poll for fills. It may be nil when prices are only
+// replayed through ApplyPrice. Pass the result of Client.GetCurrentFees to trade at your real rates.
+func NewPaperClient(market MarketDataAPI, fees Fees, balances map[string]string) (*PaperClient, error) {
+	maker, err := parseDecimal(fees.MakerFeeRate)
+	if err != nil {
+		return nil, err
+	}
+	taker, err := parseDecimal(fees.TakerFeeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PaperClient{
+		market:   market,
+		fees:     fees,
+		maker:    maker,
+		taker:    taker,
+		accounts: make(map[string]*paperAccount),
+		orders:   make(map[string]*paperOrder),
+		prices:   make(map[string]*big.Rat),
+		volume:   make(map[string]*big.Rat),
+		notional: make(map[string]*big.Rat),
+		now:      time.Now,
+	}
+	for currency, amount := range balances {
+		bal, err := parseDecimal(amount)
+		if err != nil {
+			return nil, err
+		}
+		p.account(currency).balance = bal
+	}
+
+	return p, nil
+}
+
+var (
+	_ OrdersAPI   = (*PaperClient)(nil)
+	_ AccountsAPI = (*PaperClient)(nil)
+)
+
+// PlaceOrder simulates placing an order. Market orders and limit orders that cross the current
+// ticker fill immediately as taker. Anything else rests and has its funds put on hold.
+func (p *PaperClient) PlaceOrder(params OrderParams) (Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if params.Type == "" {
+		params.Type = "limit"
+	}
+	base, quote, err := splitProductID(params.ProductID)
+	if err != nil {
+		return Order{}, err
+	}
+	size, err := parseDecimal(params.Size)
+	if err != nil {
+		return Order{}, err
+	}
+	price, err := parseDecimal(params.Price)
+	if err != nil {
+		return Order{}, err
+	}
+	funds, err := parseDecimal(params.Funds)
+	if err != nil {
+		return Order{}, err
+	}
+	stopPrice, err := parseDecimal(params.StopPrice)
+	if err != nil {
+		return Order{}, err
+	}
+	if params.Side != "buy" && params.Side != "sell" {
+		return Order{}, ErrPaperInvalidOrder
+	}
+
+	if params.Stop != "" && (params.Stop != "loss" && params.Stop != "entry" || params.Type != "limit") {
+		return Order{}, ErrPaperInvalidOrder
+	}
+
+	p.orderSeq++
+	o := &paperOrder{
+		seq: p.orderSeq,
+		order: Order{
+			ID:            newUUID(),
+			CreatedAt:     p.timestamp(),
+			FillFees:      "0",
+			FilledSize:    "0",
+			ExecutedValue: "0",
+			Status:        orderStatusPending,
+			OrderParams:   params,
+		},
+		size:      size,
+		price:     price,
+		stopPrice: stopPrice,
+	}
+
+	switch params.Type {
+	case "market":
+		if size.Sign() <= 0 && funds.Sign() <= 0 {
+			return Order{}, ErrPaperInvalidOrder
+		}
+		t, err := p.ticker(params.ProductID)
+		if err != nil {
+			return Order{}, err
+		}
+		fillPrice := t.ask
+		if params.Side == "sell" {
+			fillPrice = t.bid
+		}
+		if fillPrice.Sign() <= 0 {
+			return Order{}, ErrPaperNoPrice
+		}
+		if size.Sign() <= 0 {
+			size = p.sizeForFunds(params.Side, funds, fillPrice)
+		}
+		if err := p.checkFunds(params.Side, base, quote, size, fillPrice, p.taker); err != nil {
+			return Order{}, err
+		}
+		o.size = size
+		p.orders[o.order.ID] = o
+		p.fill(o, fillPrice, "T")
+
+	case "limit":
+		if size.Sign() <= 0 || price.Sign() <= 0 {
+			return Order{}, ErrPaperInvalidOrder
+		}
+		if params.Stop != "" && stopPrice.Sign() <= 0 {
+			return Order{}, ErrPaperInvalidOrder
+		}
+		if err := p.placeHold(o, base, quote); err != nil {
+			return Order{}, err
+		}
+		p.orders[o.order.ID] = o
+
+		if params.Stop != "" {
+			o.order.Status = orderStatusActive
+			break
+		}
+		o.order.Status = orderStatusOpen
+		t, err := p.ticker(params.ProductID)
+		if err == ErrPaperNoPrice {
+			break
+		}
+		if err != nil {
+			p.releaseHold(o)
+			delete(p.orders, o.order.ID)
+			return Order{}, err
+		}
+		if crossPrice, ok := crosses(o, t); ok {
+			if params.PostOnly {
+				p.releaseHold(o)
+				delete(p.orders, o.order.ID)
+				return Order{}, ErrPaperPostOnlyCross
+			}
+			p.fill(o, crossPrice, "T")
+		}
+
+	default:
+		return Order{}, ErrPaperInvalidOrder
+	}
+
+	return o.order, nil
+}
+
+// CancelOrderByID cancels a resting paper order and releases its hold.
+func (p *PaperClient) CancelOrderByID(orderID string, qp QueryParams) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o, ok := p.orders[orderID]
+	if !ok || !o.resting() {
+		return ErrPaperOrderNotFound
+	}
+	p.cancel(o)
+	return nil
+}
+
+// CancelOrderByClientOID cancels a resting paper order by its client OID and releases its hold.
+func (p *PaperClient) CancelOrderByClientOID(clientOID string, qp QueryParams) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, o := range p.orders {
+		if o.order.ClientOID == clientOID && o.resting() {
+			p.cancel(o)
+			return o.order.ID, nil
+		}
+	}
+	return "", ErrPaperOrderNotFound
+}
+
+// CancelAllOrders cancels every resting paper order, or only those for ProductIDParam when it is set.
+func (p *PaperClient) CancelAllOrders(qp QueryParams) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var ids []string
+	for _, o := range p.sortedOrders() {
+		if !o.resting() || (qp[ProductIDParam] != "" && o.order.ProductID != qp[ProductIDParam]) {
+			continue
+		}
+		p.cancel(o)
+		ids = append(ids, o.order.ID)
+	}
+	return ids, nil
+}
+
+// ListOrders lists resting paper orders, newest first. StatusParam and ProductIDParam filter the
+// results the same way they do against coinbase pro.
+func (p *PaperClient) ListOrders(qp QueryParams) ([]Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	orders := []Order{}
+	for _, o := range p.sortedOrders() {
+		if qp[ProductIDParam] != "" && o.order.ProductID != qp[ProductIDParam] {
+			continue
+		}
+		switch status := qp[StatusParam]; status {
+		case "all":
+		case "":
+			if !o.resting() {
+				continue
+			}
+		default:
+			if o.order.Status != status {
+				continue
+			}
+		}
+		orders = append(orders, o.order)
+	}
+	return orders, nil
+}
+
+// GetOrderByID gets a paper order by its ID.
+func (p *PaperClient) GetOrderByID(orderID string) (Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o, ok := p.orders[orderID]
+	if !ok {
+		return Order{}, ErrPaperOrderNotFound
+	}
+	return o.order, nil
+}
+
+// GetOrderByClientOID gets a paper order by its client OID.
+func (p *PaperClient) GetOrderByClientOID(orderClientOID string) (Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, o := range p.orders {
+		if o.order.ClientOID == orderClientOID {
+			return o.order, nil
+		}
+	}
+	return Order{}, ErrPaperOrderNotFound
+}
+
+// ListFills lists paper fills, newest first. Like coinbase pro, either an order_id or product_id is required.
+func (p *PaperClient) ListFills(qp QueryParams) ([]Fill, error) {
+	if qp[ProductIDParam] == "" && qp[OrderIDParam] == "" {
+		return nil, ErrMissingOrderOrProductID
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fills := []Fill{}
+	for i := len(p.fills) - 1; i >= 0; i-- {
+		f := p.fills[i]
+		if qp[ProductIDParam] != "" && f.ProductID != qp[ProductIDParam] {
+			continue
+		}
+		if qp[OrderIDParam] != "" && f.OrderID != qp[OrderIDParam] {
+			continue
+		}
+		fills = append(fills, f)
+	}
+	return fills, nil
+}
+
+// ListAccounts lists the paper accounts, one per currency.
+func (p *PaperClient) ListAccounts() ([]ListAccount, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var currencies []string
+	for c := range p.accounts {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	accounts := []ListAccount{}
+	for _, c := range currencies {
+		a := p.accounts[c]
+		accounts = append(accounts, ListAccount{
+			ID:        a.id,
+			Currency:  a.currency,
+			Balance:   formatDecimal(a.balance),
+			Available: formatDecimal(sub(a.balance, a.hold)),
+			Hold:      formatDecimal(a.hold),
+		})
+	}
+	return accounts, nil
+}
+
+// GetAccount gets a single paper account.
+func (p *PaperClient) GetAccount(accountID string) (Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, err := p.accountByID(accountID)
+	if err != nil {
+		return Account{}, err
+	}
+	return Account{
+		ID:        a.id,
+		Balance:   formatDecimal(a.balance),
+		Holds:     formatDecimal(a.hold),
+		Available: formatDecimal(sub(a.balance, a.hold)),
+		Currency:  a.currency,
+	}, nil
+}
+
+// GetAccountHistory lists the match and fee ledger entries of a paper account, latest first.
+func (p *PaperClient) GetAccountHistory(accountID string) ([]AccountActivity, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, err := p.accountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]AccountActivity, 0, len(a.ledger))
+	for i := len(a.ledger) - 1; i >= 0; i-- {
+		history = append(history, a.ledger[i])
+	}
+	return history, nil
+}
+
+// GetAccountHolds lists the holds placed on a paper account by its resting orders.
+func (p *PaperClient) GetAccountHolds(accountID string) ([]AccountHold, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, err := p.accountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	holds := []AccountHold{}
+	for _, o := range p.sortedOrders() {
+		if !o.resting() || o.holdCcy != a.currency {
+			continue
+		}
+		holds = append(holds, AccountHold{
+			ID:        o.order.ID,
+			AccountID: a.id,
+			CreatedAt: o.order.CreatedAt,
+			UpdatedAt: o.order.CreatedAt,
+			Amount:    formatDecimal(o.hold),
+			Type:      "order",
+			Ref:       o.order.ID,
+		})
+	}
+	return holds, nil
+}
+
+// GetCurrentFees returns the fee rates the paper client charges along with its simulated USD volume.
+func (p *PaperClient) GetCurrentFees() (Fees, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fees := p.fees
+	usd := newRat()
+	for productID, v := range p.notional {
+		if _, quote, _ := splitProductID(productID); quote == "USD" {
+			usd.Add(usd, v)
+		}
+	}
+	fees.USDVolume = formatDecimal(usd)
+	return fees, nil
+}
+
+// GetTrailingVolume returns the simulated volume traded per product, in base currency like the real API.
+func (p *PaperClient) GetTrailingVolume() ([]UserAccount, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var products []string
+	for productID := range p.volume {
+		products = append(products, productID)
+	}
+	sort.Strings(products)
+
+	vols := []UserAccount{}
+	for _, productID := range products {
+		vols = append(vols, UserAccount{
+			ProductID:  productID,
+			Volume:     formatDecimal(p.volume[productID]),
+			RecordedAt: p.timestamp(),
+		})
+	}
+	return vols, nil
+}
+
+// traded returns a product's running total from volume or notional, zero if it hasn't traded.
+func traded(totals map[string]*big.Rat, productID string) *big.Rat {
+	if v, ok := totals[productID]; ok {
+		return v
+	}
+	return newRat()
+}
+
+// ApplyPrice feeds a trade price for a product into the simulation, triggering any stop orders and
+// filling any resting limit orders it trades through. Use it to replay market data or to forward
+// match prices from a streaming feed.
+func (p *PaperClient) ApplyPrice(productID, price string) error {
+	px, err := parseDecimal(price)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prices[productID] = px
+	p.match(productID, paperTicker{price: px, bid: px, ask: px})
+	return nil
+}
+
+// PollTickers fetches the current ticker of every product with resting orders and matches
+// against it. It requires the PaperClient to have been created with market data.
+func (p *PaperClient) PollTickers() error {
+	p.mu.Lock()
+	products := make(map[string]bool)
+	for _, o := range p.orders {
+		if o.resting() {
+			products[o.order.ProductID] = true
+		}
+	}
+	p.mu.Unlock()
+
+	if p.market == nil {
+		return ErrPaperNoPrice
+	}
+	for productID := range products {
+		t, err := p.market.GetProductTicker(productID)
+		if err != nil {
+			return err
+		}
+		pt, err := parseTicker(t)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.prices[productID] = pt.price
+		p.match(productID, pt)
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+type paperTicker struct {
+	price *big.Rat
+	bid   *big.Rat
+	ask   *big.Rat
+}
+
+func parseTicker(t Ticker) (paperTicker, error) {
+	price, err := parseDecimal(t.Price)
+	if err != nil {
+		return paperTicker{}, err
+	}
+	bid, err := parseDecimal(t.Bid)
+	if err != nil {
+		return paperTicker{}, err
+	}
+	ask, err := parseDecimal(t.Ask)
+	if err != nil {
+		return paperTicker{}, err
+	}
+	if bid.Sign() == 0 {
+		bid = price
+	}
+	if ask.Sign() == 0 {
+		ask = price
+	}
+	return paperTicker{price: price, bid: bid, ask: ask}, nil
+}
+
+// ticker returns the current market for a product, from market data when available
+// and otherwise from the last price applied. Callers must hold p.mu.
+func (p *PaperClient) ticker(productID string) (paperTicker, error) {
+	if p.market != nil {
+		t, err := p.market.GetProductTicker(productID)
+		if err != nil {
+			return paperTicker{}, err
+		}
+		pt, err := parseTicker(t)
+		if err != nil {
+			return paperTicker{}, err
+		}
+		p.prices[productID] = pt.price
+		return pt, nil
+	}
+	px, ok := p.prices[productID]
+	if !ok {
+		return paperTicker{}, ErrPaperNoPrice
+	}
+	return paperTicker{price: px, bid: px, ask: px}, nil
+}
+
+// match triggers and fills resting orders for a product against t, oldest first. Callers must hold p.mu.
+func (p *PaperClient) match(productID string, t paperTicker) {
+	orders := p.sortedOrders()
+	for i := len(orders) - 1; i >= 0; i-- {
+		o := orders[i]
+		if o.order.ProductID != productID || !o.resting() {
+			continue
+		}
+		if o.order.Stop != "" && !o.triggered {
+			if !stopTriggered(o, t.price) {
+				continue
+			}
+			o.triggered = true
+			o.order.Status = orderStatusOpen
+			if fillPrice, ok := crosses(o, t); ok {
+				p.fill(o, fillPrice, "T")
+			}
+			continue
+		}
+		if tradesThrough(o, t.price) {
+			p.fill(o, o.price, "M")
+		}
+	}
+}
+
+// stopTriggered follows coinbase pro: a loss stop triggers at or below the stop price and
+// an entry stop triggers at or above it.
+func stopTriggered(o *paperOrder, price *big.Rat) bool {
+	if o.order.Stop == "loss" {
+		return price.Cmp(o.stopPrice) <= 0
+	}
+	return price.Cmp(o.stopPrice) >= 0
+}
+
+// crosses reports whether a limit order would take liquidity at t, and the price it would fill at.
+func crosses(o *paperOrder, t paperTicker) (*big.Rat, bool) {
+	if o.order.Side == "buy" && t.ask.Sign() > 0 && o.price.Cmp(t.ask) >= 0 {
+		return t.ask, true
+	}
+	if o.order.Side == "sell" && t.bid.Sign() > 0 && o.price.Cmp(t.bid) <= 0 {
+		return t.bid, true
+	}
+	return nil, false
+}
+
+// tradesThrough reports whether a trade at price would have filled a resting limit order.
+func tradesThrough(o *paperOrder, price *big.Rat) bool {
+	if o.order.Side == "buy" {
+		return price.Cmp(o.price) <= 0
+	}
+	return price.Cmp(o.price) >= 0
+}
+
+// sizeForFunds works out the base size a market order with funds fills. Buy funds include the fee.
+func (p *PaperClient) sizeForFunds(side string, funds, price *big.Rat) *big.Rat {
+	if side == "buy" {
+		return quo(funds, mul(price, add(big.NewRat(1, 1), p.taker)))
+	}
+	return quo(funds, price)
+}
+
+// checkFunds makes sure the account being debited can cover an immediate fill. Callers must hold p.mu.
+func (p *PaperClient) checkFunds(side, base, quote string, size, price, rate *big.Rat) error {
+	if side == "buy" {
+		value := mul(size, price)
+		need := add(value, mul(value, rate))
+		if p.available(quote).Cmp(need) < 0 {
+			return ErrPaperInsufficientFunds
+		}
+		return nil
+	}
+	if p.available(base).Cmp(size) < 0 {
+		return ErrPaperInsufficientFunds
+	}
+	return nil
+}
+
+// placeHold puts the funds a limit order could use on hold. Buys hold enough quote currency to
+// cover the order at the taker rate. Callers must hold p.mu.
+func (p *PaperClient) placeHold(o *paperOrder, base, quote string) error {
+	if o.order.Side == "buy" {
+		value := mul(o.size, o.price)
+		o.hold = add(value, mul(value, p.taker))
+		o.holdCcy = quote
+	} else {
+		o.hold = o.size
+		o.holdCcy = base
+	}
+	if p.available(o.holdCcy).Cmp(o.hold) < 0 {
+		return ErrPaperInsufficientFunds
+	}
+	a := p.account(o.holdCcy)
+	a.hold = add(a.hold, o.hold)
+	return nil
+}
+
+func (p *PaperClient) releaseHold(o *paperOrder) {
+	if o.hold == nil {
+		return
+	}
+	a := p.account(o.holdCcy)
+	a.hold = sub(a.hold, o.hold)
+	o.hold = nil
+}
+
+func (p *PaperClient) cancel(o *paperOrder) {
+	p.releaseHold(o)
+	o.order.Status = orderStatusDone
+	o.order.Settled = true
+}
+
+// fill executes an order in full at price, moving balances, charging fees and recording the fill
+// and ledger entries. liquidity is "M" or "T". Callers must hold p.mu.
+func (p *PaperClient) fill(o *paperOrder, price *big.Rat, liquidity string) {
+	p.releaseHold(o)
+	base, quote, _ := splitProductID(o.order.ProductID)
+
+	rate := p.taker
+	if liquidity == "M" {
+		rate = p.maker
+	}
+	value := mul(o.size, price)
+	fee := mul(value, rate)
+
+	p.tradeID++
+	details := ActivityDetail{
+		OrderID:   o.order.ID,
+		TradeID:   strconv.Itoa(p.tradeID),
+		ProductID: o.order.ProductID,
+	}
+	if o.order.Side == "buy" {
+		p.post(quote, newRat().Neg(value), "match", details)
+		p.post(base, o.size, "match", details)
+	} else {
+		p.post(base, newRat().Neg(o.size), "match", details)
+		p.post(quote, value, "match", details)
+	}
+	if fee.Sign() != 0 {
+		p.post(quote, newRat().Neg(fee), "fee", details)
+	}
+
+	p.volume[o.order.ProductID] = add(traded(p.volume, o.order.ProductID), o.size)
+	p.notional[o.order.ProductID] = add(traded(p.notional, o.order.ProductID), value)
+
+	p.fills = append(p.fills, Fill{
+		TradeID:   p.tradeID,
+		ProductID: o.order.ProductID,
+		Price:     formatDecimal(price),
+		Size:      formatDecimal(o.size),
+		OrderID:   o.order.ID,
+		CreatedAt: p.timestamp(),
+		Liquidity: liquidity,
+		Fee:       formatDecimal(fee),
+		Settled:   true,
+		Side:      o.order.Side,
+	})
+
+	o.order.Size = formatDecimal(o.size)
+	o.order.FilledSize = formatDecimal(o.size)
+	o.order.ExecutedValue = formatDecimal(value)
+	o.order.FillFees = formatDecimal(fee)
+	o.order.Status = orderStatusDone
+	o.order.Settled = true
+}
+
+// post writes a ledger entry and moves the balance of a currency's account.
+func (p *PaperClient) post(currency string, amount *big.Rat, typ string, details ActivityDetail) {
+	a := p.account(currency)
+	a.balance = add(a.balance, amount)
+	p.ledgerID++
+	a.ledger = append(a.ledger, AccountActivity{
+		ID:        strconv.Itoa(p.ledgerID),
+		CreatedAt: p.timestamp(),
+		Amount:    formatDecimal(amount),
+		Balance:   formatDecimal(a.balance),
+		Type:      typ,
+		Details:   details,
+	})
+}
+
+// account returns the account for a currency, opening an empty one if needed.
+func (p *PaperClient) account(currency string) *paperAccount {
+	a, ok := p.accounts[currency]
+	if !ok {
+		a = &paperAccount{id: newUUID(), currency: currency, balance: newRat(), hold: newRat()}
+		p.accounts[currency] = a
+	}
+	return a
+}
+
+func (p *PaperClient) accountByID(accountID string) (*paperAccount, error) {
+	for _, a := range p.accounts {
+		if a.id == accountID {
+			return a, nil
+		}
+	}
+	return nil, ErrPaperAccountNotFound
+}
+
+func (p *PaperClient) available(currency string) *big.Rat {
+	a, ok := p.accounts[currency]
+	if !ok {
+		return newRat()
+	}
+	return sub(a.balance, a.hold)
+}
+
+// sortedOrders returns every order, newest first.
+func (p *PaperClient) sortedOrders() []*paperOrder {
+	orders := make([]*paperOrder, 0, len(p.orders))
+	for _, o := range p.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].seq > orders[j].seq })
+	return orders
+}
+
+func (p *PaperClient) timestamp() string {
+	return p.now().UTC().Format(time.RFC3339Nano)
+}
+
+func (o *paperOrder) resting() bool {
+	return o.order.Status == orderStatusOpen || o.order.Status == orderStatusActive || o.order.Status == orderStatusPending
+}
@@ -0,0 +1,314 @@
+package godax
+
+import (
+	"testing"
+)
+
+func newTestPaperClient(t *testing.T, market MarketDataAPI, balances map[string]string) *PaperClient {
+	t.Helper()
+	p, err := NewPaperClient(market, Fees{MakerFeeRate: "0.001", TakerFeeRate: "0.002"}, balances)
+	if err != nil {
+		t.Fatalf("NewPaperClient() error = %v", err)
+	}
+	return p
+}
+
+func paperBalances(t *testing.T, p *PaperClient) map[string]ListAccount {
+	t.Helper()
+	accounts, err := p.ListAccounts()
+	if err != nil {
+		t.Fatalf("ListAccounts() error = %v", err)
+	}
+	byCurrency := make(map[string]ListAccount)
+	for _, a := range accounts {
+		byCurrency[a.Currency] = a
+	}
+	return byCurrency
+}
+
+func TestPaperClient_MarketOrder(t *testing.T) {
+	market := &FakeMarketDataAPI{
+		GetProductTickerFunc: func(productID string) (Ticker, error) {
+			return Ticker{Price: "100", Bid: "99", Ask: "101"}, nil
+		},
+	}
+
+	tests := [...]struct {
+		name      string
+		params    OrderParams
+		wantErr   error
+		wantUSD   string
+		wantBTC   string
+		wantFee   string
+		wantSize  string
+		wantValue string
+	}{
+		{
+			name:      "a market buy by size fills at the ask and charges the taker fee",
+			params:    OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Type: "market", Size: "2"}},
+			wantUSD:   "797.596",
+			wantBTC:   "3",
+			wantFee:   "0.404",
+			wantSize:  "2",
+			wantValue: "202",
+		},
+		{
+			name:      "a market buy by funds spends the funds including the fee",
+			params:    OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Type: "market"}, MarketOrderParams: MarketOrderParams{Funds: "101.202"}},
+			wantUSD:   "898.798",
+			wantBTC:   "2",
+			wantFee:   "0.202",
+			wantSize:  "1",
+			wantValue: "101",
+		},
+		{
+			name:      "a market sell fills at the bid and takes the fee from the proceeds",
+			params:    OrderParams{CommonOrderParams: CommonOrderParams{Side: "sell", ProductID: "BTC-USD", Type: "market", Size: "1"}},
+			wantUSD:   "1098.802",
+			wantBTC:   "0",
+			wantFee:   "0.198",
+			wantSize:  "1",
+			wantValue: "99",
+		},
+		{
+			name:    "a market buy larger than the available balance is rejected",
+			params:  OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Type: "market", Size: "10"}},
+			wantErr: ErrPaperInsufficientFunds,
+			wantUSD: "1000",
+			wantBTC: "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPaperClient(t, market, map[string]string{"USD": "1000", "BTC": "1"})
+
+			o, err := p.PlaceOrder(tt.params)
+			if err != tt.wantErr {
+				t.Fatalf("PlaceOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			bals := paperBalances(t, p)
+			if bals["USD"].Balance != tt.wantUSD || bals["BTC"].Balance != tt.wantBTC {
+				t.Errorf("balances USD = %s, BTC = %s, want %s and %s", bals["USD"].Balance, bals["BTC"].Balance, tt.wantUSD, tt.wantBTC)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if o.Status != "done" || o.FillFees != tt.wantFee || o.FilledSize != tt.wantSize || o.ExecutedValue != tt.wantValue {
+				t.Errorf("order = %+v, want done with fees %s, filled size %s and executed value %s", o, tt.wantFee, tt.wantSize, tt.wantValue)
+			}
+
+			fills, err := p.ListFills(QueryParams{OrderIDParam: o.ID})
+			if err != nil {
+				t.Fatalf("ListFills() error = %v", err)
+			}
+			if len(fills) != 1 || fills[0].Liquidity != "T" || fills[0].Fee != tt.wantFee {
+				t.Errorf("fills = %+v, want a single taker fill with fee %s", fills, tt.wantFee)
+			}
+		})
+	}
+}
+
+func TestPaperClient_LimitOrderLifecycle(t *testing.T) {
+	p := newTestPaperClient(t, nil, map[string]string{"USD": "1000"})
+	if err := p.ApplyPrice("BTC-USD", "105"); err != nil {
+		t.Fatalf("ApplyPrice() error = %v", err)
+	}
+
+	o, err := p.PlaceOrder(OrderParams{CommonOrderParams: CommonOrderParams{
+		Side: "buy", ProductID: "BTC-USD", Price: "100", Size: "5", ClientOID: "my-oid",
+	}})
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	if o.Status != "open" || o.Type != "limit" {
+		t.Fatalf("order = %+v, want an open limit order", o)
+	}
+
+	usd := paperBalances(t, p)["USD"]
+	if usd.Hold != "501" || usd.Available != "499" {
+		t.Errorf("USD hold = %s, available = %s, want 501 and 499", usd.Hold, usd.Available)
+	}
+	holds, err := p.GetAccountHolds(usd.ID)
+	if err != nil {
+		t.Fatalf("GetAccountHolds() error = %v", err)
+	}
+	if len(holds) != 1 || holds[0].Ref != o.ID || holds[0].Type != "order" || holds[0].Amount != "501" {
+		t.Errorf("holds = %+v, want one order hold of 501 referencing %s", holds, o.ID)
+	}
+
+	open, err := p.ListOrders(QueryParams{ProductIDParam: "BTC-USD"})
+	if err != nil {
+		t.Fatalf("ListOrders() error = %v", err)
+	}
+	if len(open) != 1 || open[0].ID != o.ID {
+		t.Errorf("ListOrders() = %+v, want the open order", open)
+	}
+
+	if err := p.ApplyPrice("BTC-USD", "100.01"); err != nil {
+		t.Fatalf("ApplyPrice() error = %v", err)
+	}
+	if got, _ := p.GetOrderByClientOID("my-oid"); got.Status != "open" {
+		t.Errorf("order status after a price above the limit = %s, want open", got.Status)
+	}
+
+	if err := p.ApplyPrice("BTC-USD", "99.5"); err != nil {
+		t.Fatalf("ApplyPrice() error = %v", err)
+	}
+	got, err := p.GetOrderByID(o.ID)
+	if err != nil {
+		t.Fatalf("GetOrderByID() error = %v", err)
+	}
+	if got.Status != "done" || got.FillFees != "0.5" || got.ExecutedValue != "500" {
+		t.Errorf("order = %+v, want done at the limit price with a maker fee of 0.5", got)
+	}
+
+	bals := paperBalances(t, p)
+	if bals["USD"].Balance != "499.5" || bals["USD"].Hold != "0" || bals["BTC"].Balance != "5" {
+		t.Errorf("balances = %+v, want 499.5 USD with no hold and 5 BTC", bals)
+	}
+
+	history, err := p.GetAccountHistory(bals["USD"].ID)
+	if err != nil {
+		t.Fatalf("GetAccountHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Type != "fee" || history[1].Type != "match" || history[0].Balance != "499.5" {
+		t.Errorf("history = %+v, want a match then a fee, latest first", history)
+	}
+	if history[1].Details.OrderID != o.ID || history[1].Details.ProductID != "BTC-USD" {
+		t.Errorf("history details = %+v, want order %s on BTC-USD", history[1].Details, o.ID)
+	}
+
+	if open, _ := p.ListOrders(QueryParams{}); len(open) != 0 {
+		t.Errorf("ListOrders() = %+v, want no open orders", open)
+	}
+}
+
+func TestPaperClient_Cancel(t *testing.T) {
+	p := newTestPaperClient(t, nil, map[string]string{"BTC": "3"})
+
+	var ids []string
+	for _, product := range []string{"BTC-USD", "BTC-EUR", "BTC-USD"} {
+		o, err := p.PlaceOrder(OrderParams{CommonOrderParams: CommonOrderParams{
+			Side: "sell", ProductID: product, Price: "100", Size: "1", ClientOID: product,
+		}})
+		if err != nil {
+			t.Fatalf("PlaceOrder() error = %v", err)
+		}
+		ids = append(ids, o.ID)
+	}
+	if btc := paperBalances(t, p)["BTC"]; btc.Hold != "3" {
+		t.Fatalf("BTC hold = %s, want 3", btc.Hold)
+	}
+
+	if err := p.CancelOrderByID(ids[0], nil); err != nil {
+		t.Fatalf("CancelOrderByID() error = %v", err)
+	}
+	if err := p.CancelOrderByID(ids[0], nil); err != ErrPaperOrderNotFound {
+		t.Errorf("canceling twice error = %v, want ErrPaperOrderNotFound", err)
+	}
+
+	canceled, err := p.CancelAllOrders(QueryParams{ProductIDParam: "BTC-USD"})
+	if err != nil {
+		t.Fatalf("CancelAllOrders() error = %v", err)
+	}
+	if len(canceled) != 1 || canceled[0] != ids[2] {
+		t.Errorf("CancelAllOrders() = %v, want [%s]", canceled, ids[2])
+	}
+
+	id, err := p.CancelOrderByClientOID("BTC-EUR", nil)
+	if err != nil || id != ids[1] {
+		t.Errorf("CancelOrderByClientOID() = %s, %v, want %s", id, err, ids[1])
+	}
+	if btc := paperBalances(t, p)["BTC"]; btc.Hold != "0" || btc.Available != "3" {
+		t.Errorf("BTC hold = %s, available = %s, want 0 and 3", btc.Hold, btc.Available)
+	}
+}
+
+func TestPaperClient_StopAndPostOnly(t *testing.T) {
+	t.Run("a loss stop triggers at or below its stop price and then fills", func(t *testing.T) {
+		p := newTestPaperClient(t, nil, map[string]string{"BTC": "1"})
+		o, err := p.PlaceOrder(OrderParams{CommonOrderParams: CommonOrderParams{
+			Side: "sell", ProductID: "BTC-USD", Price: "89", Size: "1", Stop: "loss", StopPrice: "90",
+		}})
+		if err != nil {
+			t.Fatalf("PlaceOrder() error = %v", err)
+		}
+		if o.Status != "active" {
+			t.Fatalf("stop order status = %s, want active", o.Status)
+		}
+
+		p.ApplyPrice("BTC-USD", "95")
+		if got, _ := p.GetOrderByID(o.ID); got.Status != "active" {
+			t.Errorf("status above the stop price = %s, want active", got.Status)
+		}
+		p.ApplyPrice("BTC-USD", "90")
+		got, _ := p.GetOrderByID(o.ID)
+		if got.Status != "done" || got.ExecutedValue != "90" {
+			t.Errorf("order = %+v, want done with an executed value of 90", got)
+		}
+	})
+
+	t.Run("a post only order that would cross is rejected and releases its hold", func(t *testing.T) {
+		p := newTestPaperClient(t, nil, map[string]string{"USD": "1000"})
+		p.ApplyPrice("BTC-USD", "100")
+
+		_, err := p.PlaceOrder(OrderParams{
+			CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Price: "101", Size: "1"},
+			LimitOrderParams:  LimitOrderParams{PostOnly: true},
+		})
+		if err != ErrPaperPostOnlyCross {
+			t.Fatalf("PlaceOrder() error = %v, want ErrPaperPostOnlyCross", err)
+		}
+		if usd := paperBalances(t, p)["USD"]; usd.Hold != "0" {
+			t.Errorf("USD hold = %s, want 0", usd.Hold)
+		}
+	})
+
+	t.Run("polling tickers fills resting orders", func(t *testing.T) {
+		price := "100"
+		market := &FakeMarketDataAPI{GetProductTickerFunc: func(string) (Ticker, error) {
+			return Ticker{Price: price}, nil
+		}}
+		p := newTestPaperClient(t, market, map[string]string{"USD": "1000"})
+		o, err := p.PlaceOrder(OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Price: "95", Size: "1"}})
+		if err != nil {
+			t.Fatalf("PlaceOrder() error = %v", err)
+		}
+
+		price = "94"
+		if err := p.PollTickers(); err != nil {
+			t.Fatalf("PollTickers() error = %v", err)
+		}
+		if got, _ := p.GetOrderByID(o.ID); got.Status != "done" {
+			t.Errorf("status after polling = %s, want done", got.Status)
+		}
+	})
+}
+
+func TestPaperClient_Volume(t *testing.T) {
+	market := &FakeMarketDataAPI{
+		GetProductTickerFunc: func(productID string) (Ticker, error) {
+			return Ticker{Price: "100", Bid: "99", Ask: "101"}, nil
+		},
+	}
+	p := newTestPaperClient(t, market, map[string]string{"USD": "1000"})
+	if _, err := p.PlaceOrder(OrderParams{CommonOrderParams: CommonOrderParams{Side: "buy", ProductID: "BTC-USD", Type: "market", Size: "2"}}); err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+
+	vols, err := p.GetTrailingVolume()
+	if err != nil {
+		t.Fatalf("GetTrailingVolume() error = %v", err)
+	}
+	if len(vols) != 1 || vols[0].ProductID != "BTC-USD" || vols[0].Volume != "2" {
+		t.Errorf("GetTrailingVolume() = %+v, want 2 BTC of BTC-USD", vols)
+	}
+	fees, err := p.GetCurrentFees()
+	if err != nil {
+		t.Fatalf("GetCurrentFees() error = %v", err)
+	}
+	if fees.USDVolume != "202" {
+		t.Errorf("GetCurrentFees() USDVolume = %s, want 202", fees.USDVolume)
+	}
+}
@@ -0,0 +1,372 @@
+package godax
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Position is a snapshot of a product position tracked by a PositionTracker. Amounts are in the
+// product's quote currency unless noted otherwise.
+type Position struct {
+	ProductID string
+
+	// Size is the position in base currency, positive when long and negative when short.
+	Size string
+
+	// AverageEntryPrice is the average price the open position was entered at, zero when flat.
+	AverageEntryPrice string
+
+	// RealizedPnL is the profit or loss locked in by reducing the position, net of every fee paid.
+	RealizedPnL string
+
+	// Fees is the total paid in fees, already taken out of RealizedPnL.
+	Fees string
+
+	// MarkPrice is the latest price the position was marked to, empty before the first mark.
+	MarkPrice string
+
+	// UnrealizedPnL is what closing the open position at MarkPrice would realize, before fees.
+	UnrealizedPnL string
+}
+
+// PnLTotal sums the positions that share a quote currency.
+type PnLTotal struct {
+	Currency      string
+	RealizedPnL   string
+	UnrealizedPnL string
+	Fees          string
+}
+
+// PositionTracker keeps per product positions and profit and loss from fills. Positions use the
+// average cost method: buys and sells that add to a position move its average entry price, and
+// ones that reduce it realize the difference between the fill price and that average. Everything is
+// kept as exact decimals, only the snapshots are rounded.
+//
+// Load history with LoadFills or ApplyFills and keep it current with ApplyFill. Fills are deduplicated,
+// so it is safe to replay history that overlaps fills already applied. Mark open positions with
+// SetMark, HandleFeedMessage or MarkToMarket.
+type PositionTracker struct {
+	mu        sync.Mutex
+	positions map[string]*position
+	seen      map[fillKey]bool
+}
+
+type position struct {
+	productID string
+	quote     string
+	size      *big.Rat
+	avg       *big.Rat
+	realized  *big.Rat
+	fees      *big.Rat
+	mark      *big.Rat
+}
+
+// fillKey identifies a fill. Trade IDs are only unique per product, and a self trade has a buy and a
+// sell fill with the same trade ID, so the order ID is part of the key as well.
+type fillKey struct {
+	productID string
+	tradeID   int
+	orderID   string
+}
+
+// NewPositionTracker creates a PositionTracker with no positions.
+func NewPositionTracker() *PositionTracker {
+	return &PositionTracker{
+		positions: make(map[string]*position),
+		seen:      make(map[fillKey]bool),
+	}
+}
+
+// LoadFills applies the whole fill history of each product from ListFills.
+func (t *PositionTracker) LoadFills(orders OrdersAPI, productIDs ...string) error {
+	for _, productID := range productIDs {
+		fills, err := orders.ListFills(QueryParams{ProductIDParam: productID})
+		if err != nil {
+			return err
+		}
+		if err := t.ApplyFills(fills); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyFills applies fills oldest first, whatever order they come in. ListFills returns them newest first.
+func (t *PositionTracker) ApplyFills(fills []Fill) error {
+	sorted, err := sortFills(fills)
+	if err != nil {
+		return err
+	}
+	for _, f := range sorted {
+		if err := t.ApplyFill(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortFills returns a copy of fills sorted oldest first by their parsed CreatedAt, so timestamps
+// with and without fractional seconds compare correctly. Fills at the same time, or without a
+// CreatedAt, go by TradeID.
+func sortFills(fills []Fill) ([]Fill, error) {
+	type timed struct {
+		fill Fill
+		at   time.Time
+	}
+	ts := make([]timed, len(fills))
+	for i, f := range fills {
+		ts[i].fill = f
+		if f.CreatedAt == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, f.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		ts[i].at = at
+	}
+	sort.SliceStable(ts, func(i, j int) bool {
+		if !ts[i].at.Equal(ts[j].at) {
+			return ts[i].at.Before(ts[j].at)
+		}
+		return ts[i].fill.TradeID < ts[j].fill.TradeID
+	})
+
+	sorted := make([]Fill, len(ts))
+	for i, t := range ts {
+		sorted[i] = t.fill
+	}
+	return sorted, nil
+}
+
+// ApplyFill applies a single fill. A fill that has already been applied is ignored.
+func (t *PositionTracker) ApplyFill(f Fill) error {
+	price, err := parseDecimal(f.Price)
+	if err != nil {
+		return err
+	}
+	size, err := parseDecimal(f.Size)
+	if err != nil {
+		return err
+	}
+	fee, err := parseDecimal(f.Fee)
+	if err != nil {
+		return err
+	}
+	if f.Side == "sell" {
+		size.Neg(size)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := fillKey{productID: f.ProductID, tradeID: f.TradeID, orderID: f.OrderID}
+	if t.seen[key] {
+		return nil
+	}
+	p, err := t.position(f.ProductID)
+	if err != nil {
+		return err
+	}
+	t.seen[key] = true
+
+	p.fees = add(p.fees, fee)
+	p.realized = sub(p.realized, fee)
+
+	// the part of the fill that reduces the position realizes against the average entry price
+	if p.size.Sign() != 0 && p.size.Sign() != size.Sign() {
+		closed := minRat(newRat().Abs(size), newRat().Abs(p.size))
+		if p.size.Sign() < 0 {
+			closed.Neg(closed)
+		}
+		p.realized = add(p.realized, mul(closed, sub(price, p.avg)))
+		p.size = sub(p.size, closed)
+		size = add(size, closed)
+		if p.size.Sign() == 0 {
+			p.avg = newRat()
+		}
+	}
+
+	// whatever is left opens or adds to the position
+	if size.Sign() != 0 {
+		total := add(p.size, size)
+		cost := add(mul(newRat().Abs(p.size), p.avg), mul(newRat().Abs(size), price))
+		p.avg = quo(cost, newRat().Abs(total))
+		p.size = total
+	}
+	return nil
+}
+
+// SetMark marks a product's position to price.
+func (t *PositionTracker) SetMark(productID, price string) error {
+	mark, err := parseDecimal(price)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, err := t.position(productID)
+	if err != nil {
+		return err
+	}
+	p.mark = mark
+	return nil
+}
+
+// HandleFeedMessage marks positions to the price of ticker, match and last_match messages for
+// products the tracker has a position in. Other messages are ignored.
+func (t *PositionTracker) HandleFeedMessage(msg FeedMessage) error {
+	switch msg.Type {
+	case FeedTicker, FeedMatch, FeedLastMatch:
+	default:
+		return nil
+	}
+	t.mu.Lock()
+	_, ok := t.positions[msg.ProductID]
+	t.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return t.SetMark(msg.ProductID, msg.Price)
+}
+
+// MarkToMarket marks every position to the last trade price from GetProductTicker.
+func (t *PositionTracker) MarkToMarket(market MarketDataAPI) error {
+	for _, productID := range t.productIDs() {
+		ticker, err := market.GetProductTicker(productID)
+		if err != nil {
+			return err
+		}
+		if err := t.SetMark(productID, ticker.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Position returns a snapshot of a product's position.
+func (t *PositionTracker) Position(productID string) (Position, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.positions[productID]
+	if !ok {
+		return Position{}, false
+	}
+	return p.snapshot(), true
+}
+
+// Positions returns a snapshot of every position, sorted by product ID.
+func (t *PositionTracker) Positions() []Position {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var positions []Position
+	for _, p := range t.sorted() {
+		positions = append(positions, p.snapshot())
+	}
+	return positions
+}
+
+// Totals sums realized and unrealized PnL and fees per quote currency, sorted by currency.
+// Positions that haven't been marked yet count as no unrealized PnL.
+func (t *PositionTracker) Totals() []PnLTotal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	type sums struct{ realized, unrealized, fees *big.Rat }
+	byQuote := make(map[string]*sums)
+	var currencies []string
+	for _, p := range t.sorted() {
+		s, ok := byQuote[p.quote]
+		if !ok {
+			s = &sums{newRat(), newRat(), newRat()}
+			byQuote[p.quote] = s
+			currencies = append(currencies, p.quote)
+		}
+		s.realized = add(s.realized, p.realized)
+		s.unrealized = add(s.unrealized, p.unrealized())
+		s.fees = add(s.fees, p.fees)
+	}
+	sort.Strings(currencies)
+
+	var totals []PnLTotal
+	for _, c := range currencies {
+		s := byQuote[c]
+		totals = append(totals, PnLTotal{
+			Currency:      c,
+			RealizedPnL:   formatDecimal(s.realized),
+			UnrealizedPnL: formatDecimal(s.unrealized),
+			Fees:          formatDecimal(s.fees),
+		})
+	}
+	return totals
+}
+
+// position returns a product's position, creating a flat one if needed. Callers must hold t.mu.
+func (t *PositionTracker) position(productID string) (*position, error) {
+	if p, ok := t.positions[productID]; ok {
+		return p, nil
+	}
+	_, quote, err := splitProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	p := &position{
+		productID: productID,
+		quote:     quote,
+		size:      newRat(),
+		avg:       newRat(),
+		realized:  newRat(),
+		fees:      newRat(),
+	}
+	t.positions[productID] = p
+	return p, nil
+}
+
+func (t *PositionTracker) productIDs() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ids []string
+	for _, p := range t.sorted() {
+		ids = append(ids, p.productID)
+	}
+	return ids
+}
+
+// sorted returns the positions sorted by product ID. Callers must hold t.mu.
+func (t *PositionTracker) sorted() []*position {
+	positions := make([]*position, 0, len(t.positions))
+	for _, p := range t.positions {
+		positions = append(positions, p)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].productID < positions[j].productID })
+	return positions
+}
+
+func (p *position) unrealized() *big.Rat {
+	if p.mark == nil {
+		return newRat()
+	}
+	return mul(p.size, sub(p.mark, p.avg))
+}
+
+func (p *position) snapshot() Position {
+	s := Position{
+		ProductID:         p.productID,
+		Size:              formatDecimal(p.size),
+		AverageEntryPrice: formatDecimal(p.avg),
+		RealizedPnL:       formatDecimal(p.realized),
+		Fees:              formatDecimal(p.fees),
+		UnrealizedPnL:     formatDecimal(p.unrealized()),
+	}
+	if p.mark != nil {
+		s.MarkPrice = formatDecimal(p.mark)
+	}
+	return s
+}
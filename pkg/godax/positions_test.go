@@ -0,0 +1,134 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPositionTracker_AverageCost(t *testing.T) {
+	tr := NewPositionTracker()
+
+	// ListFills order, newest first
+	fills := []Fill{
+		{TradeID: 4, ProductID: "BTC-USD", OrderID: "o4", CreatedAt: "2020-09-14T14:04:00Z", Side: "buy", Price: "90", Size: "2", Fee: "0.18"},
+		{TradeID: 3, ProductID: "BTC-USD", OrderID: "o3", CreatedAt: "2020-09-14T14:03:00Z", Side: "sell", Price: "130", Size: "1.5", Fee: "0.195"},
+		{TradeID: 2, ProductID: "BTC-USD", OrderID: "o2", CreatedAt: "2020-09-14T14:02:00Z", Side: "buy", Price: "110", Size: "1", Fee: "0.11"},
+		{TradeID: 1, ProductID: "BTC-USD", OrderID: "o1", CreatedAt: "2020-09-14T14:01:00Z", Side: "buy", Price: "100", Size: "1", Fee: "0.1"},
+	}
+	if err := tr.ApplyFills(fills); err != nil {
+		t.Fatalf("ApplyFills() error = %v", err)
+	}
+
+	// 2 @ 105, sell 1.5 @ 130 realizes 37.5, then 0.5 @ 105 + 2 @ 90 = 2.5 @ 93
+	got, _ := tr.Position("BTC-USD")
+	want := Position{
+		ProductID:         "BTC-USD",
+		Size:              "2.5",
+		AverageEntryPrice: "93",
+		RealizedPnL:       "36.915",
+		Fees:              "0.585",
+		UnrealizedPnL:     "0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Position() = %+v, want %+v", got, want)
+	}
+
+	// replaying history is a no-op
+	if err := tr.ApplyFills(fills); err != nil {
+		t.Fatalf("ApplyFills() error = %v", err)
+	}
+	if again, _ := tr.Position("BTC-USD"); !reflect.DeepEqual(again, want) {
+		t.Errorf("Position() after a replay = %+v, want %+v", again, want)
+	}
+
+	if err := tr.HandleFeedMessage(FeedMessage{Type: FeedTicker, ProductID: "BTC-USD", Price: "100.1"}); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	got, _ = tr.Position("BTC-USD")
+	if got.MarkPrice != "100.1" || got.UnrealizedPnL != "17.75" {
+		t.Errorf("Position() = %+v, want 17.75 unrealized at 100.1", got)
+	}
+}
+
+func TestPositionTracker_FlipsThroughFlat(t *testing.T) {
+	tr := NewPositionTracker()
+	fills := []Fill{
+		{TradeID: 1, ProductID: "ETH-BTC", OrderID: "a", CreatedAt: "2020-09-14T14:01:00Z", Side: "buy", Price: "0.03", Size: "10"},
+		{TradeID: 2, ProductID: "ETH-BTC", OrderID: "b", CreatedAt: "2020-09-14T14:02:00Z", Side: "sell", Price: "0.031", Size: "15"},
+		{TradeID: 3, ProductID: "ETH-BTC", OrderID: "c", CreatedAt: "2020-09-14T14:03:00Z", Side: "buy", Price: "0.029", Size: "2"},
+	}
+	if err := tr.ApplyFills(fills); err != nil {
+		t.Fatalf("ApplyFills() error = %v", err)
+	}
+	got, _ := tr.Position("ETH-BTC")
+	if got.Size != "-3" || got.AverageEntryPrice != "0.031" || got.RealizedPnL != "0.014" {
+		t.Errorf("Position() = %+v, want short 3 @ 0.031 with 0.014 realized", got)
+	}
+
+	// exact decimals, 0.1 + 0.2 is 0.3
+	tr = NewPositionTracker()
+	tr.ApplyFill(Fill{TradeID: 1, ProductID: "BTC-USD", OrderID: "a", Side: "buy", Price: "1", Size: "0.1", Fee: "0.1"})
+	tr.ApplyFill(Fill{TradeID: 2, ProductID: "BTC-USD", OrderID: "b", Side: "buy", Price: "1", Size: "0.2", Fee: "0.2"})
+	if got, _ := tr.Position("BTC-USD"); got.Size != "0.3" || got.Fees != "0.3" || got.RealizedPnL != "-0.3" {
+		t.Errorf("Position() = %+v, want exactly 0.3", got)
+	}
+}
+
+func TestPositionTracker_LoadAndMarkToMarket(t *testing.T) {
+	orders := &FakeOrdersAPI{ListFillsFunc: func(qp QueryParams) ([]Fill, error) {
+		switch qp[ProductIDParam] {
+		case "BTC-USD":
+			return []Fill{{TradeID: 1, ProductID: "BTC-USD", OrderID: "a", Side: "buy", Price: "100", Size: "1", Fee: "1"}}, nil
+		case "ETH-USD":
+			return []Fill{{TradeID: 1, ProductID: "ETH-USD", OrderID: "b", Side: "sell", Price: "10", Size: "2", Fee: "0.5"}}, nil
+		}
+		return []Fill{{TradeID: 1, ProductID: "ETH-BTC", OrderID: "c", Side: "buy", Price: "0.1", Size: "1", Fee: "0.001"}}, nil
+	}}
+	market := &FakeMarketDataAPI{GetProductTickerFunc: func(productID string) (Ticker, error) {
+		return map[string]Ticker{"BTC-USD": {Price: "110"}, "ETH-USD": {Price: "11"}, "ETH-BTC": {Price: "0.1"}}[productID], nil
+	}}
+
+	tr := NewPositionTracker()
+	if err := tr.LoadFills(orders, "BTC-USD", "ETH-USD", "ETH-BTC"); err != nil {
+		t.Fatalf("LoadFills() error = %v", err)
+	}
+	if err := tr.MarkToMarket(market); err != nil {
+		t.Fatalf("MarkToMarket() error = %v", err)
+	}
+
+	want := []PnLTotal{
+		{Currency: "BTC", RealizedPnL: "-0.001", UnrealizedPnL: "0", Fees: "0.001"},
+		{Currency: "USD", RealizedPnL: "-1.5", UnrealizedPnL: "8", Fees: "1.5"},
+	}
+	if got := tr.Totals(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Totals() = %+v, want %+v", got, want)
+	}
+	if got := len(tr.Positions()); got != 3 {
+		t.Errorf("Positions() has %d positions, want 3", got)
+	}
+}
+
+func TestSortFills(t *testing.T) {
+	fills := []Fill{
+		{TradeID: 3, CreatedAt: "2020-09-14T14:00:01Z"},
+		{TradeID: 2, CreatedAt: "2020-09-14T14:00:00.5Z"},
+		{TradeID: 4, CreatedAt: "2020-09-14T14:00:00.500Z"},
+		{TradeID: 1, CreatedAt: "2020-09-14T14:00:00Z"},
+	}
+	sorted, err := sortFills(fills)
+	if err != nil {
+		t.Fatalf("sortFills() error = %v", err)
+	}
+	var got []int
+	for _, f := range sorted {
+		got = append(got, f.TradeID)
+	}
+	// compared as strings ".5Z" would sort before "Z"
+	if want := []int{1, 2, 4, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sortFills() trade IDs = %v, want %v", got, want)
+	}
+
+	if _, err := sortFills([]Fill{{CreatedAt: "yesterday"}}); err == nil {
+		t.Error("sortFills() with a bad timestamp should fail")
+	}
+}
@@ -0,0 +1,355 @@
+package godax
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ProfileManager errors
+var (
+	ErrProfileNotManaged     = errors.New("no client has been added for that profile")
+	ErrInsufficientAvailable = errors.New("the profile doesn't have enough available to transfer")
+	ErrInvalidTransfer       = errors.New("please provide a currency and a positive amount to transfer between two different profiles")
+)
+
+// ProfileAccount is one profile's account in an aggregate view.
+type ProfileAccount struct {
+	ProfileID string
+	ListAccount
+}
+
+// ProfileBalance sums a currency's accounts across every managed profile.
+type ProfileBalance struct {
+	Currency  string
+	Balance   string
+	Available string
+	Hold      string
+	Accounts  []ProfileAccount
+}
+
+// ProfileOrder is an order along with the profile it belongs to.
+type ProfileOrder struct {
+	ProfileID string
+	Order
+}
+
+// ProfileFill is a fill along with the profile it belongs to.
+type ProfileFill struct {
+	ProfileID string
+	Fill
+}
+
+// ProfileManager holds a client for each of several coinbase pro profiles, every profile having its
+// own API key, and gives aggregate views across all of them. It also moves funds between them with
+// ProfileTransfer, checking the sending profile has the funds available first.
+type ProfileManager struct {
+	mu       sync.Mutex
+	clients  map[string]API
+	profiles map[string]Profile
+}
+
+// NewProfileManager creates a ProfileManager with no profiles. Add each profile with AddProfile.
+func NewProfileManager() *ProfileManager {
+	return &ProfileManager{
+		clients:  make(map[string]API),
+		profiles: make(map[string]Profile),
+	}
+}
+
+// AddProfile adds the client for a profile, which should be built with that profile's API key (see
+// NewClientWithCredentials). The profile is looked up with GetProfile to check the key can see it.
+func (m *ProfileManager) AddProfile(profileID string, client API) error {
+	p, err := client.GetProfile(profileID)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clients[profileID] = client
+	m.profiles[profileID] = p
+	return nil
+}
+
+// Profiles returns the managed profiles, sorted by ID.
+func (m *ProfileManager) Profiles() []Profile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var profiles []Profile
+	for _, id := range m.ids() {
+		profiles = append(profiles, m.profiles[id])
+	}
+	return profiles
+}
+
+// Client returns the client of a managed profile.
+func (m *ProfileManager) Client(profileID string) (API, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.clients[profileID]
+	return c, ok
+}
+
+// Balances lists the accounts of every profile and sums them by currency, sorted by currency.
+func (m *ProfileManager) Balances() ([]ProfileBalance, error) {
+	type sums struct {
+		balance, available, hold *big.Rat
+		accounts                 []ProfileAccount
+	}
+	byCurrency := make(map[string]*sums)
+	var currencies []string
+
+	for _, id := range m.profileIDs() {
+		accounts, err := m.client(id).ListAccounts()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accounts {
+			balance, err := parseDecimal(a.Balance)
+			if err != nil {
+				return nil, err
+			}
+			available, err := parseDecimal(a.Available)
+			if err != nil {
+				return nil, err
+			}
+			hold, err := parseDecimal(a.Hold)
+			if err != nil {
+				return nil, err
+			}
+			s, ok := byCurrency[a.Currency]
+			if !ok {
+				s = &sums{balance: newRat(), available: newRat(), hold: newRat()}
+				byCurrency[a.Currency] = s
+				currencies = append(currencies, a.Currency)
+			}
+			s.balance = add(s.balance, balance)
+			s.available = add(s.available, available)
+			s.hold = add(s.hold, hold)
+			s.accounts = append(s.accounts, ProfileAccount{ProfileID: id, ListAccount: a})
+		}
+	}
+	sort.Strings(currencies)
+
+	var balances []ProfileBalance
+	for _, c := range currencies {
+		s := byCurrency[c]
+		balances = append(balances, ProfileBalance{
+			Currency:  c,
+			Balance:   formatDecimal(s.balance),
+			Available: formatDecimal(s.available),
+			Hold:      formatDecimal(s.hold),
+			Accounts:  s.accounts,
+		})
+	}
+	return balances, nil
+}
+
+// ListOrders lists the open orders of every profile with qp, oldest first.
+func (m *ProfileManager) ListOrders(qp QueryParams) ([]ProfileOrder, error) {
+	var orders []ProfileOrder
+	for _, id := range m.profileIDs() {
+		list, err := m.client(id).ListOrders(qp)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range list {
+			orders = append(orders, ProfileOrder{ProfileID: id, Order: o})
+		}
+	}
+
+	// compare parsed times, the API doesn't always send fractional seconds
+	at := make(map[string]time.Time, len(orders))
+	for _, o := range orders {
+		if o.CreatedAt == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, o.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		at[o.ID] = t
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		ti, tj := at[orders[i].ID], at[orders[j].ID]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders, nil
+}
+
+// ListFills lists the fills of every profile with qp, newest first like ListFills.
+func (m *ProfileManager) ListFills(qp QueryParams) ([]ProfileFill, error) {
+	var all []Fill
+	profiles := make(map[fillKey]string)
+	for _, id := range m.profileIDs() {
+		list, err := m.client(id).ListFills(qp)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range list {
+			all = append(all, f)
+			profiles[fillKey{productID: f.ProductID, tradeID: f.TradeID, orderID: f.OrderID}] = id
+		}
+	}
+	sorted, err := sortFills(all)
+	if err != nil {
+		return nil, err
+	}
+
+	fills := make([]ProfileFill, len(sorted))
+	for i, f := range sorted {
+		id := profiles[fillKey{productID: f.ProductID, tradeID: f.TradeID, orderID: f.OrderID}]
+		fills[len(sorted)-1-i] = ProfileFill{ProfileID: id, Fill: f}
+	}
+	return fills, nil
+}
+
+// Transfer moves amount of currency from one managed profile to another with the sending profile's
+// ProfileTransfer. It first checks with ListAccounts that the amount is available to the sender.
+func (m *ProfileManager) Transfer(from, to, currency, amount string) error {
+	a, err := parseDecimal(amount)
+	if err != nil {
+		return err
+	}
+	if from == to || currency == "" || a.Sign() <= 0 {
+		return ErrInvalidTransfer
+	}
+	sender := m.client(from)
+	if sender == nil || m.client(to) == nil {
+		return ErrProfileNotManaged
+	}
+
+	available, err := availableIn(sender, currency)
+	if err != nil {
+		return err
+	}
+	if available.Cmp(a) < 0 {
+		return ErrInsufficientAvailable
+	}
+	return sender.ProfileTransfer(TransferParams{From: from, To: to, Currency: currency, Amount: amount})
+}
+
+// RebalancePlan works out the transfers that bring each profile in targets to its target available
+// amount of currency. Profiles above their target send to profiles below theirs, largest surplus
+// and shortfall first. If there isn't enough in total the shortfalls are filled as far as possible.
+func (m *ProfileManager) RebalancePlan(currency string, targets map[string]string) ([]TransferParams, error) {
+	type gap struct {
+		id     string
+		amount *big.Rat
+	}
+	var surplus, shortfall []gap
+
+	ids := make([]string, 0, len(targets))
+	for id := range targets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		target, err := parseDecimal(targets[id])
+		if err != nil {
+			return nil, err
+		}
+		c := m.client(id)
+		if c == nil {
+			return nil, ErrProfileNotManaged
+		}
+		available, err := availableIn(c, currency)
+		if err != nil {
+			return nil, err
+		}
+		switch diff := sub(available, target); diff.Sign() {
+		case 1:
+			surplus = append(surplus, gap{id, diff})
+		case -1:
+			shortfall = append(shortfall, gap{id, diff.Neg(diff)})
+		}
+	}
+	largest := func(gaps []gap) func(i, j int) bool {
+		return func(i, j int) bool { return gaps[i].amount.Cmp(gaps[j].amount) > 0 }
+	}
+	sort.SliceStable(surplus, largest(surplus))
+	sort.SliceStable(shortfall, largest(shortfall))
+
+	var plan []TransferParams
+	for i, j := 0, 0; i < len(surplus) && j < len(shortfall); {
+		amount := minRat(surplus[i].amount, shortfall[j].amount)
+		plan = append(plan, TransferParams{From: surplus[i].id, To: shortfall[j].id, Currency: currency, Amount: formatDecimal(amount)})
+		surplus[i].amount = sub(surplus[i].amount, amount)
+		shortfall[j].amount = sub(shortfall[j].amount, amount)
+		if surplus[i].amount.Sign() == 0 {
+			i++
+		}
+		if shortfall[j].amount.Sign() == 0 {
+			j++
+		}
+	}
+	return plan, nil
+}
+
+// Rebalance runs the transfers of RebalancePlan, checking each one with Transfer. It stops at the
+// first failed transfer and returns the transfers that were made.
+func (m *ProfileManager) Rebalance(currency string, targets map[string]string) ([]TransferParams, error) {
+	plan, err := m.RebalancePlan(currency, targets)
+	if err != nil {
+		return nil, err
+	}
+	var done []TransferParams
+	for _, t := range plan {
+		if err := m.Transfer(t.From, t.To, t.Currency, t.Amount); err != nil {
+			return done, err
+		}
+		done = append(done, t)
+	}
+	return done, nil
+}
+
+func (m *ProfileManager) client(profileID string) API {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.clients[profileID]
+}
+
+func (m *ProfileManager) profileIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ids()
+}
+
+// ids returns the managed profile IDs, sorted. Callers must hold m.mu.
+func (m *ProfileManager) ids() []string {
+	ids := make([]string, 0, len(m.clients))
+	for id := range m.clients {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// availableIn sums what is available of currency across a client's accounts.
+func availableIn(c AccountsAPI, currency string) (*big.Rat, error) {
+	accounts, err := c.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	total := newRat()
+	for _, a := range accounts {
+		if a.Currency != currency {
+			continue
+		}
+		available, err := parseDecimal(a.Available)
+		if err != nil {
+			return nil, err
+		}
+		total = add(total, available)
+	}
+	return total, nil
+}
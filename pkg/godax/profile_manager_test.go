@@ -0,0 +1,221 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeProfile is a FakeAPI for one profile whose transfers move funds between the fake profiles.
+type fakeProfile struct {
+	FakeAPI
+	id        string
+	available map[string]string
+	transfers []TransferParams
+}
+
+func newFakeProfiles(t *testing.T, balances map[string]map[string]string) (*ProfileManager, map[string]*fakeProfile) {
+	t.Helper()
+	m := NewProfileManager()
+	fakes := make(map[string]*fakeProfile)
+	for id, available := range balances {
+		f := &fakeProfile{id: id, available: available}
+		f.GetProfileFunc = func(profileID string) (Profile, error) {
+			return Profile{ID: profileID, Name: "desk " + profileID}, nil
+		}
+		f.ListAccountsFunc = func() ([]ListAccount, error) {
+			var accounts []ListAccount
+			for c, a := range f.available {
+				accounts = append(accounts, ListAccount{ID: f.id + "-" + c, Currency: c, Balance: a, Available: a, Hold: "0"})
+			}
+			return accounts, nil
+		}
+		f.ProfileTransferFunc = func(tp TransferParams) error {
+			f.transfers = append(f.transfers, tp)
+			amount, _ := parseDecimal(tp.Amount)
+			from, _ := parseDecimal(f.available[tp.Currency])
+			to, _ := parseDecimal(fakes[tp.To].available[tp.Currency])
+			f.available[tp.Currency] = formatDecimal(sub(from, amount))
+			fakes[tp.To].available[tp.Currency] = formatDecimal(add(to, amount))
+			return nil
+		}
+		fakes[id] = f
+		if err := m.AddProfile(id, f); err != nil {
+			t.Fatalf("AddProfile() error = %v", err)
+		}
+	}
+	return m, fakes
+}
+
+func TestProfileManager_AggregateViews(t *testing.T) {
+	m, fakes := newFakeProfiles(t, map[string]map[string]string{
+		"a": {"USD": "100", "BTC": "1"},
+		"b": {"USD": "50.5"},
+	})
+	fakes["a"].ListOrdersFunc = func(qp QueryParams) ([]Order, error) {
+		return []Order{{ID: "a1", CreatedAt: "2020-09-14T14:02:00Z"}}, nil
+	}
+	fakes["b"].ListOrdersFunc = func(qp QueryParams) ([]Order, error) {
+		return []Order{{ID: "b1", CreatedAt: "2020-09-14T14:01:00Z"}}, nil
+	}
+	fakes["a"].ListFillsFunc = func(qp QueryParams) ([]Fill, error) {
+		return []Fill{{TradeID: 1, CreatedAt: "2020-09-14T14:01:00Z"}}, nil
+	}
+	fakes["b"].ListFillsFunc = func(qp QueryParams) ([]Fill, error) {
+		return []Fill{{TradeID: 2, CreatedAt: "2020-09-14T14:02:00Z"}}, nil
+	}
+
+	if got := m.Profiles(); len(got) != 2 || got[0].Name != "desk a" {
+		t.Errorf("Profiles() = %+v", got)
+	}
+
+	balances, err := m.Balances()
+	if err != nil {
+		t.Fatalf("Balances() error = %v", err)
+	}
+	if len(balances) != 2 || balances[0].Currency != "BTC" || balances[1].Currency != "USD" {
+		t.Fatalf("Balances() = %+v, want BTC and USD", balances)
+	}
+	usd := balances[1]
+	if usd.Balance != "150.5" || usd.Available != "150.5" || usd.Hold != "0" || len(usd.Accounts) != 2 || usd.Accounts[1].ProfileID != "b" {
+		t.Errorf("USD balance = %+v, want 150.5 across both profiles", usd)
+	}
+
+	orders, err := m.ListOrders(QueryParams{})
+	if err != nil {
+		t.Fatalf("ListOrders() error = %v", err)
+	}
+	if len(orders) != 2 || orders[0].ID != "b1" || orders[0].ProfileID != "b" {
+		t.Errorf("ListOrders() = %+v, want b's older order first", orders)
+	}
+
+	fills, err := m.ListFills(QueryParams{ProductIDParam: "BTC-USD"})
+	if err != nil {
+		t.Fatalf("ListFills() error = %v", err)
+	}
+	if len(fills) != 2 || fills[0].TradeID != 2 || fills[0].ProfileID != "b" {
+		t.Errorf("ListFills() = %+v, want b's newer fill first", fills)
+	}
+}
+
+func TestProfileManager_MergesByParsedTime(t *testing.T) {
+	m, fakes := newFakeProfiles(t, map[string]map[string]string{"a": {}, "b": {}})
+	// "...00Z" sorts after "...00.5Z" as a string but is half a second earlier
+	fakes["a"].ListOrdersFunc = func(qp QueryParams) ([]Order, error) {
+		return []Order{{ID: "a1", CreatedAt: "2020-09-14T14:01:00.5Z"}, {ID: "a2", CreatedAt: "2020-09-14T14:01:00.000Z"}}, nil
+	}
+	fakes["b"].ListOrdersFunc = func(qp QueryParams) ([]Order, error) {
+		return []Order{{ID: "b1", CreatedAt: "2020-09-14T14:01:00Z"}}, nil
+	}
+	fakes["a"].ListFillsFunc = func(qp QueryParams) ([]Fill, error) {
+		return []Fill{{TradeID: 1, OrderID: "a1", CreatedAt: "2020-09-14T14:01:00.5Z"}}, nil
+	}
+	fakes["b"].ListFillsFunc = func(qp QueryParams) ([]Fill, error) {
+		return []Fill{{TradeID: 2, OrderID: "b1", CreatedAt: "2020-09-14T14:01:00Z"}}, nil
+	}
+
+	orders, err := m.ListOrders(QueryParams{})
+	if err != nil {
+		t.Fatalf("ListOrders() error = %v", err)
+	}
+	if len(orders) != 3 || orders[0].ID != "a2" || orders[1].ID != "b1" || orders[2].ID != "a1" {
+		t.Errorf("ListOrders() = %+v, want a2 and b1 by ID at the same time, then a1", orders)
+	}
+
+	fills, err := m.ListFills(QueryParams{})
+	if err != nil {
+		t.Fatalf("ListFills() error = %v", err)
+	}
+	if len(fills) != 2 || fills[0].TradeID != 1 || fills[0].ProfileID != "a" || fills[1].ProfileID != "b" {
+		t.Errorf("ListFills() = %+v, want a's later fill first", fills)
+	}
+
+	fakes["b"].ListOrdersFunc = func(qp QueryParams) ([]Order, error) {
+		return []Order{{ID: "b1", CreatedAt: "yesterday"}}, nil
+	}
+	if _, err := m.ListOrders(QueryParams{}); err == nil {
+		t.Error("ListOrders() with an invalid CreatedAt error = nil, want a parse error")
+	}
+}
+
+func TestProfileManager_Transfer(t *testing.T) {
+	m, fakes := newFakeProfiles(t, map[string]map[string]string{
+		"a": {"USD": "100"},
+		"b": {"USD": "0"},
+	})
+
+	if err := m.Transfer("a", "b", "USD", "100.01"); err != ErrInsufficientAvailable {
+		t.Errorf("Transfer() error = %v, want ErrInsufficientAvailable", err)
+	}
+	if err := m.Transfer("a", "c", "USD", "1"); err != ErrProfileNotManaged {
+		t.Errorf("Transfer() error = %v, want ErrProfileNotManaged", err)
+	}
+	if err := m.Transfer("a", "a", "USD", "1"); err != ErrInvalidTransfer {
+		t.Errorf("Transfer() error = %v, want ErrInvalidTransfer", err)
+	}
+	if len(fakes["a"].transfers) != 0 {
+		t.Fatalf("transfers = %+v, want none after failed checks", fakes["a"].transfers)
+	}
+
+	if err := m.Transfer("a", "b", "USD", "40"); err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+	want := []TransferParams{{From: "a", To: "b", Currency: "USD", Amount: "40"}}
+	if !reflect.DeepEqual(fakes["a"].transfers, want) {
+		t.Errorf("transfers = %+v, want %+v", fakes["a"].transfers, want)
+	}
+}
+
+func TestProfileManager_Rebalance(t *testing.T) {
+	m, fakes := newFakeProfiles(t, map[string]map[string]string{
+		"a": {"USD": "1000"},
+		"b": {"USD": "100"},
+		"c": {"USD": "0"},
+		"d": {"USD": "500"},
+	})
+	targets := map[string]string{"a": "400", "b": "400", "c": "300", "d": "500"}
+
+	plan, err := m.RebalancePlan("USD", targets)
+	if err != nil {
+		t.Fatalf("RebalancePlan() error = %v", err)
+	}
+	want := []TransferParams{
+		{From: "a", To: "b", Currency: "USD", Amount: "300"},
+		{From: "a", To: "c", Currency: "USD", Amount: "300"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Fatalf("RebalancePlan() = %+v, want %+v", plan, want)
+	}
+
+	done, err := m.Rebalance("USD", targets)
+	if err != nil {
+		t.Fatalf("Rebalance() error = %v", err)
+	}
+	if !reflect.DeepEqual(done, want) {
+		t.Errorf("Rebalance() = %+v, want %+v", done, want)
+	}
+	for id, target := range targets {
+		if got := fakes[id].available["USD"]; got != target {
+			t.Errorf("profile %s has %s USD, want %s", id, got, target)
+		}
+	}
+
+	if plan, _ := m.RebalancePlan("USD", targets); len(plan) != 0 {
+		t.Errorf("RebalancePlan() once balanced = %+v, want nothing", plan)
+	}
+	if _, err := m.RebalancePlan("USD", map[string]string{"z": "1"}); err != ErrProfileNotManaged {
+		t.Errorf("RebalancePlan() error = %v, want ErrProfileNotManaged", err)
+	}
+}
+
+func TestNewClientWithCredentials(t *testing.T) {
+	c, err := NewClientWithCredentials(Credentials{Key: key, Secret: secret, Passphrase: passphrase}, true)
+	if err != nil {
+		t.Fatalf("NewClientWithCredentials() error = %v", err)
+	}
+	if c.key != key || c.secret != secret || c.passphrase != passphrase || c.baseRestURL != sandboxREST || c.baseWsURL != sandboxWS {
+		t.Errorf("NewClientWithCredentials() = %+v, want the sandbox client for the key", c)
+	}
+	if _, err := NewClientWithCredentials(Credentials{Key: key}, false); err != ErrMissingCredentials {
+		t.Errorf("NewClientWithCredentials() error = %v, want ErrMissingCredentials", err)
+	}
+}
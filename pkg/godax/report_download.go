@@ -0,0 +1,315 @@
+package godax
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report download errors
+var (
+	ErrReportFailed        = errors.New("coinbase pro failed to create the report")
+	ErrReportNoFileURL     = errors.New("the report is ready but has no file_url")
+	ErrReportUnknownFormat = errors.New("the report file is neither a csv nor a pdf")
+	ErrReportMissingColumn = errors.New("the report csv is missing a column")
+)
+
+// Report status values
+const (
+	ReportPending  = "pending"
+	ReportCreating = "creating"
+	ReportReady    = "ready"
+	ReportFailed   = "failed"
+)
+
+// ReportFill is a row of a csv fills report.
+type ReportFill struct {
+	// Portfolio is the name of the profile the fill belongs to.
+	Portfolio string
+
+	// Total is the fill's price * size plus or minus its fee, in PriceUnit.
+	Total string
+
+	// SizeUnit is the currency of the fill's Size, PriceUnit the currency of its Price, Fee and Total.
+	SizeUnit  string
+	PriceUnit string
+
+	Fill
+}
+
+// ReportActivity is a row of a csv account report.
+type ReportActivity struct {
+	// Portfolio is the name of the profile the activity belongs to.
+	Portfolio string
+
+	// Currency is the currency of the activity's Amount and Balance.
+	Currency string
+
+	// TransferID is set for transfer entries.
+	TransferID string
+
+	AccountActivity
+}
+
+// PDFMetadata is what can be read of a pdf report without rendering it.
+type PDFMetadata struct {
+	Version      string
+	Pages        int
+	Title        string
+	Author       string
+	Creator      string
+	Producer     string
+	CreationDate string
+}
+
+// Report is a downloaded report. Data is the raw file, and depending on its format and the report's
+// type one of Fills, Activities or PDF is filled in.
+type Report struct {
+	Status ReportStatus
+
+	// Format is "csv" or "pdf".
+	Format string
+	Data   []byte
+
+	Fills      []ReportFill
+	Activities []ReportActivity
+	PDF        *PDFMetadata
+}
+
+// FetchReport creates a report, waits for it with WaitForReport and downloads it with DownloadReport.
+// Put a deadline on ctx to bound the wait.
+func FetchReport(ctx context.Context, reports ReportsAPI, httpClient HTTPClient, params ReportParams, interval time.Duration) (Report, error) {
+	status, err := reports.CreateReport(params)
+	if err != nil {
+		return Report{}, err
+	}
+	if status, err = WaitForReport(ctx, reports, status.ID, interval); err != nil {
+		return Report{}, err
+	}
+	return DownloadReport(ctx, httpClient, status)
+}
+
+// defaultReportPollInterval is how often WaitForReport polls when it isn't given a positive interval.
+const defaultReportPollInterval = 2 * time.Second
+
+// WaitForReport polls GetReportStatus every interval (every 2 seconds if it isn't positive) until
+// the report is ready and returns its status. It returns ctx's error if ctx is done first, and
+// ErrReportFailed if the report fails.
+func WaitForReport(ctx context.Context, reports ReportsAPI, reportID string, interval time.Duration) (ReportStatus, error) {
+	if interval <= 0 {
+		interval = defaultReportPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := reports.GetReportStatus(reportID)
+		if err != nil {
+			return ReportStatus{}, err
+		}
+		switch status.Status {
+		case ReportReady:
+			return status, nil
+		case ReportFailed:
+			return status, ErrReportFailed
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DownloadReport downloads a ready report from its FileURL with httpClient (http.DefaultClient when
+// nil) and parses it. The file URL is pre-signed so the request is not signed with the API key.
+func DownloadReport(ctx context.Context, httpClient HTTPClient, status ReportStatus) (Report, error) {
+	if status.FileURL == "" {
+		return Report{}, ErrReportNoFileURL
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodGet, status.FileURL, nil)
+	if err != nil {
+		return Report{}, err
+	}
+	res, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return Report{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Report{}, fmt.Errorf("downloading report %s: %s", status.ID, res.Status)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return Report{}, err
+	}
+	return ParseReport(status, data)
+}
+
+// ParseReport parses a downloaded report file. The format is sniffed from the file itself.
+func ParseReport(status ReportStatus, data []byte) (Report, error) {
+	r := Report{Status: status, Data: data}
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		r.Format = "pdf"
+		r.PDF = parsePDFMetadata(data)
+		return r, nil
+	}
+	if status.Params.Format == "pdf" {
+		return r, ErrReportUnknownFormat
+	}
+	r.Format = "csv"
+
+	var err error
+	switch status.Type {
+	case "fills":
+		r.Fills, err = ParseFillsReport(bytes.NewReader(data))
+	case "account":
+		r.Activities, err = ParseAccountReport(bytes.NewReader(data))
+	default:
+		err = ErrReportUnknownFormat
+	}
+	return r, err
+}
+
+// ParseFillsReport parses a csv fills report, which has the columns: portfolio, trade id, product,
+// side, created at, size, size unit, price, fee, total and price/fee/total unit.
+func ParseFillsReport(r io.Reader) ([]ReportFill, error) {
+	rows, err := readReportCSV(r, "portfolio", "trade id", "product", "side", "created at", "size", "size unit", "price", "fee", "total", "price/fee/total unit")
+	if err != nil {
+		return nil, err
+	}
+	fills := []ReportFill{}
+	for _, row := range rows {
+		tradeID, err := strconv.Atoi(row["trade id"])
+		if err != nil {
+			return nil, fmt.Errorf("parsing trade id: %w", err)
+		}
+		fills = append(fills, ReportFill{
+			Portfolio: row["portfolio"],
+			Total:     row["total"],
+			SizeUnit:  row["size unit"],
+			PriceUnit: row["price/fee/total unit"],
+			Fill: Fill{
+				TradeID:   tradeID,
+				ProductID: row["product"],
+				Side:      strings.ToLower(row["side"]),
+				CreatedAt: row["created at"],
+				Size:      row["size"],
+				Price:     row["price"],
+				Fee:       row["fee"],
+				Settled:   true,
+			},
+		})
+	}
+	return fills, nil
+}
+
+// ParseAccountReport parses a csv account report, which has the columns: portfolio, type, time,
+// amount, balance, amount/balance unit, transfer id, trade id and order id.
+func ParseAccountReport(r io.Reader) ([]ReportActivity, error) {
+	rows, err := readReportCSV(r, "portfolio", "type", "time", "amount", "balance", "amount/balance unit", "transfer id", "trade id", "order id")
+	if err != nil {
+		return nil, err
+	}
+	activities := []ReportActivity{}
+	for _, row := range rows {
+		activities = append(activities, ReportActivity{
+			Portfolio:  row["portfolio"],
+			Currency:   row["amount/balance unit"],
+			TransferID: row["transfer id"],
+			AccountActivity: AccountActivity{
+				CreatedAt: row["time"],
+				Amount:    row["amount"],
+				Balance:   row["balance"],
+				Type:      row["type"],
+				Details: ActivityDetail{
+					OrderID: row["order id"],
+					TradeID: row["trade id"],
+				},
+			},
+		})
+	}
+	return activities, nil
+}
+
+// readReportCSV reads a report csv into rows keyed by lower cased column name, checking the columns
+// are all there. Columns can come in any order.
+func readReportCSV(r io.Reader, columns ...string) ([]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, c := range columns {
+		if _, ok := index[c]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrReportMissingColumn, c)
+		}
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string)
+		for _, c := range columns {
+			if i := index[c]; i < len(record) {
+				row[c] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+var (
+	pdfVersion = regexp.MustCompile(`^%PDF-(\d+\.\d+)`)
+	pdfPage    = regexp.MustCompile(`/Type\s*/Page[^s]`)
+	pdfInfo    = regexp.MustCompile(`/(Title|Author|Creator|Producer|CreationDate)\s*\(((?:[^()\\]|\\.)*)\)`)
+)
+
+// parsePDFMetadata reads the version, page count and uncompressed info dictionary of a pdf.
+func parsePDFMetadata(data []byte) *PDFMetadata {
+	m := &PDFMetadata{}
+	if v := pdfVersion.FindSubmatch(data); v != nil {
+		m.Version = string(v[1])
+	}
+	m.Pages = len(pdfPage.FindAll(data, -1))
+	for _, match := range pdfInfo.FindAllSubmatch(data, -1) {
+		value := string(match[2])
+		switch string(match[1]) {
+		case "Title":
+			m.Title = value
+		case "Author":
+			m.Author = value
+		case "Creator":
+			m.Creator = value
+		case "Producer":
+			m.Producer = value
+		case "CreationDate":
+			m.CreationDate = value
+		}
+	}
+	return m
+}
@@ -0,0 +1,139 @@
+package godax
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	fillsReportCSV = "portfolio,trade id,product,side,created at,size,size unit,price,fee,total,price/fee/total unit\n" +
+		"default,74,BTC-USD,BUY,2020-09-14T14:01:00.123Z,0.01,BTC,10000.00,0.50,-100.50,USD\n" +
+		"default,75,BTC-USD,SELL,2020-09-14T14:02:00.456Z,0.01,BTC,10100.00,0.50,100.50,USD\n"
+	accountReportCSV = "portfolio,type,time,amount,balance,amount/balance unit,transfer id,trade id,order id\n" +
+		"default,deposit,2020-09-14T14:00:00.000Z,1000,1000,USD,8a9b7c,,\n" +
+		"default,match,2020-09-14T14:01:00.123Z,-100,900,USD,,74,d50ec984-77a8-460a-b958-66f114b0de9b\n" +
+		"default,fee,2020-09-14T14:01:00.123Z,-0.5,899.5,USD,,74,d50ec984-77a8-460a-b958-66f114b0de9b\n"
+	pdfReport = "%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n" +
+		"2 0 obj << /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >> endobj\n" +
+		"3 0 obj << /Type /Page /Parent 2 0 R >> endobj\n4 0 obj << /Type /Page /Parent 2 0 R >> endobj\n" +
+		"5 0 obj << /Title (Account Statement \\(September\\)) /Producer (Coinbase) /CreationDate (D:20200914140000Z) >> endobj\n%%EOF"
+)
+
+func reportServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(file))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchReport_Fills(t *testing.T) {
+	srv := reportServer(t, map[string]string{"/fills.csv": fillsReportCSV})
+
+	polls := 0
+	reports := &FakeReportsAPI{
+		CreateReportFunc: func(p ReportParams) (ReportStatus, error) {
+			return ReportStatus{ID: "r1", Type: p.Type, Status: ReportPending, Params: p}, nil
+		},
+		GetReportStatusFunc: func(id string) (ReportStatus, error) {
+			polls++
+			s := ReportStatus{ID: id, Type: "fills", Status: ReportCreating, Params: ReportParams{Type: "fills", Format: "csv"}}
+			if polls == 3 {
+				s.Status, s.FileURL = ReportReady, srv.URL+"/fills.csv"
+			}
+			return s, nil
+		},
+	}
+
+	r, err := FetchReport(context.Background(), reports, nil, ReportParams{Type: "fills", ProductID: "BTC-USD", Format: "csv"}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("FetchReport() error = %v", err)
+	}
+	if polls != 3 || r.Format != "csv" || r.Status.Status != ReportReady {
+		t.Fatalf("FetchReport() = %+v after %d polls, want a ready csv after 3", r, polls)
+	}
+	want := []ReportFill{
+		{Portfolio: "default", Total: "-100.50", SizeUnit: "BTC", PriceUnit: "USD", Fill: Fill{
+			TradeID: 74, ProductID: "BTC-USD", Side: "buy", CreatedAt: "2020-09-14T14:01:00.123Z", Size: "0.01", Price: "10000.00", Fee: "0.50", Settled: true,
+		}},
+		{Portfolio: "default", Total: "100.50", SizeUnit: "BTC", PriceUnit: "USD", Fill: Fill{
+			TradeID: 75, ProductID: "BTC-USD", Side: "sell", CreatedAt: "2020-09-14T14:02:00.456Z", Size: "0.01", Price: "10100.00", Fee: "0.50", Settled: true,
+		}},
+	}
+	if !reflect.DeepEqual(r.Fills, want) {
+		t.Errorf("Fills = %+v, want %+v", r.Fills, want)
+	}
+}
+
+func TestDownloadReport_AccountAndPDF(t *testing.T) {
+	srv := reportServer(t, map[string]string{"/account.csv": accountReportCSV, "/account.pdf": pdfReport})
+
+	r, err := DownloadReport(context.Background(), nil, ReportStatus{ID: "r2", Type: "account", Status: ReportReady, FileURL: srv.URL + "/account.csv"})
+	if err != nil {
+		t.Fatalf("DownloadReport() error = %v", err)
+	}
+	if len(r.Activities) != 3 {
+		t.Fatalf("Activities = %+v, want 3", r.Activities)
+	}
+	fee := r.Activities[2]
+	if fee.Type != "fee" || fee.Amount != "-0.5" || fee.Balance != "899.5" || fee.Currency != "USD" ||
+		fee.Details.TradeID != "74" || fee.Details.OrderID != "d50ec984-77a8-460a-b958-66f114b0de9b" {
+		t.Errorf("fee activity = %+v", fee)
+	}
+	if r.Activities[0].TransferID != "8a9b7c" {
+		t.Errorf("deposit activity = %+v, want its transfer id", r.Activities[0])
+	}
+
+	r, err = DownloadReport(context.Background(), nil, ReportStatus{ID: "r3", Type: "account", Status: ReportReady, FileURL: srv.URL + "/account.pdf"})
+	if err != nil {
+		t.Fatalf("DownloadReport() error = %v", err)
+	}
+	want := &PDFMetadata{Version: "1.4", Pages: 2, Title: `Account Statement \(September\)`, Producer: "Coinbase", CreationDate: "D:20200914140000Z"}
+	if r.Format != "pdf" || !reflect.DeepEqual(r.PDF, want) {
+		t.Errorf("DownloadReport() = %s %+v, want pdf %+v", r.Format, r.PDF, want)
+	}
+
+	if _, err := DownloadReport(context.Background(), nil, ReportStatus{ID: "r4", FileURL: srv.URL + "/gone.csv"}); err == nil {
+		t.Error("DownloadReport() error = nil for a missing file")
+	}
+	if _, err := DownloadReport(context.Background(), nil, ReportStatus{ID: "r5"}); err != ErrReportNoFileURL {
+		t.Errorf("DownloadReport() error = %v, want ErrReportNoFileURL", err)
+	}
+}
+
+func TestWaitForReport_Deadline(t *testing.T) {
+	reports := &FakeReportsAPI{GetReportStatusFunc: func(id string) (ReportStatus, error) {
+		return ReportStatus{ID: id, Status: ReportPending}, nil
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForReport(ctx, reports, "r1", time.Millisecond); err != context.DeadlineExceeded {
+		t.Errorf("WaitForReport() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	reports.GetReportStatusFunc = func(id string) (ReportStatus, error) {
+		return ReportStatus{ID: id, Status: ReportFailed}, nil
+	}
+	if _, err := WaitForReport(context.Background(), reports, "r1", time.Millisecond); err != ErrReportFailed {
+		t.Errorf("WaitForReport() error = %v, want ErrReportFailed", err)
+	}
+	if _, err := WaitForReport(context.Background(), reports, "r1", 0); err != ErrReportFailed {
+		t.Errorf("WaitForReport() with no interval error = %v, want ErrReportFailed", err)
+	}
+}
+
+func TestParseFillsReport_MissingColumn(t *testing.T) {
+	if _, err := ParseReport(ReportStatus{Type: "fills"}, []byte("portfolio,trade id\ndefault,1\n")); err == nil {
+		t.Error("ParseReport() error = nil, want a missing column")
+	}
+}
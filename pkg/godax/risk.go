@@ -0,0 +1,443 @@
+package godax
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Risk check rejections. PlaceOrder on a RiskGuard returns them wrapped in a *RiskError, test for
+// them with errors.Is.
+var (
+	ErrProductNotAllowed = errors.New("product is not in the allowed product list")
+	ErrMaxOrderNotional  = errors.New("order notional is over the max order notional")
+	ErrMaxPosition       = errors.New("order could take the position over the max position")
+	ErrMaxOpenOrders     = errors.New("there are already the max number of open orders")
+	ErrDailyLossLimit    = errors.New("the daily loss limit has been reached")
+	ErrPriceBand         = errors.New("order price is outside of the price band around the ticker")
+	ErrMaxSlippage       = errors.New("market order would slip more than the max slippage")
+	ErrInvalidRiskConfig = errors.New("risk limits must be positive decimals, and the position and daily loss limits need a PositionTracker")
+)
+
+// RiskError is a PlaceOrder rejected by a RiskGuard check.
+type RiskError struct {
+	// Err is the check that failed, one of the risk check rejection errors.
+	Err error
+
+	ProductID string
+
+	// Detail says by how much the check failed.
+	Detail string
+}
+
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("risk check rejected %s order: %s (%s)", e.ProductID, e.Err, e.Detail)
+}
+
+// Unwrap returns the check that failed.
+func (e *RiskError) Unwrap() error {
+	return e.Err
+}
+
+// RiskConfig configures a RiskGuard. Every limit is optional, leave it empty (or zero) to skip its check.
+type RiskConfig struct {
+	// AllowedProducts are the only products orders can be placed for.
+	AllowedProducts []string
+
+	// MaxOrderNotional caps the value of a single order in its quote currency. Limit orders are
+	// valued at their price, market orders at their funds or at the ticker price.
+	MaxOrderNotional string
+
+	// MaxPosition caps the position per product in base currency, long or short. The check assumes
+	// every open order of the product fills along with the new one.
+	MaxPosition map[string]string
+
+	// MaxOpenOrders caps the number of open orders across every product.
+	MaxOpenOrders int
+
+	// DailyLossLimit stops all new orders once the day's (UTC) realized plus unrealized loss in
+	// DailyLossCurrency reaches it, until the next day. The loss counts from the PnL the guard sees
+	// at its first check of the day. A new guard would start counting again, so save what
+	// OnDayStart gets and pass it back as DayStart when restarting.
+	DailyLossLimit    string
+	DailyLossCurrency string
+
+	// DayStart restores the start of day PnL saved from OnDayStart. It is only used on its own
+	// day, a DayStart from an earlier day is ignored.
+	DayStart RiskDayStart
+
+	// OnDayStart is called with the start of day PnL each time the guard takes a new one.
+	OnDayStart func(RiskDayStart)
+
+	// PriceBandPercent rejects limit orders priced further than this percentage from the ticker's last
+	// trade price, catching fat-fingered prices.
+	PriceBandPercent string
+
+	// MaxSlippageBps rejects market orders whose estimated slippage (see EstimateSlippage) is over this
+	// many basis points, or that the book is too thin to fill.
+	MaxSlippageBps string
+
+	// OrderBook supplies the books for MaxSlippageBps, a locally maintained book say. When nil the
+	// level 2 book is fetched with GetProductOrderBook for every market order.
+	OrderBook func(productID string) (OrderBook, error)
+
+	// Positions supplies the positions and PnL for MaxPosition and DailyLossLimit. Keep it up to date
+	// with fills yourself.
+	Positions *PositionTracker
+
+	// OpenOrders supplies the open orders for MaxPosition and MaxOpenOrders. When nil they are looked
+	// up with ListOrders for every order.
+	OpenOrders *OrderTracker
+
+	// Logger gets a line for every rejected order. Defaults to stderr.
+	Logger Logger
+}
+
+// RiskDayStart is the PnL in DailyLossCurrency at the start of a (UTC) day.
+type RiskDayStart struct {
+	// Day is the UTC date, for example "2020-09-14".
+	Day string
+	PnL string
+}
+
+// RiskGuard is an OrdersAPI that runs pre-trade risk checks in front of another OrdersAPI's
+// PlaceOrder. Orders that pass are sent on as they are, orders that fail are logged and rejected
+// with a *RiskError. Every other method goes straight through.
+//
+// PlaceOrder checks and places one order at a time, so orders placed from several goroutines can't
+// all pass against the same open orders and positions. Orders placed around the guard, straight
+// through the wrapped OrdersAPI, aren't held up by it.
+type RiskGuard struct {
+	OrdersAPI
+	market   MarketDataAPI
+	cfg      RiskConfig
+	allowed  map[string]bool
+	notional *big.Rat
+	position map[string]*big.Rat
+	maxLoss  *big.Rat
+	band     *big.Rat
+	slippage *big.Rat
+	placing  sync.Mutex
+	mu       sync.Mutex
+	day      string
+	dayStart *big.Rat
+	now      func() time.Time
+}
+
+var _ OrdersAPI = (*RiskGuard)(nil)
+
+// NewRiskGuard creates a RiskGuard that places orders through orders once they pass the checks
+// configured by cfg. market is used for ticker prices.
+func NewRiskGuard(orders OrdersAPI, market MarketDataAPI, cfg RiskConfig) (*RiskGuard, error) {
+	g := &RiskGuard{
+		OrdersAPI: orders,
+		market:    market,
+		cfg:       cfg,
+		position:  make(map[string]*big.Rat),
+		now:       time.Now,
+	}
+	if g.cfg.Logger == nil {
+		g.cfg.Logger = defaultLogger()
+	}
+	if len(cfg.AllowedProducts) > 0 {
+		g.allowed = make(map[string]bool)
+		for _, p := range cfg.AllowedProducts {
+			g.allowed[p] = true
+		}
+	}
+
+	var err error
+	if g.notional, err = positiveLimit(cfg.MaxOrderNotional); err != nil {
+		return nil, err
+	}
+	if g.maxLoss, err = positiveLimit(cfg.DailyLossLimit); err != nil {
+		return nil, err
+	}
+	if g.band, err = positiveLimit(cfg.PriceBandPercent); err != nil {
+		return nil, err
+	}
+	if g.slippage, err = positiveLimit(cfg.MaxSlippageBps); err != nil {
+		return nil, err
+	}
+	for productID, max := range cfg.MaxPosition {
+		if g.position[productID], err = positiveLimit(max); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.MaxOpenOrders < 0 || (cfg.Positions == nil && (len(g.position) > 0 || g.maxLoss != nil)) {
+		return nil, ErrInvalidRiskConfig
+	}
+	if cfg.DayStart.Day != "" {
+		if _, err := time.Parse(riskDayLayout, cfg.DayStart.Day); err != nil {
+			return nil, ErrInvalidRiskConfig
+		}
+		if g.dayStart, err = parseDecimal(cfg.DayStart.PnL); err != nil {
+			return nil, err
+		}
+		g.day = cfg.DayStart.Day
+	}
+	return g, nil
+}
+
+// PlaceOrder runs the risk checks and places the order if they all pass. With OpenOrders the placed
+// order is added to the tracker right away, so the next check counts it before the feed reports it.
+func (g *RiskGuard) PlaceOrder(order OrderParams) (Order, error) {
+	g.placing.Lock()
+	defer g.placing.Unlock()
+
+	if err := g.Check(order); err != nil {
+		return Order{}, err
+	}
+	o, err := g.OrdersAPI.PlaceOrder(order)
+	if err != nil {
+		return o, err
+	}
+	if g.cfg.OpenOrders != nil {
+		g.cfg.OpenOrders.track(o)
+	}
+	return o, nil
+}
+
+// Check runs the risk checks against an order without placing it. Rejections are logged.
+func (g *RiskGuard) Check(order OrderParams) error {
+	err := g.check(order)
+	if re, ok := err.(*RiskError); ok {
+		g.cfg.Logger.Printf("risk check rejected %s %s order (size %q, price %q, funds %q): %s (%s)",
+			order.ProductID, order.Side, order.Size, order.Price, order.Funds, re.Err, re.Detail)
+	}
+	return err
+}
+
+func (g *RiskGuard) check(order OrderParams) error {
+	reject := func(err error, format string, v ...interface{}) error {
+		return &RiskError{Err: err, ProductID: order.ProductID, Detail: fmt.Sprintf(format, v...)}
+	}
+
+	if g.allowed != nil && !g.allowed[order.ProductID] {
+		return reject(ErrProductNotAllowed, "allowed %v", g.cfg.AllowedProducts)
+	}
+
+	if g.maxLoss != nil {
+		loss, err := g.dailyLoss()
+		if err != nil {
+			return err
+		}
+		if loss.Cmp(g.maxLoss) >= 0 {
+			return reject(ErrDailyLossLimit, "lost %s %s today, limit %s", formatDecimal(loss), g.cfg.DailyLossCurrency, g.cfg.DailyLossLimit)
+		}
+	}
+
+	size, err := parseDecimal(order.Size)
+	if err != nil {
+		return err
+	}
+	price, err := parseDecimal(order.Price)
+	if err != nil {
+		return err
+	}
+	isMarket := order.Type == "market"
+
+	var last *big.Rat
+	ticker := func() (*big.Rat, error) {
+		if last != nil {
+			return last, nil
+		}
+		t, err := g.market.GetProductTicker(order.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		last, err = parseDecimal(t.Price)
+		return last, err
+	}
+
+	if g.band != nil && !isMarket {
+		ref, err := ticker()
+		if err != nil {
+			return err
+		}
+		maxMove := quo(mul(ref, g.band), newRat().SetInt64(100))
+		if newRat().Abs(sub(price, ref)).Cmp(maxMove) > 0 {
+			return reject(ErrPriceBand, "price %s is more than %s%% from %s", order.Price, g.cfg.PriceBandPercent, formatDecimal(ref))
+		}
+	}
+
+	if g.notional != nil {
+		notional := mul(price, size)
+		if isMarket {
+			if order.Funds != "" {
+				if notional, err = parseDecimal(order.Funds); err != nil {
+					return err
+				}
+			} else {
+				ref, err := ticker()
+				if err != nil {
+					return err
+				}
+				notional = mul(ref, size)
+			}
+		}
+		if notional.Cmp(g.notional) > 0 {
+			return reject(ErrMaxOrderNotional, "notional %s, max %s", formatDecimal(notional), g.cfg.MaxOrderNotional)
+		}
+	}
+
+	if g.slippage != nil && isMarket {
+		e, err := g.estimateSlippage(order)
+		if err != nil {
+			return err
+		}
+		if !e.Complete {
+			return reject(ErrMaxSlippage, "the book only fills size %s, funds %s", e.Size, e.Funds)
+		}
+		bps, err := parseDecimal(e.SlippageBps)
+		if err != nil {
+			return err
+		}
+		if bps.Cmp(g.slippage) > 0 {
+			return reject(ErrMaxSlippage, "slippage %s bps to %s, max %s bps", e.SlippageBps, e.WorstPrice, g.cfg.MaxSlippageBps)
+		}
+	}
+
+	max, limitPosition := g.position[order.ProductID]
+	if !limitPosition && g.cfg.MaxOpenOrders == 0 {
+		return nil
+	}
+	open, err := g.openOrders()
+	if err != nil {
+		return err
+	}
+	if g.cfg.MaxOpenOrders > 0 && len(open) >= g.cfg.MaxOpenOrders {
+		return reject(ErrMaxOpenOrders, "%d open, max %d", len(open), g.cfg.MaxOpenOrders)
+	}
+	if !limitPosition {
+		return nil
+	}
+
+	if isMarket && size.Sign() == 0 {
+		// a market order by funds, size it at the ticker
+		funds, err := parseDecimal(order.Funds)
+		if err != nil {
+			return err
+		}
+		ref, err := ticker()
+		if err != nil {
+			return err
+		}
+		if ref.Sign() > 0 {
+			size = quo(funds, ref)
+		}
+	}
+	current := newRat()
+	if p, ok := g.cfg.Positions.Position(order.ProductID); ok {
+		if current, err = parseDecimal(p.Size); err != nil {
+			return err
+		}
+	}
+	long, short := newRat().Set(current), newRat().Neg(current)
+	for _, o := range open {
+		if o.ProductID != order.ProductID {
+			continue
+		}
+		remaining, err := remainingSize(o)
+		if err != nil {
+			return err
+		}
+		if o.Side == "buy" {
+			long = add(long, remaining)
+		} else {
+			short = add(short, remaining)
+		}
+	}
+	worst, side := add(long, size), "long"
+	if order.Side == "sell" {
+		worst, side = add(short, size), "short"
+	}
+	if worst.Cmp(max) > 0 {
+		return reject(ErrMaxPosition, "could reach %s %s, max %s", formatDecimal(worst), side, formatDecimal(max))
+	}
+	return nil
+}
+
+// riskDayLayout formats the UTC day the daily loss limit counts over.
+const riskDayLayout = "2006-01-02"
+
+// dailyLoss is how much PnL in DailyLossCurrency has dropped since the start of the (UTC) day.
+func (g *RiskGuard) dailyLoss() (*big.Rat, error) {
+	pnl := newRat()
+	for _, t := range g.cfg.Positions.Totals() {
+		if t.Currency != g.cfg.DailyLossCurrency {
+			continue
+		}
+		realized, err := parseDecimal(t.RealizedPnL)
+		if err != nil {
+			return nil, err
+		}
+		unrealized, err := parseDecimal(t.UnrealizedPnL)
+		if err != nil {
+			return nil, err
+		}
+		pnl = add(realized, unrealized)
+	}
+
+	g.mu.Lock()
+	var started *RiskDayStart
+	if day := g.now().UTC().Format(riskDayLayout); day != g.day {
+		g.day, g.dayStart = day, pnl
+		started = &RiskDayStart{Day: day, PnL: formatDecimal(pnl)}
+	}
+	loss := sub(g.dayStart, pnl)
+	g.mu.Unlock()
+
+	if started != nil && g.cfg.OnDayStart != nil {
+		g.cfg.OnDayStart(*started)
+	}
+	return loss, nil
+}
+
+func (g *RiskGuard) estimateSlippage(order OrderParams) (SlippageEstimate, error) {
+	if g.cfg.OrderBook == nil {
+		return FetchSlippage(g.market, order)
+	}
+	book, err := g.cfg.OrderBook(order.ProductID)
+	if err != nil {
+		return SlippageEstimate{}, err
+	}
+	return EstimateSlippage(book, order)
+}
+
+func (g *RiskGuard) openOrders() ([]Order, error) {
+	if g.cfg.OpenOrders != nil {
+		return g.cfg.OpenOrders.List(), nil
+	}
+	return g.OrdersAPI.ListOrders(QueryParams{})
+}
+
+// remainingSize is how much of an open order is left to fill.
+func remainingSize(o Order) (*big.Rat, error) {
+	size, err := parseDecimal(o.Size)
+	if err != nil {
+		return nil, err
+	}
+	filled, err := parseDecimal(o.FilledSize)
+	if err != nil {
+		return nil, err
+	}
+	return sub(size, filled), nil
+}
+
+// positiveLimit parses an optional limit, nil when it isn't set.
+func positiveLimit(s string) (*big.Rat, error) {
+	if s == "" {
+		return nil, nil
+	}
+	r, err := parseDecimal(s)
+	if err != nil {
+		return nil, err
+	}
+	if r.Sign() <= 0 {
+		return nil, ErrInvalidRiskConfig
+	}
+	return r, nil
+}
@@ -0,0 +1,228 @@
+package godax
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func riskMarket(price string) *FakeMarketDataAPI {
+	return &FakeMarketDataAPI{GetProductTickerFunc: func(productID string) (Ticker, error) {
+		return Ticker{Price: price}, nil
+	}}
+}
+
+func riskOrder(productID, side, orderType, price, size, funds string) OrderParams {
+	return OrderParams{
+		CommonOrderParams: CommonOrderParams{ProductID: productID, Side: side, Type: orderType, Price: price, Size: size},
+		MarketOrderParams: MarketOrderParams{Funds: funds},
+	}
+}
+
+func TestRiskGuard_Rejections(t *testing.T) {
+	x := newFakeExchange()
+	x.ListOrdersFunc = func(qp QueryParams) ([]Order, error) {
+		var open []Order
+		for _, o := range x.orders {
+			if o.Status != "done" {
+				open = append(open, *o)
+			}
+		}
+		return open, nil
+	}
+	positions := NewPositionTracker()
+	if err := positions.ApplyFill(Fill{TradeID: 1, ProductID: "BTC-USD", OrderID: "o0", Side: "buy", Price: "100", Size: "1"}); err != nil {
+		t.Fatalf("ApplyFill() error = %v", err)
+	}
+	var logs bytes.Buffer
+	g, err := NewRiskGuard(x, riskMarket("100"), RiskConfig{
+		AllowedProducts:  []string{"BTC-USD"},
+		MaxOrderNotional: "500",
+		MaxPosition:      map[string]string{"BTC-USD": "4"},
+		MaxOpenOrders:    2,
+		PriceBandPercent: "5",
+		Positions:        positions,
+		Logger:           log.New(&logs, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("NewRiskGuard() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		order OrderParams
+		want  error
+	}{
+		{"product", riskOrder("ETH-USD", "buy", "limit", "100", "1", ""), ErrProductNotAllowed},
+		{"band", riskOrder("BTC-USD", "buy", "limit", "1000", "0.1", ""), ErrPriceBand},
+		{"notional", riskOrder("BTC-USD", "buy", "limit", "101", "5", ""), ErrMaxOrderNotional},
+		{"market notional", riskOrder("BTC-USD", "buy", "market", "", "6", ""), ErrMaxOrderNotional},
+		{"ok", riskOrder("BTC-USD", "buy", "limit", "99", "2", ""), nil},
+		// 1 held + 2 open + 1.5 could reach 4.5 long
+		{"position", riskOrder("BTC-USD", "buy", "market", "", "", "150"), ErrMaxPosition},
+		{"short side", riskOrder("BTC-USD", "sell", "limit", "101", "4", ""), nil},
+		{"open orders", riskOrder("BTC-USD", "sell", "limit", "101", "0.1", ""), ErrMaxOpenOrders},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placed := len(x.placed)
+			_, err := g.PlaceOrder(tt.order)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("PlaceOrder() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil {
+				if len(x.placed) != placed+1 {
+					t.Errorf("order wasn't placed")
+				}
+				return
+			}
+			var re *RiskError
+			if !errors.As(err, &re) || re.ProductID != tt.order.ProductID {
+				t.Errorf("PlaceOrder() error = %#v, want a *RiskError", err)
+			}
+			if len(x.placed) != placed {
+				t.Errorf("rejected order was placed")
+			}
+			if !strings.Contains(logs.String(), tt.want.Error()) {
+				t.Errorf("log = %q, want the rejection logged", logs.String())
+			}
+		})
+	}
+}
+
+func TestRiskGuard_DailyLossLimit(t *testing.T) {
+	positions := NewPositionTracker()
+	if err := positions.ApplyFill(Fill{TradeID: 1, ProductID: "BTC-USD", OrderID: "o1", Side: "buy", Price: "100", Size: "2"}); err != nil {
+		t.Fatalf("ApplyFill() error = %v", err)
+	}
+	if err := positions.SetMark("BTC-USD", "100"); err != nil {
+		t.Fatalf("SetMark() error = %v", err)
+	}
+	x := newFakeExchange()
+	g, err := NewRiskGuard(x, riskMarket("100"), RiskConfig{
+		DailyLossLimit:    "50",
+		DailyLossCurrency: "USD",
+		Positions:         positions,
+		Logger:            log.New(&bytes.Buffer{}, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("NewRiskGuard() error = %v", err)
+	}
+	now := time.Date(2020, 9, 14, 23, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	order := riskOrder("BTC-USD", "buy", "limit", "80", "0.1", "")
+	if _, err := g.PlaceOrder(order); err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	positions.SetMark("BTC-USD", "80") // down 40
+	if _, err := g.PlaceOrder(order); err != nil {
+		t.Fatalf("PlaceOrder() down 40 error = %v", err)
+	}
+	positions.SetMark("BTC-USD", "75") // down 50
+	if _, err := g.PlaceOrder(order); !errors.Is(err, ErrDailyLossLimit) {
+		t.Fatalf("PlaceOrder() down 50 error = %v, want ErrDailyLossLimit", err)
+	}
+
+	// a new day starts from the current PnL
+	now = now.Add(2 * time.Hour)
+	if _, err := g.PlaceOrder(order); err != nil {
+		t.Errorf("PlaceOrder() the next day error = %v", err)
+	}
+}
+
+func TestRiskGuard_DayStartSurvivesRestart(t *testing.T) {
+	positions := NewPositionTracker()
+	if err := positions.ApplyFill(Fill{TradeID: 1, ProductID: "BTC-USD", OrderID: "o1", Side: "buy", Price: "100", Size: "2"}); err != nil {
+		t.Fatalf("ApplyFill() error = %v", err)
+	}
+	positions.SetMark("BTC-USD", "75") // down 50 on the day
+	now := time.Date(2020, 9, 14, 12, 0, 0, 0, time.UTC)
+	newGuard := func(start RiskDayStart, saved *[]RiskDayStart) *RiskGuard {
+		g, err := NewRiskGuard(newFakeExchange(), riskMarket("75"), RiskConfig{
+			DailyLossLimit:    "50",
+			DailyLossCurrency: "USD",
+			DayStart:          start,
+			OnDayStart:        func(s RiskDayStart) { *saved = append(*saved, s) },
+			Positions:         positions,
+			Logger:            log.New(&bytes.Buffer{}, "", 0),
+		})
+		if err != nil {
+			t.Fatalf("NewRiskGuard() error = %v", err)
+		}
+		g.now = func() time.Time { return now }
+		return g
+	}
+	order := riskOrder("BTC-USD", "buy", "limit", "75", "0.1", "")
+
+	var saved []RiskDayStart
+	g := newGuard(RiskDayStart{Day: "2020-09-14", PnL: "0"}, &saved)
+	if _, err := g.PlaceOrder(order); !errors.Is(err, ErrDailyLossLimit) {
+		t.Errorf("PlaceOrder() after a restart error = %v, want ErrDailyLossLimit", err)
+	}
+	if len(saved) != 0 {
+		t.Errorf("OnDayStart got %+v, want nothing for a restored day", saved)
+	}
+
+	// yesterday's start is ignored, today starts from the current PnL
+	g = newGuard(RiskDayStart{Day: "2020-09-13", PnL: "0"}, &saved)
+	if _, err := g.PlaceOrder(order); err != nil {
+		t.Errorf("PlaceOrder() with a stale day start error = %v", err)
+	}
+	if want := []RiskDayStart{{Day: "2020-09-14", PnL: "-50"}}; !reflect.DeepEqual(saved, want) {
+		t.Errorf("OnDayStart got %+v, want %+v", saved, want)
+	}
+}
+
+func TestRiskGuard_ConcurrentPlaceOrder(t *testing.T) {
+	var mu sync.Mutex
+	placed := 0
+	orders := &FakeOrdersAPI{PlaceOrderFunc: func(p OrderParams) (Order, error) {
+		time.Sleep(time.Millisecond) // the window other orders could be checked in
+		mu.Lock()
+		defer mu.Unlock()
+		placed++
+		return Order{ID: "order-" + strconv.Itoa(placed), Status: "pending", OrderParams: p}, nil
+	}}
+	g, err := NewRiskGuard(orders, riskMarket("100"), RiskConfig{
+		MaxOpenOrders: 1,
+		OpenOrders:    NewOrderTracker(orders, nil),
+		Logger:        log.New(&bytes.Buffer{}, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("NewRiskGuard() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.PlaceOrder(riskOrder("BTC-USD", "buy", "limit", "100", "0.1", ""))
+		}()
+	}
+	wg.Wait()
+	if placed != 1 {
+		t.Errorf("placed %d orders, want 1 with MaxOpenOrders 1", placed)
+	}
+}
+
+func TestNewRiskGuard_InvalidConfig(t *testing.T) {
+	for _, cfg := range []RiskConfig{
+		{MaxOrderNotional: "-1"},
+		{MaxOpenOrders: -1},
+		{MaxPosition: map[string]string{"BTC-USD": "1"}},
+		{DailyLossLimit: "100"},
+		{DayStart: RiskDayStart{Day: "14/09/2020", PnL: "0"}},
+	} {
+		if _, err := NewRiskGuard(newFakeExchange(), riskMarket("1"), cfg); err != ErrInvalidRiskConfig {
+			t.Errorf("NewRiskGuard(%+v) error = %v, want ErrInvalidRiskConfig", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package godax
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrInvalidSlippageOrder is returned when estimating the slippage of an order that isn't a buy or a
+// sell of either a size or funds.
+var ErrInvalidSlippageOrder = errors.New("please provide a buy or sell side and one of size or funds")
+
+// SlippageEstimate is what walking the book with a market order would cost.
+type SlippageEstimate struct {
+	Side string
+
+	// BestPrice is the price at the top of the side of the book the order takes from (the best ask
+	// for a buy, the best bid for a sell).
+	BestPrice string
+
+	// AveragePrice is the size weighted price the order fills at, WorstPrice the last level it
+	// reaches.
+	AveragePrice string
+	WorstPrice   string
+
+	// SlippageBps is how far AveragePrice is from BestPrice in basis points, always positive or zero.
+	SlippageBps string
+
+	// Size is the base currency and Funds the quote currency the order fills for, before fees.
+	Size  string
+	Funds string
+
+	// Levels is the number of price levels the order reaches.
+	Levels int
+
+	// Complete is false when the book runs out before the order is filled. The other fields then
+	// describe the part of it that fills.
+	Complete bool
+}
+
+// EstimateSlippage walks book as a market order would and estimates its average fill price, worst
+// price and slippage. Only the order's Side, and one of its Size or Funds, are used, so any
+// hypothetical order will do. book can come from GetProductOrderBook (level 2 is aggregated and
+// deep enough for most orders) or be one you keep up to date yourself. Bids must be sorted best
+// (highest) first and asks best (lowest) first, as coinbase sends them. Fees aren't taken into
+// account, see FeeCalculator for those.
+func EstimateSlippage(book OrderBook, order OrderParams) (SlippageEstimate, error) {
+	levels := book.Asks
+	switch order.Side {
+	case "buy":
+	case "sell":
+		levels = book.Bids
+	default:
+		return SlippageEstimate{}, ErrInvalidSlippageOrder
+	}
+	if (order.Size == "") == (order.Funds == "") {
+		return SlippageEstimate{}, ErrInvalidSlippageOrder
+	}
+	want, err := parseDecimal(order.Size)
+	if err != nil {
+		return SlippageEstimate{}, err
+	}
+	byFunds := order.Funds != ""
+	if byFunds {
+		if want, err = parseDecimal(order.Funds); err != nil {
+			return SlippageEstimate{}, err
+		}
+	}
+	if want.Sign() <= 0 {
+		return SlippageEstimate{}, ErrInvalidSlippageOrder
+	}
+
+	e := SlippageEstimate{Side: order.Side}
+	size, funds := newRat(), newRat()
+	var best, worst *big.Rat
+	for _, l := range levels {
+		price, err := parseDecimal(l.Price)
+		if err != nil {
+			return SlippageEstimate{}, err
+		}
+		available, err := parseDecimal(l.Size)
+		if err != nil {
+			return SlippageEstimate{}, err
+		}
+		if available.Sign() <= 0 || price.Sign() <= 0 {
+			continue
+		}
+		if best == nil {
+			best = price
+		}
+
+		take := available
+		if byFunds {
+			if left := sub(want, funds); mul(available, price).Cmp(left) >= 0 {
+				take = quo(left, price)
+				e.Complete = true
+			}
+		} else if left := sub(want, size); available.Cmp(left) >= 0 {
+			take = left
+			e.Complete = true
+		}
+		size = add(size, take)
+		funds = add(funds, mul(take, price))
+		worst = price
+		e.Levels++
+		if e.Complete {
+			break
+		}
+	}
+
+	e.Size = formatDecimal(size)
+	e.Funds = formatDecimal(funds)
+	if best == nil {
+		return e, nil
+	}
+	average := quo(funds, size)
+	slippage := sub(average, best)
+	if order.Side == "sell" {
+		slippage = sub(best, average)
+	}
+	e.BestPrice = formatDecimal(best)
+	e.WorstPrice = formatDecimal(worst)
+	e.AveragePrice = formatDecimal(average)
+	e.SlippageBps = formatDecimal(quo(mul(slippage, newRat().SetInt64(10000)), best))
+	return e, nil
+}
+
+// FetchSlippage estimates the slippage of a market order against the product's current level 2 order
+// book. See EstimateSlippage.
+func FetchSlippage(market MarketDataAPI, order OrderParams) (SlippageEstimate, error) {
+	book, err := market.GetProductOrderBook(order.ProductID, QueryParams{LevelParam: "2"})
+	if err != nil {
+		return SlippageEstimate{}, err
+	}
+	return EstimateSlippage(book, order)
+}
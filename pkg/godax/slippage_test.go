@@ -0,0 +1,115 @@
+package godax
+
+import (
+	"errors"
+	"testing"
+)
+
+func slippageBook() OrderBook {
+	return OrderBook{
+		Bids: []OrderBookOrder{{Price: "99", Size: "1"}, {Price: "98", Size: "2"}, {Price: "95", Size: "10"}},
+		Asks: []OrderBookOrder{{Price: "100", Size: "1"}, {Price: "101", Size: "0"}, {Price: "102", Size: "2"}},
+	}
+}
+
+func TestEstimateSlippage(t *testing.T) {
+	tests := []struct {
+		name  string
+		order OrderParams
+		want  SlippageEstimate
+	}{
+		{
+			"buy size within the top level",
+			marketOrder("buy", "0.5", ""),
+			SlippageEstimate{Side: "buy", BestPrice: "100", AveragePrice: "100", WorstPrice: "100", SlippageBps: "0", Size: "0.5", Funds: "50", Levels: 1, Complete: true},
+		},
+		{
+			"buy size through two levels",
+			marketOrder("buy", "2", ""),
+			SlippageEstimate{Side: "buy", BestPrice: "100", AveragePrice: "101", WorstPrice: "102", SlippageBps: "100", Size: "2", Funds: "202", Levels: 2, Complete: true},
+		},
+		{
+			"sell funds",
+			marketOrder("sell", "", "197"),
+			SlippageEstimate{Side: "sell", BestPrice: "99", AveragePrice: "98.5", WorstPrice: "98", SlippageBps: "50.5050505050505051", Size: "2", Funds: "197", Levels: 2, Complete: true},
+		},
+		{
+			"buy more than the book",
+			marketOrder("buy", "5", ""),
+			SlippageEstimate{Side: "buy", BestPrice: "100", AveragePrice: "101.3333333333333333", WorstPrice: "102", SlippageBps: "133.3333333333333333", Size: "3", Funds: "304", Levels: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateSlippage(slippageBook(), tt.order)
+			if err != nil {
+				t.Fatalf("EstimateSlippage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EstimateSlippage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, o := range []OrderParams{marketOrder("hold", "1", ""), marketOrder("buy", "1", "100"), marketOrder("buy", "", ""), marketOrder("sell", "-1", "")} {
+		if _, err := EstimateSlippage(slippageBook(), o); err != ErrInvalidSlippageOrder {
+			t.Errorf("EstimateSlippage(%+v) error = %v, want ErrInvalidSlippageOrder", o, err)
+		}
+	}
+	if got, err := EstimateSlippage(OrderBook{}, marketOrder("buy", "1", "")); err != nil || got.Complete || got.Size != "0" || got.BestPrice != "" {
+		t.Errorf("EstimateSlippage() on an empty book = %+v, %v", got, err)
+	}
+}
+
+func TestFetchSlippage(t *testing.T) {
+	market := &FakeMarketDataAPI{GetProductOrderBookFunc: func(productID string, qp QueryParams) (OrderBook, error) {
+		if productID != "BTC-USD" || qp[LevelParam] != "2" {
+			t.Errorf("GetProductOrderBook(%s, %v), want the BTC-USD level 2 book", productID, qp)
+		}
+		return slippageBook(), nil
+	}}
+	got, err := FetchSlippage(market, marketOrder("sell", "3", ""))
+	if err != nil {
+		t.Fatalf("FetchSlippage() error = %v", err)
+	}
+	if got.AveragePrice != "98.3333333333333333" || got.WorstPrice != "98" || !got.Complete {
+		t.Errorf("FetchSlippage() = %+v", got)
+	}
+}
+
+func TestRiskGuard_MaxSlippage(t *testing.T) {
+	var books int
+	g, err := NewRiskGuard(newFakeExchange(), riskMarket("100"), RiskConfig{
+		MaxSlippageBps: "60",
+		OrderBook: func(productID string) (OrderBook, error) {
+			books++
+			return slippageBook(), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRiskGuard() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		order OrderParams
+		want  error
+	}{
+		{"within", riskOrder("BTC-USD", "sell", "market", "", "", "197"), nil},
+		{"over", riskOrder("BTC-USD", "buy", "market", "", "2", ""), ErrMaxSlippage},
+		{"too thin", riskOrder("BTC-USD", "buy", "market", "", "5", ""), ErrMaxSlippage},
+		{"limit orders skip it", riskOrder("BTC-USD", "buy", "limit", "110", "5", ""), nil},
+	}
+	for _, tt := range tests {
+		if err := g.Check(tt.order); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Check() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if books != 3 {
+		t.Errorf("looked up %d books, want one per market order", books)
+	}
+
+	if _, err := NewRiskGuard(newFakeExchange(), riskMarket("100"), RiskConfig{MaxSlippageBps: "-1"}); err != ErrInvalidRiskConfig {
+		t.Errorf("NewRiskGuard() error = %v, want ErrInvalidRiskConfig", err)
+	}
+}
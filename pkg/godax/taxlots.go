@@ -0,0 +1,527 @@
+package godax
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LotMethod picks which tax lots a sale is matched against.
+type LotMethod string
+
+// Lot matching methods
+const (
+	// LotFIFO sells the oldest lots first.
+	LotFIFO LotMethod = "fifo"
+
+	// LotLIFO sells the newest lots first.
+	LotLIFO LotMethod = "lifo"
+
+	// LotHIFO sells the lots with the highest cost per unit first. Costs in different currencies
+	// aren't comparable, so it is only meaningful for lots that share a cost currency.
+	LotHIFO LotMethod = "hifo"
+
+	// LotSpecificID sells the lots chosen with SelectLots.
+	LotSpecificID LotMethod = "specific_id"
+)
+
+// TaxLotEngine errors
+var (
+	ErrInvalidLotMethod = errors.New("please provide one of the fifo, lifo, hifo or specific_id lot methods")
+	ErrLotSelection     = errors.New("the lots selected for the sale don't cover it")
+)
+
+// TaxLot is an open tax lot, what is left of one buy.
+type TaxLot struct {
+	// ID identifies the lot for SelectLots, "<product id>:<trade id>".
+	ID string
+
+	// Currency is the currency that was acquired, for example "BTC".
+	Currency string
+
+	// CostCurrency is the currency of the cost basis, the currency given up for the lot. That is
+	// the quote currency of a buy, or the base currency of a sell that acquired a crypto quote
+	// currency (the ETH given up for BTC on ETH-BTC).
+	CostCurrency string
+
+	Acquired time.Time
+
+	// Size is what is left of the lot.
+	Size string
+
+	// CostBasis is the cost of what is left of the lot, including its share of the buy's fee.
+	CostBasis string
+}
+
+// TaxGain is a realized capital gain or loss, one sale matched against (part of) one lot, or the
+// part of a sale no lot covers.
+type TaxGain struct {
+	Currency     string
+	CostCurrency string
+	LotID        string
+
+	// SaleID identifies the sale, "<product id>:<trade id>" like lot IDs.
+	SaleID string
+
+	Acquired time.Time
+	Disposed time.Time
+	Size     string
+
+	// Proceeds is what the sale received net of its share of the sale's fee, in ProceedsCurrency.
+	Proceeds         string
+	ProceedsCurrency string
+	CostBasis        string
+
+	// Gain is Proceeds minus CostBasis. It is empty when the gain is Unpriced.
+	Gain string
+
+	// Unpriced is true when the proceeds and the cost basis are in different currencies, as when
+	// a lot bought with USD is sold for BTC. Price the proceeds in CostCurrency as of Disposed to
+	// work out the gain. Unpriced gains are left out of the report's totals.
+	Unpriced bool
+
+	// Unmatched is true for the part of a sale that no open lot covers, typically coins deposited
+	// from elsewhere that have no buy in the fill history. It has no lot, cost basis or gain, work
+	// those out from where the coins came from. Unmatched gains are left out of the report's totals.
+	Unmatched bool
+
+	// LongTerm is true when the lot was held for more than a year.
+	LongTerm bool
+}
+
+// TaxConversion is a stablecoin conversion from the account ledger. Conversions are not taxable,
+// they are listed in reports so they can be accounted for.
+type TaxConversion struct {
+	Time      time.Time
+	AccountID string
+	Currency  string
+	Amount    string
+}
+
+// TaxTotal sums the priced gains of a report that share a cost currency.
+type TaxTotal struct {
+	CostCurrency  string
+	Proceeds      string
+	CostBasis     string
+	ShortTermGain string
+	LongTermGain  string
+}
+
+// TaxReport is the capital gains report for a period.
+type TaxReport struct {
+	Gains       []TaxGain
+	Totals      []TaxTotal
+	Conversions []TaxConversion
+}
+
+// TaxLotEngine matches sells against buys as tax lots and reports the capital gains. Buys open a
+// lot with a cost basis of price * size plus the fee, sells are matched against open lots of the
+// same currency with the engine's LotMethod and realize price * size minus the fee.
+//
+// Amounts are in the quote currency of each fill. Stablecoins are treated as the currency they are
+// pegged to, so a lot bought on BTC-USDC can be sold on BTC-USD, and stablecoin conversions from
+// the ledger are reported without creating gains. On a product quoted in crypto both sides are
+// taxed: buying on ETH-BTC also sells the BTC spent, selling also opens a lot for the BTC received.
+// Gains whose proceeds aren't in the lot's cost currency are recorded as Unpriced. USD, EUR and GBP
+// are fiat and never held in lots, use SetFiat to add others.
+type TaxLotEngine struct {
+	mu          sync.Mutex
+	method      LotMethod
+	pegs        map[string]string
+	fiat        map[string]bool
+	lots        map[string][]*taxLot
+	gains       []TaxGain
+	conversions []TaxConversion
+	selections  map[string][]string
+	seen        map[fillKey]bool
+	seenLedger  map[string]bool
+}
+
+type taxLot struct {
+	id       string
+	currency string
+	costCur  string
+	acquired time.Time
+	size     *big.Rat
+	cost     *big.Rat
+	unit     *big.Rat
+}
+
+// NewTaxLotEngine creates a TaxLotEngine that matches lots with method. USDC is treated as USD.
+func NewTaxLotEngine(method LotMethod) (*TaxLotEngine, error) {
+	switch method {
+	case LotFIFO, LotLIFO, LotHIFO, LotSpecificID:
+	default:
+		return nil, ErrInvalidLotMethod
+	}
+	return &TaxLotEngine{
+		method:     method,
+		pegs:       map[string]string{"USDC": "USD"},
+		fiat:       map[string]bool{"USD": true, "EUR": true, "GBP": true},
+		lots:       make(map[string][]*taxLot),
+		selections: make(map[string][]string),
+		seen:       make(map[fillKey]bool),
+		seenLedger: make(map[string]bool),
+	}, nil
+}
+
+// SetPeg treats stablecoin as the currency it is pegged to, for example SetPeg("USDC", "USD").
+func (e *TaxLotEngine) SetPeg(stablecoin, currency string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pegs[stablecoin] = currency
+}
+
+// SetFiat treats currency as fiat, so it is spent and received without lots or gains.
+func (e *TaxLotEngine) SetFiat(currency string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fiat[currency] = true
+}
+
+// SelectLots picks the lots, in order, that the fills of a sell order are matched against when
+// using LotSpecificID. A buy on a product quoted in crypto sells the quote currency, so select its
+// lots too. Select lots before adding the order's fills.
+func (e *TaxLotEngine) SelectLots(orderID string, lotIDs ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.selections[orderID] = append([]string(nil), lotIDs...)
+}
+
+// Load adds the fill history of each product from ListFills, and the stablecoin conversions from
+// the ledger of every account from ListAccounts and GetAccountHistory.
+func (e *TaxLotEngine) Load(orders OrdersAPI, accounts AccountsAPI, productIDs ...string) error {
+	var fills []Fill
+	for _, productID := range productIDs {
+		f, err := orders.ListFills(QueryParams{ProductIDParam: productID})
+		if err != nil {
+			return err
+		}
+		fills = append(fills, f...)
+	}
+	if err := e.AddFills(fills); err != nil {
+		return err
+	}
+
+	list, err := accounts.ListAccounts()
+	if err != nil {
+		return err
+	}
+	for _, a := range list {
+		history, err := accounts.GetAccountHistory(a.ID)
+		if err != nil {
+			return err
+		}
+		if err := e.AddAccountHistory(a.ID, a.Currency, history); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddFills adds fills oldest first, whatever order they come in. Fills already added are ignored.
+func (e *TaxLotEngine) AddFills(fills []Fill) error {
+	sorted, err := sortFills(fills)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, f := range sorted {
+		if err := e.addFill(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddAccountHistory records the stablecoin conversions of an account's ledger.
+func (e *TaxLotEngine) AddAccountHistory(accountID, currency string, history []AccountActivity) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, a := range history {
+		if a.Type != "conversion" || e.seenLedger[accountID+":"+a.ID] {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, a.CreatedAt)
+		if err != nil {
+			return err
+		}
+		e.seenLedger[accountID+":"+a.ID] = true
+		e.conversions = append(e.conversions, TaxConversion{Time: at, AccountID: accountID, Currency: currency, Amount: a.Amount})
+	}
+	sort.SliceStable(e.conversions, func(i, j int) bool { return e.conversions[i].Time.Before(e.conversions[j].Time) })
+	return nil
+}
+
+// Lots returns the open lots, sorted by currency and then by when they were acquired.
+func (e *TaxLotEngine) Lots() []TaxLot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var currencies []string
+	for c := range e.lots {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	var lots []TaxLot
+	for _, c := range currencies {
+		for _, l := range e.lots[c] {
+			lots = append(lots, TaxLot{
+				ID:           l.id,
+				Currency:     l.currency,
+				CostCurrency: l.costCur,
+				Acquired:     l.acquired,
+				Size:         formatDecimal(l.size),
+				CostBasis:    formatDecimal(l.cost),
+			})
+		}
+	}
+	return lots
+}
+
+// Report returns the gains disposed of in [from, to) along with their totals, and the
+// conversions made in the same period. Use a calendar year for a yearly report.
+func (e *TaxLotEngine) Report(from, to time.Time) TaxReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	type sums struct{ proceeds, cost, short, long *big.Rat }
+	byCur := make(map[string]*sums)
+	var currencies []string
+
+	var report TaxReport
+	for _, g := range e.gains {
+		if g.Disposed.Before(from) || !g.Disposed.Before(to) {
+			continue
+		}
+		report.Gains = append(report.Gains, g)
+		if g.Unpriced || g.Unmatched {
+			continue
+		}
+
+		s, ok := byCur[g.CostCurrency]
+		if !ok {
+			s = &sums{newRat(), newRat(), newRat(), newRat()}
+			byCur[g.CostCurrency] = s
+			currencies = append(currencies, g.CostCurrency)
+		}
+		proceeds, _ := parseDecimal(g.Proceeds)
+		cost, _ := parseDecimal(g.CostBasis)
+		s.proceeds = add(s.proceeds, proceeds)
+		s.cost = add(s.cost, cost)
+		if g.LongTerm {
+			s.long = add(s.long, sub(proceeds, cost))
+		} else {
+			s.short = add(s.short, sub(proceeds, cost))
+		}
+	}
+	sort.Strings(currencies)
+	for _, c := range currencies {
+		s := byCur[c]
+		report.Totals = append(report.Totals, TaxTotal{
+			CostCurrency:  c,
+			Proceeds:      formatDecimal(s.proceeds),
+			CostBasis:     formatDecimal(s.cost),
+			ShortTermGain: formatDecimal(s.short),
+			LongTermGain:  formatDecimal(s.long),
+		})
+	}
+	for _, c := range e.conversions {
+		if !c.Time.Before(from) && c.Time.Before(to) {
+			report.Conversions = append(report.Conversions, c)
+		}
+	}
+	return report
+}
+
+// addFill opens a lot for what a fill acquired and matches what it gave up against open lots.
+// Fiat on either side is neither. Callers must hold e.mu.
+func (e *TaxLotEngine) addFill(f Fill) error {
+	key := fillKey{productID: f.ProductID, tradeID: f.TradeID, orderID: f.OrderID}
+	if e.seen[key] {
+		return nil
+	}
+	base, quote, err := splitProductID(f.ProductID)
+	if err != nil {
+		return err
+	}
+	at, err := time.Parse(time.RFC3339Nano, f.CreatedAt)
+	if err != nil {
+		return err
+	}
+	price, err := parseDecimal(f.Price)
+	if err != nil {
+		return err
+	}
+	size, err := parseDecimal(f.Size)
+	if err != nil {
+		return err
+	}
+	fee, err := parseDecimal(f.Fee)
+	if err != nil {
+		return err
+	}
+	if size.Sign() <= 0 {
+		return nil
+	}
+
+	id := f.ProductID + ":" + strconv.Itoa(f.TradeID)
+	quote = e.peg(quote)
+	if f.Side == "buy" {
+		spent := add(mul(price, size), fee)
+		if !e.fiat[quote] {
+			if err := e.dispose(quote, f.OrderID, id, at, spent, size, base); err != nil {
+				return err
+			}
+		}
+		e.open(base, id, at, size, spent, quote)
+		e.seen[key] = true
+		return nil
+	}
+
+	received := sub(mul(price, size), fee)
+	if err := e.dispose(base, f.OrderID, id, at, size, received, quote); err != nil {
+		return err
+	}
+	if !e.fiat[quote] {
+		e.open(quote, id, at, received, size, base)
+	}
+	e.seen[key] = true
+	return nil
+}
+
+// open adds a lot of size currency that cost cost in costCur. Callers must hold e.mu.
+func (e *TaxLotEngine) open(currency, id string, at time.Time, size, cost *big.Rat, costCur string) {
+	if size.Sign() <= 0 {
+		return
+	}
+	e.lots[currency] = append(e.lots[currency], &taxLot{
+		id:       id,
+		currency: currency,
+		costCur:  costCur,
+		acquired: at,
+		size:     size,
+		cost:     cost,
+		unit:     quo(cost, size),
+	})
+}
+
+// dispose matches a sale of size currency, which received proceeds in proceedsCur, against open
+// lots and records the gains. What the lots don't cover is recorded as unmatched. Nothing changes if
+// the selected lots of a specific ID sale don't cover it. Callers must hold e.mu.
+func (e *TaxLotEngine) dispose(currency, orderID, saleID string, at time.Time, size, proceeds *big.Rat, proceedsCur string) error {
+	lots, err := e.pick(currency, orderID, size)
+	if err != nil {
+		return err
+	}
+	remaining := size
+	for _, l := range lots {
+		used := minRat(remaining, l.size)
+		cost := mul(l.unit, used)
+		if used.Cmp(l.size) == 0 {
+			cost = l.cost // the rest of the lot, exactly
+		}
+		share := quo(mul(proceeds, used), size)
+		g := TaxGain{
+			Currency:         currency,
+			CostCurrency:     l.costCur,
+			LotID:            l.id,
+			SaleID:           saleID,
+			Acquired:         l.acquired,
+			Disposed:         at,
+			Size:             formatDecimal(used),
+			Proceeds:         formatDecimal(share),
+			ProceedsCurrency: proceedsCur,
+			CostBasis:        formatDecimal(cost),
+			Unpriced:         l.costCur != proceedsCur,
+			LongTerm:         at.After(l.acquired.AddDate(1, 0, 0)),
+		}
+		if !g.Unpriced {
+			g.Gain = formatDecimal(sub(share, cost))
+		}
+		e.gains = append(e.gains, g)
+		l.size = sub(l.size, used)
+		l.cost = sub(l.cost, cost)
+		remaining = sub(remaining, used)
+	}
+	if remaining.Sign() > 0 {
+		e.gains = append(e.gains, TaxGain{
+			Currency:         currency,
+			SaleID:           saleID,
+			Disposed:         at,
+			Size:             formatDecimal(remaining),
+			Proceeds:         formatDecimal(quo(mul(proceeds, remaining), size)),
+			ProceedsCurrency: proceedsCur,
+			Unmatched:        true,
+		})
+	}
+	e.prune(currency)
+	return nil
+}
+
+// pick returns the lots a sale of size is matched against, in the order they are used, which may
+// not cover all of it. Lots selected for a specific ID sale must cover it. Callers must hold e.mu.
+func (e *TaxLotEngine) pick(currency, orderID string, size *big.Rat) ([]*taxLot, error) {
+	open := append([]*taxLot(nil), e.lots[currency]...)
+
+	var lots []*taxLot
+	switch e.method {
+	case LotFIFO:
+		lots = open
+	case LotLIFO:
+		for i := len(open) - 1; i >= 0; i-- {
+			lots = append(lots, open[i])
+		}
+	case LotHIFO:
+		lots = open
+		sort.SliceStable(lots, func(i, j int) bool { return lots[i].unit.Cmp(lots[j].unit) > 0 })
+	case LotSpecificID:
+		byID := make(map[string]*taxLot)
+		for _, l := range open {
+			byID[l.id] = l
+		}
+		for _, id := range e.selections[orderID] {
+			if l, ok := byID[id]; ok {
+				lots = append(lots, l)
+			}
+		}
+	}
+
+	covered := newRat()
+	for i, l := range lots {
+		covered = add(covered, l.size)
+		if covered.Cmp(size) >= 0 {
+			return lots[:i+1], nil
+		}
+	}
+	if e.method == LotSpecificID {
+		return nil, ErrLotSelection
+	}
+	return lots, nil
+}
+
+// prune drops fully sold lots. Callers must hold e.mu.
+func (e *TaxLotEngine) prune(currency string) {
+	var open []*taxLot
+	for _, l := range e.lots[currency] {
+		if l.size.Sign() > 0 {
+			open = append(open, l)
+		}
+	}
+	e.lots[currency] = open
+}
+
+func (e *TaxLotEngine) peg(currency string) string {
+	if to, ok := e.pegs[currency]; ok {
+		return to
+	}
+	return currency
+}
@@ -0,0 +1,253 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// taxFills buys 1 BTC three times at different prices and then sells 1.5 BTC, newest first like ListFills.
+func taxFills() []Fill {
+	return []Fill{
+		{TradeID: 4, ProductID: "BTC-USD", OrderID: "sell", CreatedAt: "2021-03-01T00:00:00Z", Side: "sell", Price: "300", Size: "1.5", Fee: "3"},
+		{TradeID: 3, ProductID: "BTC-USD", OrderID: "buy-3", CreatedAt: "2020-06-01T00:00:00Z", Side: "buy", Price: "150", Size: "1", Fee: "1"},
+		{TradeID: 2, ProductID: "BTC-USDC", OrderID: "buy-2", CreatedAt: "2020-03-01T00:00:00Z", Side: "buy", Price: "200", Size: "1", Fee: "2"},
+		{TradeID: 1, ProductID: "BTC-USD", OrderID: "buy-1", CreatedAt: "2020-01-01T00:00:00Z", Side: "buy", Price: "100", Size: "1", Fee: "0"},
+	}
+}
+
+func gainSummary(gains []TaxGain) [][3]string {
+	var out [][3]string
+	for _, g := range gains {
+		term := "short"
+		if g.LongTerm {
+			term = "long"
+		}
+		out = append(out, [3]string{g.LotID, g.Size, g.Gain + " " + term})
+	}
+	return out
+}
+
+func TestTaxLotEngine_Methods(t *testing.T) {
+	tests := []struct {
+		method LotMethod
+		want   [][3]string
+	}{
+		{
+			method: LotFIFO,
+			want: [][3]string{
+				{"BTC-USD:1", "1", "198 long"},
+				{"BTC-USDC:2", "0.5", "48 short"},
+			},
+		},
+		{
+			method: LotLIFO,
+			want: [][3]string{
+				{"BTC-USD:3", "1", "147 short"},
+				{"BTC-USDC:2", "0.5", "48 short"},
+			},
+		},
+		{
+			method: LotHIFO,
+			want: [][3]string{
+				{"BTC-USDC:2", "1", "96 short"},
+				{"BTC-USD:3", "0.5", "73.5 short"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			e, err := NewTaxLotEngine(tt.method)
+			if err != nil {
+				t.Fatalf("NewTaxLotEngine() error = %v", err)
+			}
+			if err := e.AddFills(taxFills()); err != nil {
+				t.Fatalf("AddFills() error = %v", err)
+			}
+			report := e.Report(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+			if got := gainSummary(report.Gains); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("gains = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxLotEngine_ReportTotalsAndLots(t *testing.T) {
+	e, _ := NewTaxLotEngine(LotFIFO)
+	if err := e.AddFills(taxFills()); err != nil {
+		t.Fatalf("AddFills() error = %v", err)
+	}
+	// replayed fills are ignored
+	if err := e.AddFills(taxFills()); err != nil {
+		t.Fatalf("AddFills() error = %v", err)
+	}
+
+	report := e.Report(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	// proceeds are 450 - 3 in fees, the cost is 100 for the first lot and half of 202 for the second
+	want := []TaxTotal{{CostCurrency: "USD", Proceeds: "447", CostBasis: "201", ShortTermGain: "48", LongTermGain: "198"}}
+	if !reflect.DeepEqual(report.Totals, want) {
+		t.Errorf("Totals = %+v, want %+v", report.Totals, want)
+	}
+
+	if empty := e.Report(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)); len(empty.Gains) != 0 {
+		t.Errorf("2020 report gains = %+v, want none", empty.Gains)
+	}
+
+	lots := e.Lots()
+	if len(lots) != 2 || lots[0].ID != "BTC-USDC:2" || lots[0].Size != "0.5" || lots[0].CostBasis != "101" || lots[1].CostBasis != "151" {
+		t.Errorf("Lots() = %+v, want half of the second lot and all of the third", lots)
+	}
+}
+
+func TestTaxLotEngine_SpecificID(t *testing.T) {
+	e, _ := NewTaxLotEngine(LotSpecificID)
+	e.SelectLots("sell", "BTC-USD:3", "BTC-USD:1")
+	if err := e.AddFills(taxFills()); err != nil {
+		t.Fatalf("AddFills() error = %v", err)
+	}
+	got := gainSummary(e.Report(time.Time{}, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)).Gains)
+	want := [][3]string{{"BTC-USD:3", "1", "147 short"}, {"BTC-USD:1", "0.5", "99 long"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gains = %v, want %v", got, want)
+	}
+
+	e, _ = NewTaxLotEngine(LotSpecificID)
+	e.SelectLots("sell", "BTC-USD:3")
+	if err := e.AddFills(taxFills()); err != ErrLotSelection {
+		t.Errorf("AddFills() error = %v, want ErrLotSelection", err)
+	}
+}
+
+func TestTaxLotEngine_Errors(t *testing.T) {
+	if _, err := NewTaxLotEngine("average"); err != ErrInvalidLotMethod {
+		t.Errorf("NewTaxLotEngine() error = %v, want ErrInvalidLotMethod", err)
+	}
+
+}
+
+func TestTaxLotEngine_Unmatched(t *testing.T) {
+	// 1 BTC bought, 1.5 sold, the rest was deposited from elsewhere
+	e, _ := NewTaxLotEngine(LotFIFO)
+	err := e.AddFills([]Fill{
+		{TradeID: 1, ProductID: "BTC-USD", CreatedAt: "2020-01-01T00:00:00Z", Side: "buy", Price: "100", Size: "1"},
+		{TradeID: 2, ProductID: "BTC-USD", CreatedAt: "2020-02-01T00:00:00Z", Side: "sell", Price: "200", Size: "1.5", Fee: "3"},
+		// the BTC spent here has no lot left at all
+		{TradeID: 3, ProductID: "ETH-BTC", CreatedAt: "2020-03-01T00:00:00Z", Side: "buy", Price: "0.03", Size: "1"},
+	})
+	if err != nil {
+		t.Fatalf("AddFills() error = %v", err)
+	}
+
+	report := e.Report(time.Time{}, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	type gain struct {
+		lot, size, proceeds, cost, gain string
+		unmatched                       bool
+	}
+	var got []gain
+	for _, g := range report.Gains {
+		got = append(got, gain{g.LotID, g.Size, g.Proceeds, g.CostBasis, g.Gain, g.Unmatched})
+	}
+	want := []gain{
+		{"BTC-USD:1", "1", "198", "100", "98", false},
+		{"", "0.5", "99", "", "", true},
+		{"", "0.03", "1", "", "", true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gains = %+v, want %+v", got, want)
+	}
+	wantTotals := []TaxTotal{{CostCurrency: "USD", Proceeds: "198", CostBasis: "100", ShortTermGain: "98", LongTermGain: "0"}}
+	if !reflect.DeepEqual(report.Totals, wantTotals) {
+		t.Errorf("Totals = %+v, want only the matched sale", report.Totals)
+	}
+	if lots := e.Lots(); len(lots) != 1 || lots[0].Currency != "ETH" || lots[0].CostBasis != "0.03" {
+		t.Errorf("Lots() = %+v, want the ETH bought", lots)
+	}
+}
+
+func TestTaxLotEngine_CryptoQuote(t *testing.T) {
+	e, _ := NewTaxLotEngine(LotFIFO)
+	err := e.AddFills([]Fill{
+		{TradeID: 1, ProductID: "BTC-USD", CreatedAt: "2020-01-01T00:00:00Z", Side: "buy", Price: "10000", Size: "1"},
+		{TradeID: 2, ProductID: "ETH-BTC", CreatedAt: "2020-02-01T00:00:00Z", Side: "buy", Price: "0.02", Size: "10", Fee: "0.001"},
+		{TradeID: 3, ProductID: "ETH-USD", CreatedAt: "2020-03-01T00:00:00Z", Side: "sell", Price: "300", Size: "4"},
+		{TradeID: 4, ProductID: "ETH-BTC", CreatedAt: "2020-04-01T00:00:00Z", Side: "sell", Price: "0.025", Size: "6", Fee: "0.0005"},
+	})
+	if err != nil {
+		t.Fatalf("AddFills() error = %v", err)
+	}
+
+	wantLots := []TaxLot{
+		{ID: "BTC-USD:1", Currency: "BTC", CostCurrency: "USD", Acquired: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Size: "0.799", CostBasis: "7990"},
+		{ID: "ETH-BTC:4", Currency: "BTC", CostCurrency: "ETH", Acquired: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), Size: "0.1495", CostBasis: "6"},
+	}
+	if lots := e.Lots(); !reflect.DeepEqual(lots, wantLots) {
+		t.Errorf("Lots() = %+v, want %+v", lots, wantLots)
+	}
+
+	report := e.Report(time.Time{}, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	type gain struct{ currency, lot, proceeds, proceedsCur, cost, gain string }
+	var got []gain
+	for _, g := range report.Gains {
+		if g.Unpriced != (g.Gain == "") {
+			t.Errorf("gain %+v has Unpriced %v with Gain %q", g, g.Unpriced, g.Gain)
+		}
+		got = append(got, gain{g.Currency, g.LotID, g.Proceeds, g.ProceedsCurrency, g.CostBasis, g.Gain})
+	}
+	want := []gain{
+		// the BTC spent on ETH, worth 10 ETH
+		{"BTC", "BTC-USD:1", "10", "ETH", "2010", ""},
+		// ETH bought with BTC and sold for USD
+		{"ETH", "ETH-BTC:2", "1200", "USD", "0.0804", ""},
+		{"ETH", "ETH-BTC:2", "0.1495", "BTC", "0.1206", "0.0289"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gains = %+v, want %+v", got, want)
+	}
+	wantTotals := []TaxTotal{{CostCurrency: "BTC", Proceeds: "0.1495", CostBasis: "0.1206", ShortTermGain: "0.0289", LongTermGain: "0"}}
+	if !reflect.DeepEqual(report.Totals, wantTotals) {
+		t.Errorf("Totals = %+v, want %+v", report.Totals, wantTotals)
+	}
+}
+
+func TestTaxLotEngine_Load(t *testing.T) {
+	orders := &FakeOrdersAPI{ListFillsFunc: func(qp QueryParams) ([]Fill, error) {
+		var fills []Fill
+		for _, f := range taxFills() {
+			if f.ProductID == qp[ProductIDParam] {
+				fills = append(fills, f)
+			}
+		}
+		return fills, nil
+	}}
+	accounts := &FakeAccountsAPI{
+		ListAccountsFunc: func() ([]ListAccount, error) {
+			return []ListAccount{{ID: "usd", Currency: "USD"}, {ID: "usdc", Currency: "USDC"}}, nil
+		},
+		GetAccountHistoryFunc: func(accountID string) ([]AccountActivity, error) {
+			if accountID == "usd" {
+				return []AccountActivity{
+					{ID: "1", CreatedAt: "2020-02-01T00:00:00Z", Amount: "-250", Type: "conversion"},
+					{ID: "2", CreatedAt: "2020-02-02T00:00:00Z", Amount: "-100", Type: "match"},
+				}, nil
+			}
+			return []AccountActivity{{ID: "1", CreatedAt: "2020-02-01T00:00:00Z", Amount: "250", Type: "conversion"}}, nil
+		},
+	}
+
+	e, _ := NewTaxLotEngine(LotFIFO)
+	if err := e.Load(orders, accounts, "BTC-USDC", "BTC-USD"); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	report := e.Report(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(report.Gains) != 2 {
+		t.Errorf("gains = %+v, want 2", report.Gains)
+	}
+	wantConversions := []TaxConversion{
+		{Time: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), AccountID: "usd", Currency: "USD", Amount: "-250"},
+		{Time: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), AccountID: "usdc", Currency: "USDC", Amount: "250"},
+	}
+	if !reflect.DeepEqual(report.Conversions, wantConversions) {
+		t.Errorf("Conversions = %+v, want %+v", report.Conversions, wantConversions)
+	}
+}
@@ -0,0 +1,354 @@
+package godax
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReconcileResult lists the order IDs an OrderTracker reconcile changed to match REST.
+type ReconcileResult struct {
+	// Added are open orders REST knew about that the tracker didn't.
+	Added []string
+
+	// Removed are orders the tracker thought were open that REST no longer returns.
+	Removed []string
+
+	// Updated are orders whose status, size, price or filled size differed from REST.
+	Updated []string
+}
+
+// Drifted reports whether the reconcile found any difference.
+func (r ReconcileResult) Drifted() bool {
+	return len(r.Added)+len(r.Removed)+len(r.Updated) > 0
+}
+
+// OrderTracker keeps your own list of open orders, as the ListOrders docs recommend for high
+// volume trading. Seed it once from ListOrders, hand it user channel messages with
+// HandleFeedMessage, and reconcile it against REST now and then (Run does this for you) to pick
+// up anything the feed missed. Orders leave the tracker once they are done.
+//
+// All methods are safe to call from multiple goroutines.
+type OrderTracker struct {
+	mu       sync.Mutex
+	orders   OrdersAPI
+	qp       QueryParams
+	open     map[string]*Order
+	byClient map[string]string
+	seq      uint64
+	touched  map[string]uint64
+	done     map[string]uint64
+}
+
+// NewOrderTracker creates an empty OrderTracker. qp is passed to ListOrders when seeding and
+// reconciling, use it to only track some products (ProductIDParam) or statuses (StatusParam).
+// Feed messages for products outside of ProductIDParam are ignored.
+func NewOrderTracker(orders OrdersAPI, qp QueryParams) *OrderTracker {
+	return &OrderTracker{
+		orders:   orders,
+		qp:       qp,
+		open:     make(map[string]*Order),
+		byClient: make(map[string]string),
+		touched:  make(map[string]uint64),
+		done:     make(map[string]uint64),
+	}
+}
+
+// Seed loads the current open orders from ListOrders, paging through all of them.
+func (t *OrderTracker) Seed() error {
+	_, err := t.Reconcile()
+	return err
+}
+
+// Reconcile lists open orders with ListOrders and makes the tracker match them. Orders the feed
+// touched while the request was in flight are left alone, the feed is newer than REST for those.
+func (t *OrderTracker) Reconcile() (ReconcileResult, error) {
+	t.mu.Lock()
+	start := t.seq
+	t.mu.Unlock()
+
+	listed, err := t.orders.ListOrders(t.qp)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var res ReconcileResult
+	seen := make(map[string]bool)
+	for i := range listed {
+		o := listed[i]
+		if o.Status == orderStatusDone {
+			continue // done but not yet settled
+		}
+		seen[o.ID] = true
+		if t.touched[o.ID] > start || t.done[o.ID] > start {
+			continue
+		}
+		local, ok := t.open[o.ID]
+		switch {
+		case !ok:
+			res.Added = append(res.Added, o.ID)
+		case local.Status != o.Status || local.Size != o.Size || local.Price != o.Price || !decimalEqual(local.FilledSize, o.FilledSize):
+			res.Updated = append(res.Updated, o.ID)
+		}
+		t.put(&o)
+	}
+	for id := range t.open {
+		if !seen[id] && t.touched[id] <= start {
+			res.Removed = append(res.Removed, id)
+			t.remove(id)
+		}
+	}
+	for id, seq := range t.done {
+		if seq <= start {
+			delete(t.done, id)
+		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Removed)
+	sort.Strings(res.Updated)
+	return res, nil
+}
+
+// defaultReconcileInterval is how often Run reconciles when it isn't given a positive interval.
+const defaultReconcileInterval = 30 * time.Second
+
+// Run seeds the tracker and then reconciles it every interval until ctx is done. An interval that
+// isn't positive reconciles every 30 seconds.
+func (t *OrderTracker) Run(ctx context.Context, interval time.Duration) error {
+	if err := t.Seed(); err != nil {
+		return err
+	}
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := t.Reconcile(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// HandleFeedMessage applies a user channel message: received and activate messages add an order,
+// open, change and match messages update it, and done messages remove it.
+func (t *OrderTracker) HandleFeedMessage(msg FeedMessage) error {
+	if p := t.qp[ProductIDParam]; p != "" && msg.ProductID != "" && msg.ProductID != p {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch msg.Type {
+	case FeedReceived, FeedActivate:
+		o, ok := t.open[msg.OrderID]
+		if !ok {
+			o = &Order{
+				ID:            msg.OrderID,
+				CreatedAt:     msg.Time,
+				FillFees:      "0",
+				FilledSize:    "0",
+				ExecutedValue: "0",
+				OrderParams: OrderParams{
+					CommonOrderParams: CommonOrderParams{
+						Side:      msg.Side,
+						ProductID: msg.ProductID,
+						ClientOID: msg.ClientOID,
+						Type:      msg.OrderType,
+						Price:     msg.Price,
+						Size:      msg.Size,
+					},
+					MarketOrderParams: MarketOrderParams{Funds: msg.Funds},
+				},
+			}
+		}
+		o.Status = orderStatusPending
+		if msg.Type == FeedActivate {
+			o.Status = orderStatusActive
+			o.Stop = msg.StopType
+			o.StopPrice = msg.StopPrice
+		}
+		t.put(o)
+
+	case FeedOpen:
+		o, ok := t.open[msg.OrderID]
+		if !ok {
+			return nil
+		}
+		o.Status = orderStatusOpen
+		if msg.Price != "" {
+			o.Price = msg.Price
+		}
+
+	case FeedChange:
+		o, ok := t.open[msg.OrderID]
+		if !ok {
+			return nil
+		}
+		if msg.NewSize != "" {
+			o.Size = msg.NewSize
+		}
+		if msg.NewFunds != "" {
+			o.Funds = msg.NewFunds
+		}
+		if msg.Price != "" {
+			o.Price = msg.Price
+		}
+
+	case FeedMatch:
+		size, err := parseDecimal(msg.Size)
+		if err != nil {
+			return err
+		}
+		price, err := parseDecimal(msg.Price)
+		if err != nil {
+			return err
+		}
+		for _, id := range []string{msg.MakerOrderID, msg.TakerOrderID} {
+			o, ok := t.open[id]
+			if !ok {
+				continue
+			}
+			filled, err := parseDecimal(o.FilledSize)
+			if err != nil {
+				return err
+			}
+			value, err := parseDecimal(o.ExecutedValue)
+			if err != nil {
+				return err
+			}
+			o.FilledSize = formatDecimal(add(filled, size))
+			o.ExecutedValue = formatDecimal(add(value, mul(size, price)))
+			t.touch(id)
+		}
+		return nil
+
+	case FeedDone:
+		if _, ok := t.open[msg.OrderID]; ok {
+			t.remove(msg.OrderID)
+		}
+		t.seq++
+		t.done[msg.OrderID] = t.seq
+		return nil
+
+	default:
+		return nil
+	}
+
+	t.touch(msg.OrderID)
+	return nil
+}
+
+// Get returns an open order by ID.
+func (t *OrderTracker) Get(id string) (Order, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.open[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// GetByClientOID returns an open order by the client OID it was placed with.
+func (t *OrderTracker) GetByClientOID(clientOID string) (Order, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.open[t.byClient[clientOID]]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// ListByProduct returns the open orders of a product, oldest first.
+func (t *OrderTracker) ListByProduct(productID string) []Order {
+	return t.list(productID)
+}
+
+// List returns every open order, oldest first.
+func (t *OrderTracker) List() []Order {
+	return t.list("")
+}
+
+func (t *OrderTracker) list(productID string) []Order {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var orders []Order
+	for _, o := range t.open {
+		if productID == "" || o.ProductID == productID {
+			orders = append(orders, *o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt != orders[j].CreatedAt {
+			return orders[i].CreatedAt < orders[j].CreatedAt
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders
+}
+
+// track adds an order that was just placed, ahead of the feed's received message. Orders the
+// tracker already has, or that are already done, are left alone.
+func (t *OrderTracker) track(o Order) {
+	if p := t.qp[ProductIDParam]; o.ID == "" || o.Status == orderStatusDone || (p != "" && o.ProductID != p) {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.open[o.ID]; !ok {
+		t.put(&o)
+	}
+}
+
+// put adds or replaces an order. Callers must hold t.mu.
+func (t *OrderTracker) put(o *Order) {
+	if old, ok := t.open[o.ID]; ok && old.ClientOID != "" && old.ClientOID != o.ClientOID {
+		delete(t.byClient, old.ClientOID)
+	}
+	t.open[o.ID] = o
+	if o.ClientOID != "" {
+		t.byClient[o.ClientOID] = o.ID
+	}
+}
+
+// remove drops an order. Callers must hold t.mu.
+func (t *OrderTracker) remove(id string) {
+	if o, ok := t.open[id]; ok && o.ClientOID != "" {
+		delete(t.byClient, o.ClientOID)
+	}
+	delete(t.open, id)
+	delete(t.touched, id)
+}
+
+// touch records that the feed changed an order. Callers must hold t.mu.
+func (t *OrderTracker) touch(id string) {
+	t.seq++
+	t.touched[id] = t.seq
+}
+
+// decimalEqual compares two decimal strings by value, so "1.0" and "1" are equal.
+func decimalEqual(a, b string) bool {
+	x, errX := parseDecimal(a)
+	y, errY := parseDecimal(b)
+	if errX != nil || errY != nil {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
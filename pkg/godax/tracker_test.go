@@ -0,0 +1,171 @@
+package godax
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func orderIDs(orders []Order) []string {
+	var ids []string
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+func TestOrderTracker_FeedLifecycle(t *testing.T) {
+	var listed []Order
+	orders := &FakeOrdersAPI{ListOrdersFunc: func(qp QueryParams) ([]Order, error) { return listed, nil }}
+	listed = []Order{
+		{ID: "seeded", CreatedAt: "2020-09-14T14:00:00Z", Status: "open", FilledSize: "0", OrderParams: OrderParams{
+			CommonOrderParams: CommonOrderParams{ProductID: "BTC-USD", ClientOID: "seed-oid", Side: "sell", Price: "110", Size: "1"},
+		}},
+		{ID: "finished", Status: "done", OrderParams: OrderParams{CommonOrderParams: CommonOrderParams{ProductID: "BTC-USD"}}},
+	}
+
+	tr := NewOrderTracker(orders, QueryParams{ProductIDParam: "BTC-USD"})
+	if err := tr.Seed(); err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	if got := orderIDs(tr.List()); !reflect.DeepEqual(got, []string{"seeded"}) {
+		t.Fatalf("List() after Seed() = %v, want only the open order", got)
+	}
+
+	msgs := []FeedMessage{
+		{Type: FeedReceived, Time: "2020-09-14T14:01:00Z", ProductID: "BTC-USD", OrderID: "new", ClientOID: "new-oid", Side: "buy", OrderType: "limit", Price: "100", Size: "2"},
+		{Type: FeedOpen, ProductID: "BTC-USD", OrderID: "new", Price: "100", RemainingSize: "2"},
+		{Type: FeedMatch, ProductID: "BTC-USD", TradeID: 1, MakerOrderID: "new", TakerOrderID: "someone", Price: "100", Size: "0.5"},
+		{Type: FeedMatch, ProductID: "BTC-USD", TradeID: 2, MakerOrderID: "new", TakerOrderID: "someone", Price: "99.5", Size: "0.5"},
+		{Type: FeedChange, ProductID: "BTC-USD", OrderID: "new", Price: "100", OldSize: "2", NewSize: "1.5"},
+		{Type: FeedReceived, ProductID: "ETH-USD", OrderID: "other-product"},
+	}
+	for _, msg := range msgs {
+		if err := tr.HandleFeedMessage(msg); err != nil {
+			t.Fatalf("HandleFeedMessage(%s) error = %v", msg.Type, err)
+		}
+	}
+
+	o, ok := tr.GetByClientOID("new-oid")
+	if !ok {
+		t.Fatal("GetByClientOID() didn't find the new order")
+	}
+	if o.ID != "new" || o.Status != "open" || o.Size != "1.5" || o.FilledSize != "1" || o.ExecutedValue != "99.75" {
+		t.Errorf("GetByClientOID() = %+v, want open with 1 of 1.5 filled", o)
+	}
+	if got := orderIDs(tr.ListByProduct("BTC-USD")); !reflect.DeepEqual(got, []string{"seeded", "new"}) {
+		t.Errorf("ListByProduct() = %v, want seeded then new", got)
+	}
+	if _, ok := tr.Get("other-product"); ok {
+		t.Error("messages for other products should be ignored")
+	}
+
+	if err := tr.HandleFeedMessage(FeedMessage{Type: FeedDone, ProductID: "BTC-USD", OrderID: "new", Reason: "filled", RemainingSize: "0"}); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	if _, ok := tr.Get("new"); ok {
+		t.Error("done orders should be removed")
+	}
+	if _, ok := tr.GetByClientOID("new-oid"); ok {
+		t.Error("done orders should be removed from the client OID index")
+	}
+
+	err := tr.HandleFeedMessage(FeedMessage{Type: FeedActivate, ProductID: "BTC-USD", OrderID: "stop", Side: "sell", Size: "1", StopType: "loss", StopPrice: "90"})
+	if err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	if o, _ := tr.Get("stop"); o.Status != "active" || o.Stop != "loss" || o.StopPrice != "90" {
+		t.Errorf("Get() = %+v, want an active loss stop", o)
+	}
+}
+
+func TestOrderTracker_Reconcile(t *testing.T) {
+	var (
+		tr       *OrderTracker
+		listed   []Order
+		inFlight []FeedMessage
+	)
+	orders := &FakeOrdersAPI{ListOrdersFunc: func(qp QueryParams) ([]Order, error) {
+		// feed messages that arrive while the request is in flight
+		for _, msg := range inFlight {
+			if err := tr.HandleFeedMessage(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return listed, nil
+	}}
+	tr = NewOrderTracker(orders, nil)
+
+	listed = []Order{
+		{ID: "a", Status: "open", FilledSize: "0"},
+		{ID: "b", Status: "open", FilledSize: "0"},
+		{ID: "c", Status: "open", FilledSize: "0"},
+	}
+	if err := tr.Seed(); err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+
+	// the feed missed a fill on a, b's done message and d's received message
+	listed = []Order{
+		{ID: "a", Status: "open", FilledSize: "0.5"},
+		{ID: "c", Status: "open", FilledSize: "0.0"},
+		{ID: "d", Status: "open", FilledSize: "0"},
+		{ID: "e", Status: "open", FilledSize: "0"},
+	}
+	// e finishes and f arrives while the request is in flight, REST is older than the feed for both
+	inFlight = []FeedMessage{
+		{Type: FeedDone, OrderID: "e"},
+		{Type: FeedReceived, OrderID: "f"},
+	}
+	res, err := tr.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	want := ReconcileResult{Added: []string{"d"}, Removed: []string{"b"}, Updated: []string{"a"}}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("Reconcile() = %+v, want %+v", res, want)
+	}
+	if got := orderIDs(tr.List()); !reflect.DeepEqual(got, []string{"a", "c", "d", "f"}) {
+		t.Errorf("List() = %v, want a, c, d and f", got)
+	}
+
+	inFlight = nil
+	listed = []Order{{ID: "a", Status: "open", FilledSize: "0.5"}, {ID: "c", Status: "open"}, {ID: "d", Status: "open"}, {ID: "f", Status: "pending"}}
+	res, err = tr.Reconcile()
+	if err != nil || res.Drifted() {
+		t.Errorf("Reconcile() = %+v, %v, want no drift", res, err)
+	}
+}
+
+func TestOrderTracker_Concurrent(t *testing.T) {
+	tr := NewOrderTracker(&FakeOrdersAPI{ListOrdersFunc: func(qp QueryParams) ([]Order, error) { return nil, nil }}, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tr.HandleFeedMessage(FeedMessage{Type: FeedReceived, OrderID: "o", ClientOID: "oid"})
+				tr.GetByClientOID("oid")
+				tr.List()
+				tr.Reconcile()
+				tr.HandleFeedMessage(FeedMessage{Type: FeedDone, OrderID: "o"})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOrderTracker_RunDefaultsInterval(t *testing.T) {
+	orders := &FakeOrdersAPI{ListOrdersFunc: func(qp QueryParams) ([]Order, error) { return nil, nil }}
+	tr := NewOrderTracker(orders, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tr.Run(ctx, 0); err != context.DeadlineExceeded {
+		t.Errorf("Run() with no interval error = %v, want context.DeadlineExceeded", err)
+	}
+}
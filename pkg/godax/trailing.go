@@ -0,0 +1,409 @@
+package godax
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TrailingStopManager errors
+var (
+	ErrInvalidTrailingStop  = errors.New("please provide a product, side, size and exactly one of a trail amount or trail percent")
+	ErrTrailingStopNotFound = errors.New("no trailing stop found with that ID")
+)
+
+// Trailing stop statuses
+const (
+	TrailingStopActive    = "active"
+	TrailingStopTriggered = "triggered"
+	TrailingStopCanceled  = "canceled"
+)
+
+// TrailingStopParams describes a trailing stop to add to a TrailingStopManager.
+type TrailingStopParams struct {
+	// ProductID is the product to follow and trade, for example "BTC-USD".
+	ProductID string
+
+	// Side is the side of the order sent when the stop triggers. A sell trails below the highest
+	// price seen, a buy trails above the lowest price seen.
+	Side string
+
+	// Size is the amount of base currency to buy or sell when the stop triggers.
+	Size string
+
+	// TrailAmount is how far the trigger trails the best price, in quote currency.
+	TrailAmount string
+
+	// TrailPercent is how far the trigger trails the best price, as a percentage (2.5 is 2.5%).
+	TrailPercent string
+
+	// OrderType is the type of the order sent when the stop triggers, "market" or "limit" (default
+	// is "market"). A limit order is sent as a stop-limit with its stop price at the trigger.
+	OrderType string
+
+	// LimitOffset is how far past the trigger the limit price of a stop-limit is set, in quote
+	// currency. Below the trigger for sells, above it for buys. Defaults to zero.
+	LimitOffset string
+
+	// InitialPrice seeds the best price seen. When empty the first price update seeds it.
+	InitialPrice string
+}
+
+// TrailingStop is the state of a trailing stop. It is also what TrailingStopManager.Save writes
+// out, so it carries everything needed to pick a stop back up after a restart.
+type TrailingStop struct {
+	ID             string `json:"id"`
+	ProductID      string `json:"product_id"`
+	Side           string `json:"side"`
+	Size           string `json:"size"`
+	TrailAmount    string `json:"trail_amount,omitempty"`
+	TrailPercent   string `json:"trail_percent,omitempty"`
+	OrderType      string `json:"order_type"`
+	LimitOffset    string `json:"limit_offset,omitempty"`
+	QuoteIncrement string `json:"quote_increment"`
+	Status         string `json:"status"`
+
+	// BestPrice is the highest price seen for sells, the lowest for buys.
+	BestPrice string `json:"best_price,omitempty"`
+
+	// TriggerPrice is the price that triggers the stop, ratcheted along with BestPrice.
+	TriggerPrice string `json:"trigger_price,omitempty"`
+
+	// ClientOID is the client OID of the stop's order, set the first time it is sent and reused when
+	// a failed send is retried.
+	ClientOID string `json:"client_oid,omitempty"`
+
+	// OrderID is the ID of the order sent when the stop triggered.
+	OrderID string `json:"order_id,omitempty"`
+}
+
+// TrailingStopManager emulates trailing stops on the client, since coinbase pro has no native
+// trailing stop order. Feed it prices with Update, or hand it ticker and match messages with
+// HandleFeedMessage, and it ratchets each stop's trigger as the price moves in its favor. When the
+// price reaches a trigger the stop sends its order through PlaceOrder and is done.
+//
+// Stops only live in memory, so use Save and Load to keep them across a restart.
+type TrailingStopManager struct {
+	mu     sync.Mutex
+	orders OrdersAPI
+	market MarketDataAPI
+	stops  map[string]*TrailingStop
+}
+
+// NewTrailingStopManager creates a TrailingStopManager that sends orders through orders and looks
+// up product increments through market.
+func NewTrailingStopManager(orders OrdersAPI, market MarketDataAPI) *TrailingStopManager {
+	return &TrailingStopManager{
+		orders: orders,
+		market: market,
+		stops:  make(map[string]*TrailingStop),
+	}
+}
+
+// Add starts a trailing stop. If InitialPrice is set the trigger is placed right away, otherwise
+// it is placed on the first price update for the product. If the initial price can't be applied,
+// for example because it triggers the stop and its order fails, the stop isn't added.
+func (m *TrailingStopManager) Add(p TrailingStopParams) (TrailingStop, error) {
+	if p.ProductID == "" || (p.Side != "buy" && p.Side != "sell") || (p.TrailAmount == "") == (p.TrailPercent == "") {
+		return TrailingStop{}, ErrInvalidTrailingStop
+	}
+	if p.OrderType == "" {
+		p.OrderType = "market"
+	}
+	if p.OrderType != "market" && p.OrderType != "limit" {
+		return TrailingStop{}, ErrInvalidTrailingStop
+	}
+	for _, s := range []string{p.Size, p.TrailAmount, p.TrailPercent, p.LimitOffset} {
+		r, err := parseDecimal(s)
+		if err != nil {
+			return TrailingStop{}, err
+		}
+		if r.Sign() < 0 {
+			return TrailingStop{}, ErrInvalidTrailingStop
+		}
+	}
+	if size, _ := parseDecimal(p.Size); size.Sign() == 0 {
+		return TrailingStop{}, ErrInvalidTrailingStop
+	}
+
+	product, err := m.market.GetProductByID(p.ProductID)
+	if err != nil {
+		return TrailingStop{}, err
+	}
+
+	s := &TrailingStop{
+		ID:             newUUID(),
+		ProductID:      p.ProductID,
+		Side:           p.Side,
+		Size:           p.Size,
+		TrailAmount:    p.TrailAmount,
+		TrailPercent:   p.TrailPercent,
+		OrderType:      p.OrderType,
+		LimitOffset:    p.LimitOffset,
+		QuoteIncrement: product.QuoteIncrement,
+		Status:         TrailingStopActive,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stops[s.ID] = s
+	if p.InitialPrice != "" {
+		price, err := parseDecimal(p.InitialPrice)
+		if err == nil {
+			err = m.update(s, price)
+		}
+		if err != nil {
+			delete(m.stops, s.ID)
+			return TrailingStop{}, err
+		}
+	}
+	return *s, nil
+}
+
+// Update moves every active stop of a product along with a new price, triggering the ones it reaches.
+// A stop that fails doesn't hold up the others, every stop is updated and the failures are returned
+// together as TrailingStopErrors.
+func (m *TrailingStopManager) Update(productID, price string) error {
+	p, err := parseDecimal(price)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs TrailingStopErrors
+	for _, s := range m.sorted() {
+		if s.ProductID != productID || s.Status != TrailingStopActive {
+			continue
+		}
+		if err := m.update(s, p); err != nil {
+			errs = append(errs, &TrailingStopError{StopID: s.ID, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// TrailingStopError is a stop that failed to move or to send its order.
+type TrailingStopError struct {
+	StopID string
+	Err    error
+}
+
+func (e *TrailingStopError) Error() string {
+	return "trailing stop " + e.StopID + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error the stop failed with.
+func (e *TrailingStopError) Unwrap() error {
+	return e.Err
+}
+
+// TrailingStopErrors are the stops that failed during one Update.
+type TrailingStopErrors []*TrailingStopError
+
+func (e TrailingStopErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the stops failed with target, so errors.Is works on the whole lot.
+func (e TrailingStopErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleFeedMessage updates stops from ticker, match and last_match messages. Other messages are ignored.
+func (m *TrailingStopManager) HandleFeedMessage(msg FeedMessage) error {
+	switch msg.Type {
+	case FeedTicker, FeedMatch, FeedLastMatch:
+		return m.Update(msg.ProductID, msg.Price)
+	}
+	return nil
+}
+
+// Cancel stops following a trailing stop. It does nothing to an order the stop has already sent.
+func (m *TrailingStopManager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stops[id]
+	if !ok {
+		return ErrTrailingStopNotFound
+	}
+	if s.Status == TrailingStopActive {
+		s.Status = TrailingStopCanceled
+	}
+	return nil
+}
+
+// Get returns a trailing stop by ID.
+func (m *TrailingStopManager) Get(id string) (TrailingStop, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stops[id]
+	if !ok {
+		return TrailingStop{}, false
+	}
+	return *s, true
+}
+
+// List returns every trailing stop the manager knows about, including triggered and canceled ones.
+func (m *TrailingStopManager) List() []TrailingStop {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stops []TrailingStop
+	for _, s := range m.sorted() {
+		stops = append(stops, *s)
+	}
+	return stops
+}
+
+// Save writes every trailing stop to w as JSON.
+func (m *TrailingStopManager) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m.List())
+}
+
+// Load reads trailing stops written by Save and adds them to the manager, replacing any with the same ID.
+func (m *TrailingStopManager) Load(r io.Reader) error {
+	var stops []TrailingStop
+	if err := json.NewDecoder(r).Decode(&stops); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range stops {
+		s := stops[i]
+		m.stops[s.ID] = &s
+	}
+	return nil
+}
+
+// update ratchets a stop with a new price and sends its order if the price reached the trigger.
+// Callers must hold m.mu.
+func (m *TrailingStopManager) update(s *TrailingStop, price *big.Rat) error {
+	best, err := parseDecimal(s.BestPrice)
+	if err != nil {
+		return err
+	}
+	if s.BestPrice == "" || (s.Side == "sell" && price.Cmp(best) > 0) || (s.Side == "buy" && price.Cmp(best) < 0) {
+		best = price
+		trigger, err := s.trigger(best)
+		if err != nil {
+			return err
+		}
+		s.BestPrice = formatDecimal(best)
+		s.TriggerPrice = formatDecimal(trigger)
+	}
+
+	trigger, err := parseDecimal(s.TriggerPrice)
+	if err != nil {
+		return err
+	}
+	if (s.Side == "sell" && price.Cmp(trigger) > 0) || (s.Side == "buy" && price.Cmp(trigger) < 0) {
+		return nil
+	}
+	return m.send(s, trigger)
+}
+
+// trigger works out the trigger price for a best price, rounded to the product's quote increment.
+func (s *TrailingStop) trigger(best *big.Rat) (*big.Rat, error) {
+	offset, err := parseDecimal(s.TrailAmount)
+	if err != nil {
+		return nil, err
+	}
+	if s.TrailPercent != "" {
+		pct, err := parseDecimal(s.TrailPercent)
+		if err != nil {
+			return nil, err
+		}
+		offset = quo(mul(best, pct), newRat().SetInt64(100))
+	}
+	inc, err := parseDecimal(s.QuoteIncrement)
+	if err != nil {
+		return nil, err
+	}
+	if s.Side == "sell" {
+		return floorTo(sub(best, offset), inc), nil
+	}
+	return floorTo(add(best, offset), inc), nil
+}
+
+// send places a triggered stop's order. A failed order leaves the stop active so the next price
+// update tries again. A failure may still have reached the exchange, so a retry first looks the
+// order up by its client OID and only places it again if it isn't there.
+func (m *TrailingStopManager) send(s *TrailingStop, trigger *big.Rat) error {
+	if s.ClientOID != "" {
+		o, err := m.orders.GetOrderByClientOID(s.ClientOID)
+		if err == nil {
+			s.OrderID = o.ID
+			s.Status = TrailingStopTriggered
+			return nil
+		}
+		if !isNotFound(err) {
+			return err
+		}
+	} else {
+		s.ClientOID = newUUID()
+	}
+
+	params := OrderParams{
+		CommonOrderParams: CommonOrderParams{
+			Side:      s.Side,
+			ProductID: s.ProductID,
+			ClientOID: s.ClientOID,
+			Type:      s.OrderType,
+			Size:      s.Size,
+		},
+	}
+	if s.OrderType == "limit" {
+		offset, err := parseDecimal(s.LimitOffset)
+		if err != nil {
+			return err
+		}
+		limit, stop := sub(trigger, offset), "loss"
+		if s.Side == "buy" {
+			limit, stop = add(trigger, offset), "entry"
+		}
+		params.Price = formatDecimal(limit)
+		params.Stop = stop
+		params.StopPrice = formatDecimal(trigger)
+	}
+
+	o, err := m.orders.PlaceOrder(params)
+	if err != nil {
+		return err
+	}
+	s.OrderID = o.ID
+	s.Status = TrailingStopTriggered
+	return nil
+}
+
+// sorted returns the stops ordered by ID so updates and listings are deterministic.
+func (m *TrailingStopManager) sorted() []*TrailingStop {
+	stops := make([]*TrailingStop, 0, len(m.stops))
+	for _, s := range m.stops {
+		stops = append(stops, s)
+	}
+	sort.Slice(stops, func(i, j int) bool { return stops[i].ID < stops[j].ID })
+	return stops
+}
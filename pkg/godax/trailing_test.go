@@ -0,0 +1,259 @@
+package godax
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTrailingStop_SellRatchetsAndTriggers(t *testing.T) {
+	bid, ask := "100", "100"
+	x := newFakeExchange()
+	m := NewTrailingStopManager(x, executionMarket(&bid, &ask))
+
+	s, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailPercent: "5", InitialPrice: "100"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if s.TriggerPrice != "95" || s.OrderType != "market" {
+		t.Fatalf("Add() = %+v, want a market stop triggering at 95", s)
+	}
+
+	prices := []string{"110", "104.6", "104.51"}
+	for _, p := range prices {
+		if err := m.Update("BTC-USD", p); err != nil {
+			t.Fatalf("Update(%s) error = %v", p, err)
+		}
+	}
+	if err := m.Update("ETH-USD", "1"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	s, _ = m.Get(s.ID)
+	if s.Status != TrailingStopActive || s.BestPrice != "110" || s.TriggerPrice != "104.5" {
+		t.Fatalf("Get() = %+v, want still active with the trigger ratcheted to 104.5", s)
+	}
+
+	if err := m.HandleFeedMessage(FeedMessage{Type: FeedTicker, ProductID: "BTC-USD", Price: "104.5"}); err != nil {
+		t.Fatalf("HandleFeedMessage() error = %v", err)
+	}
+	s, _ = m.Get(s.ID)
+	if s.Status != TrailingStopTriggered || s.OrderID == "" {
+		t.Fatalf("Get() = %+v, want triggered", s)
+	}
+	if len(x.placed) != 1 || x.placed[0].Type != "market" || x.placed[0].Side != "sell" || x.placed[0].Size != "1" {
+		t.Errorf("placed = %+v, want one market sell of 1", x.placed)
+	}
+
+	// a triggered stop doesn't fire again
+	if err := m.Update("BTC-USD", "90"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(x.placed) != 1 {
+		t.Errorf("placed %d orders, want 1", len(x.placed))
+	}
+}
+
+func TestTrailingStop_BuyStopLimit(t *testing.T) {
+	bid, ask := "100", "100"
+	x := newFakeExchange()
+	m := NewTrailingStopManager(x, executionMarket(&bid, &ask))
+
+	s, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "buy", Size: "0.5", TrailAmount: "3", OrderType: "limit", LimitOffset: "0.5"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if s.TriggerPrice != "" {
+		t.Fatalf("Add() = %+v, want no trigger before the first price", s)
+	}
+
+	for _, p := range []string{"100", "90", "92.99"} {
+		if err := m.HandleFeedMessage(FeedMessage{Type: FeedMatch, ProductID: "BTC-USD", Price: p}); err != nil {
+			t.Fatalf("HandleFeedMessage() error = %v", err)
+		}
+	}
+	if len(x.placed) != 0 {
+		t.Fatalf("placed = %+v, want nothing before the trigger", x.placed)
+	}
+
+	if err := m.Update("BTC-USD", "93.2"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(x.placed) != 1 {
+		t.Fatalf("placed = %+v, want one order", x.placed)
+	}
+	o := x.placed[0]
+	if o.Type != "limit" || o.Side != "buy" || o.Stop != "entry" || o.StopPrice != "93" || o.Price != "93.5" {
+		t.Errorf("placed = %+v, want an entry stop at 93 limited to 93.5", o.CommonOrderParams)
+	}
+}
+
+func TestTrailingStop_SaveAndLoad(t *testing.T) {
+	bid, ask := "100", "100"
+	market := executionMarket(&bid, &ask)
+	m := NewTrailingStopManager(newFakeExchange(), market)
+
+	s, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "10", InitialPrice: "100"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := m.Update("BTC-USD", "120"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	canceled, _ := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "1"})
+	if err := m.Cancel(canceled.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// pick the stops back up in a new manager, as after a restart
+	x := newFakeExchange()
+	restored := NewTrailingStopManager(x, market)
+	if err := restored.Load(&buf); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := restored.List(); len(got) != 2 {
+		t.Fatalf("List() = %+v, want both stops", got)
+	}
+	got, _ := restored.Get(s.ID)
+	if got.TriggerPrice != "110" || got.BestPrice != "120" {
+		t.Fatalf("Get() = %+v, want the trigger kept at 110", got)
+	}
+
+	if err := restored.Update("BTC-USD", "109"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(x.placed) != 1 {
+		t.Errorf("placed = %+v, want only the active stop to trigger", x.placed)
+	}
+	if err := restored.Cancel("nope"); err != ErrTrailingStopNotFound {
+		t.Errorf("Cancel() error = %v, want ErrTrailingStopNotFound", err)
+	}
+}
+
+func TestTrailingStop_InvalidParams(t *testing.T) {
+	bid, ask := "100", "100"
+	m := NewTrailingStopManager(newFakeExchange(), executionMarket(&bid, &ask))
+
+	tests := []TrailingStopParams{
+		{Side: "sell", Size: "1", TrailAmount: "1"},
+		{ProductID: "BTC-USD", Side: "hold", Size: "1", TrailAmount: "1"},
+		{ProductID: "BTC-USD", Side: "sell", Size: "1"},
+		{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "1", TrailPercent: "1"},
+		{ProductID: "BTC-USD", Side: "sell", Size: "0", TrailAmount: "1"},
+		{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "-1"},
+		{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "1", OrderType: "stop"},
+	}
+	for _, p := range tests {
+		if _, err := m.Add(p); err != ErrInvalidTrailingStop {
+			t.Errorf("Add(%+v) error = %v, want ErrInvalidTrailingStop", p, err)
+		}
+	}
+}
+
+func TestTrailingStop_FailureDoesNotBlockOthers(t *testing.T) {
+	bid, ask := "100", "100"
+	x := newFakeExchange()
+	errRejected := errors.New("rejected")
+	rejected := false
+	place := x.PlaceOrderFunc
+	x.PlaceOrderFunc = func(p OrderParams) (Order, error) {
+		// whichever stop goes first is rejected
+		if !rejected {
+			rejected = true
+			return Order{}, errRejected
+		}
+		return place(p)
+	}
+	m := NewTrailingStopManager(x, executionMarket(&bid, &ask))
+
+	var ids []string
+	for _, size := range []string{"1", "2"} {
+		s, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: size, TrailPercent: "5", InitialPrice: "100"})
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		ids = append(ids, s.ID)
+	}
+
+	err := m.Update("BTC-USD", "90")
+	var errs TrailingStopErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(err, errRejected) {
+		t.Fatalf("Update() error = %v, want one stop's rejection", err)
+	}
+	for _, id := range ids {
+		s, _ := m.Get(id)
+		if id != errs[0].StopID && s.Status != TrailingStopTriggered {
+			t.Errorf("Get() = %+v, want the other stop triggered anyway", s)
+		}
+	}
+}
+
+func TestTrailingStop_RetryDoesNotPlaceTwice(t *testing.T) {
+	bid, ask := "100", "100"
+	x := newFakeExchange()
+	errTimeout := errors.New("timeout")
+	place := x.PlaceOrderFunc
+	x.PlaceOrderFunc = func(p OrderParams) (Order, error) {
+		// the first order reaches the exchange but the response is lost, the second is rejected
+		switch len(x.placed) {
+		case 0:
+			place(p)
+			return Order{}, errTimeout
+		case 1:
+			x.placed = append(x.placed, p)
+			return Order{}, errTimeout
+		}
+		return place(p)
+	}
+	m := NewTrailingStopManager(x, executionMarket(&bid, &ask))
+
+	landed, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "5", InitialPrice: "100"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := m.Update("BTC-USD", "95"); !errors.Is(err, errTimeout) {
+		t.Fatalf("Update() error = %v, want the timeout", err)
+	}
+	if err := m.Update("BTC-USD", "95"); err != nil {
+		t.Fatalf("Update() retry error = %v", err)
+	}
+	s, _ := m.Get(landed.ID)
+	if len(x.placed) != 1 || s.Status != TrailingStopTriggered || s.OrderID != "order-1" || s.ClientOID != x.placed[0].ClientOID {
+		t.Errorf("Get() = %+v after placing %+v, want the landed order adopted", s, x.placed)
+	}
+
+	rejected, _ := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "2", TrailAmount: "5", InitialPrice: "100"})
+	if err := m.Update("BTC-USD", "95"); !errors.Is(err, errTimeout) {
+		t.Fatalf("Update() error = %v, want the timeout", err)
+	}
+	if err := m.Update("BTC-USD", "95"); err != nil {
+		t.Fatalf("Update() retry error = %v", err)
+	}
+	s, _ = m.Get(rejected.ID)
+	if len(x.placed) != 3 || x.placed[1].ClientOID != x.placed[2].ClientOID || s.Status != TrailingStopTriggered {
+		t.Errorf("placed = %+v, want the order sent again with the same client OID", x.placed)
+	}
+}
+
+func TestTrailingStop_AddFailureNotTracked(t *testing.T) {
+	bid, ask := "100", "100"
+	x := newFakeExchange()
+	errRejected := errors.New("rejected")
+	x.PlaceOrderFunc = func(p OrderParams) (Order, error) { return Order{}, errRejected }
+	m := NewTrailingStopManager(x, executionMarket(&bid, &ask))
+
+	// a zero trail triggers on the initial price
+	if _, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "0", InitialPrice: "100"}); err != errRejected {
+		t.Errorf("Add() error = %v, want the rejection", err)
+	}
+	if _, err := m.Add(TrailingStopParams{ProductID: "BTC-USD", Side: "sell", Size: "1", TrailAmount: "5", InitialPrice: "abc"}); err == nil {
+		t.Error("Add() with an invalid initial price error = nil")
+	}
+	if stops := m.List(); len(stops) != 0 {
+		t.Errorf("List() = %+v, want neither failed stop tracked", stops)
+	}
+}
@@ -0,0 +1,196 @@
+package godax
+
+import (
+	"math/big"
+	"sort"
+)
+
+// AssetValue is the value of one account balance in a Valuation.
+type AssetValue struct {
+	Currency string
+	Balance  string
+
+	// Price is the price of one unit of Currency in the valuation currency, empty when unpriced.
+	Price string
+
+	// Value is Balance * Price, empty when unpriced.
+	Value string
+
+	// Route is the products the price was worked out through, for example [XLM-BTC BTC-USD].
+	// It is empty for the valuation currency itself.
+	Route []string
+
+	// Priced is false when no chain of products connects Currency to the valuation currency, or
+	// a product on the chain has no last trade price or its ticker couldn't be fetched.
+	Priced bool
+}
+
+// Valuation values every non-zero balance of a profile in one currency.
+type Valuation struct {
+	// Currency is the currency everything is valued in.
+	Currency string
+
+	// Total is the sum of every priced asset's Value.
+	Total string
+
+	// Assets are the non-zero balances, sorted by currency.
+	Assets []AssetValue
+
+	// Unpriced lists the currencies that couldn't be priced, they are left out of Total.
+	Unpriced []string
+}
+
+// priceEdge is one hop of a price route: a product and whether it is walked from quote to base.
+type priceEdge struct {
+	product  string
+	to       string
+	inverted bool
+}
+
+// ValuePortfolio values every non-zero balance from ListAccounts in currency, for example "USD".
+// Balances include funds on hold. A currency with no product against currency is priced through
+// other products, taking the route with the fewest hops (XLM-BTC then BTC-USD for XLM in USD),
+// using the last trade price from GetProductTicker for each hop. Delisted products are not used.
+func ValuePortfolio(accounts AccountsAPI, market MarketDataAPI, currency string) (Valuation, error) {
+	balances, err := accounts.ListAccounts()
+	if err != nil {
+		return Valuation{}, err
+	}
+	products, err := market.ListProducts()
+	if err != nil {
+		return Valuation{}, err
+	}
+	graph := priceGraph(products)
+	tickers := make(map[string]*big.Rat)
+
+	// accounts of the same currency (one per profile) are valued together
+	totals := make(map[string]*big.Rat)
+	var currencies []string
+	for _, a := range balances {
+		b, err := parseDecimal(a.Balance)
+		if err != nil {
+			return Valuation{}, err
+		}
+		if _, ok := totals[a.Currency]; !ok {
+			totals[a.Currency] = newRat()
+			currencies = append(currencies, a.Currency)
+		}
+		totals[a.Currency] = add(totals[a.Currency], b)
+	}
+	sort.Strings(currencies)
+
+	v := Valuation{Currency: currency}
+	total := newRat()
+	for _, c := range currencies {
+		balance := totals[c]
+		if balance.Sign() == 0 {
+			continue
+		}
+		asset := AssetValue{Currency: c, Balance: formatDecimal(balance)}
+
+		price, route, err := routePrice(market, graph, tickers, c, currency)
+		if err != nil || price == nil {
+			v.Assets = append(v.Assets, asset)
+			v.Unpriced = append(v.Unpriced, c)
+			continue
+		}
+		asset.Route = route
+		value := mul(balance, price)
+		total = add(total, value)
+		asset.Price = formatDecimal(price)
+		asset.Value = formatDecimal(value)
+		asset.Priced = true
+		v.Assets = append(v.Assets, asset)
+	}
+	v.Total = formatDecimal(total)
+	return v, nil
+}
+
+// routePrice prices one unit of from in to along the route with the fewest hops, and returns the
+// products it went through. Last trade prices are looked up with GetProductTicker once per product
+// and kept in cache. The price is nil when there is no route or a product on it hasn't traded.
+func routePrice(market MarketDataAPI, graph map[string][]priceEdge, cache map[string]*big.Rat, from, to string) (*big.Rat, []string, error) {
+	hops, ok := priceRoute(graph, from, to)
+	if !ok {
+		return nil, nil, nil
+	}
+	price := newRat().SetInt64(1)
+	var route []string
+	for _, hop := range hops {
+		p, ok := cache[hop.product]
+		if !ok {
+			t, err := market.GetProductTicker(hop.product)
+			if err != nil {
+				return nil, nil, err
+			}
+			if p, err = parseDecimal(t.Price); err != nil {
+				return nil, nil, err
+			}
+			cache[hop.product] = p
+		}
+		if p.Sign() == 0 {
+			return nil, nil, nil
+		}
+		if hop.inverted {
+			p = quo(newRat().SetInt64(1), p)
+		}
+		price = mul(price, p)
+		route = append(route, hop.product)
+	}
+	return price, route, nil
+}
+
+// priceGraph links every currency to the currencies it trades against, with each list sorted so
+// routes come out the same every time.
+func priceGraph(products []Product) map[string][]priceEdge {
+	graph := make(map[string][]priceEdge)
+	for _, p := range products {
+		if p.Status == "delisted" || p.BaseCurrency == "" || p.QuoteCurrency == "" {
+			continue
+		}
+		graph[p.BaseCurrency] = append(graph[p.BaseCurrency], priceEdge{product: p.ID, to: p.QuoteCurrency})
+		graph[p.QuoteCurrency] = append(graph[p.QuoteCurrency], priceEdge{product: p.ID, to: p.BaseCurrency, inverted: true})
+	}
+	for c := range graph {
+		edges := graph[c]
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].inverted != edges[j].inverted {
+				return !edges[i].inverted // prefer selling into a quote currency over inverting
+			}
+			return edges[i].product < edges[j].product
+		})
+	}
+	return graph
+}
+
+// priceRoute finds the route with the fewest hops from one currency to another.
+func priceRoute(graph map[string][]priceEdge, from, to string) ([]priceEdge, bool) {
+	if from == to {
+		return nil, true
+	}
+	prev := map[string]priceEdge{from: {}}
+	queue := []string{from}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, e := range graph[c] {
+			if _, seen := prev[e.to]; seen {
+				continue
+			}
+			prev[e.to] = priceEdge{product: e.product, to: c, inverted: e.inverted}
+			if e.to != to {
+				queue = append(queue, e.to)
+				continue
+			}
+			// walk back from the target to build the route
+			var route []priceEdge
+			for at := to; at != from; {
+				back := prev[at]
+				route = append([]priceEdge{{product: back.product, to: at, inverted: back.inverted}}, route...)
+				at = back.to
+			}
+			return route, true
+		}
+	}
+	return nil, false
+}
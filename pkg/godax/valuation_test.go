@@ -0,0 +1,101 @@
+package godax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func valuationMarket(lookups *[]string) *FakeMarketDataAPI {
+	return &FakeMarketDataAPI{
+		ListProductsFunc: func() ([]Product, error) {
+			return []Product{
+				{ID: "BTC-USD", BaseCurrency: "BTC", QuoteCurrency: "USD"},
+				{ID: "ETH-USD", BaseCurrency: "ETH", QuoteCurrency: "USD"},
+				{ID: "ETH-BTC", BaseCurrency: "ETH", QuoteCurrency: "BTC"},
+				{ID: "XLM-BTC", BaseCurrency: "XLM", QuoteCurrency: "BTC"},
+				{ID: "BTC-EUR", BaseCurrency: "BTC", QuoteCurrency: "EUR"},
+				{ID: "ZRX-USD", BaseCurrency: "ZRX", QuoteCurrency: "USD", Status: "delisted"},
+			}, nil
+		},
+		GetProductTickerFunc: func(productID string) (Ticker, error) {
+			*lookups = append(*lookups, productID)
+			prices := map[string]string{"BTC-USD": "10000", "ETH-USD": "400", "ETH-BTC": "0.04", "XLM-BTC": "0.00001", "BTC-EUR": "8000"}
+			return Ticker{Price: prices[productID]}, nil
+		},
+	}
+}
+
+func TestValuePortfolio(t *testing.T) {
+	accounts := &FakeAccountsAPI{ListAccountsFunc: func() ([]ListAccount, error) {
+		return []ListAccount{
+			{Currency: "USD", Balance: "100.5"},
+			{Currency: "BTC", Balance: "0.5", Hold: "0.1"},
+			{Currency: "BTC", Balance: "0.25"}, // another profile
+			{Currency: "XLM", Balance: "1000"},
+			{Currency: "EUR", Balance: "80"},
+			{Currency: "ZRX", Balance: "10"},
+			{Currency: "ETH", Balance: "0"},
+		}, nil
+	}}
+	var lookups []string
+	got, err := ValuePortfolio(accounts, valuationMarket(&lookups), "USD")
+	if err != nil {
+		t.Fatalf("ValuePortfolio() error = %v", err)
+	}
+
+	want := Valuation{
+		Currency: "USD",
+		Total:    "7800.5",
+		Assets: []AssetValue{
+			{Currency: "BTC", Balance: "0.75", Price: "10000", Value: "7500", Route: []string{"BTC-USD"}, Priced: true},
+			{Currency: "EUR", Balance: "80", Price: "1.25", Value: "100", Route: []string{"BTC-EUR", "BTC-USD"}, Priced: true},
+			{Currency: "USD", Balance: "100.5", Price: "1", Value: "100.5", Priced: true},
+			{Currency: "XLM", Balance: "1000", Price: "0.1", Value: "100", Route: []string{"XLM-BTC", "BTC-USD"}, Priced: true},
+			{Currency: "ZRX", Balance: "10"},
+		},
+		Unpriced: []string{"ZRX"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValuePortfolio() =\n%+v\nwant\n%+v", got, want)
+	}
+	if len(lookups) != 3 {
+		t.Errorf("ticker lookups = %v, want each product looked up once", lookups)
+	}
+}
+
+func TestValuePortfolio_OtherQuote(t *testing.T) {
+	accounts := &FakeAccountsAPI{ListAccountsFunc: func() ([]ListAccount, error) {
+		return []ListAccount{{Currency: "ETH", Balance: "2"}, {Currency: "USD", Balance: "1000"}}, nil
+	}}
+	var lookups []string
+	got, err := ValuePortfolio(accounts, valuationMarket(&lookups), "BTC")
+	if err != nil {
+		t.Fatalf("ValuePortfolio() error = %v", err)
+	}
+	if got.Total != "0.18" || !reflect.DeepEqual(got.Assets[0].Route, []string{"ETH-BTC"}) || !reflect.DeepEqual(got.Assets[1].Route, []string{"BTC-USD"}) {
+		t.Errorf("ValuePortfolio() = %+v, want 0.08 BTC of ETH and 0.1 BTC of USD", got)
+	}
+}
+
+func TestValuePortfolio_TickerError(t *testing.T) {
+	accounts := &FakeAccountsAPI{ListAccountsFunc: func() ([]ListAccount, error) {
+		return []ListAccount{{Currency: "BTC", Balance: "1"}, {Currency: "XLM", Balance: "1000"}}, nil
+	}}
+	var lookups []string
+	market := valuationMarket(&lookups)
+	prices := market.GetProductTickerFunc
+	market.GetProductTickerFunc = func(productID string) (Ticker, error) {
+		if productID == "XLM-BTC" {
+			return Ticker{}, &StatusError{StatusCode: 500, Message: "Internal Server Error"}
+		}
+		return prices(productID)
+	}
+
+	got, err := ValuePortfolio(accounts, market, "USD")
+	if err != nil {
+		t.Fatalf("ValuePortfolio() error = %v", err)
+	}
+	if got.Total != "10000" || !reflect.DeepEqual(got.Unpriced, []string{"XLM"}) || got.Assets[1].Priced || got.Assets[1].Route != nil {
+		t.Errorf("ValuePortfolio() = %+v, want BTC priced and XLM unpriced", got)
+	}
+}